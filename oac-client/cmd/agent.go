@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentWait    bool
+	agentTimeout time.Duration
+)
+
+// agentCmd groups agent (scheduled delivery) commands
+var agentCmd = &cobra.Command{
+	Use:     "agent",
+	Aliases: []string{"agents"},
+	Short:   "Manage OAC agents and their delivery schedules",
+}
+
+var agentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List agents with their schedule state",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		agents, err := client.ListAgents()
+		if err != nil {
+			return fmt.Errorf("error listing agents: %w", err)
+		}
+		ids := make([]string, 0, len(agents))
+		for _, a := range agents {
+			state := "disabled"
+			if a.Enabled {
+				state = "enabled"
+			}
+			fmt.Printf("%-40s %-9s %-25s %s\n", a.Name, state, a.NextRun, a.ID)
+			ids = append(ids, a.ID)
+		}
+		rememberCompletions("agents", ids)
+		return nil
+	},
+}
+
+var agentRunCmd = &cobra.Command{
+	Use:     "run <id|path>",
+	Short:   "Run an agent's delivery now",
+	Example: `  oac-client agent run "/shared/Finance/Agents/Daily Revenue" --wait`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		wrID, err := client.RunAgent(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to run agent: %w", err)
+		}
+		if wrID == "" || !agentWait {
+			infof("Agent started %s", args[0])
+			return nil
+		}
+		if _, err := waitWorkRequest(client, "Running agent", wrID, 5*time.Second, agentTimeout); err != nil {
+			return err
+		}
+		infof("Agent delivered %s", args[0])
+		return nil
+	},
+}
+
+var agentEnableCmd = &cobra.Command{
+	Use:   "enable <id|path>...",
+	Short: "Resume the schedule of agents",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setAgentsEnabled(args, true)
+	},
+}
+
+var agentDisableCmd = &cobra.Command{
+	Use:     "disable <id|path>...",
+	Short:   "Pause the schedule of agents, e.g. for a maintenance window",
+	Example: `  oac-client agent disable $(oac-client agent list | awk '$2 == "enabled" {print $NF}')`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setAgentsEnabled(args, false)
+	},
+}
+
+// setAgentsEnabled enables or disables each agent, continuing past failures
+func setAgentsEnabled(ids []string, enabled bool) error {
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+
+	verb := "Disabled"
+	if enabled {
+		verb = "Enabled"
+	}
+	failed := 0
+	for _, id := range ids {
+		if err := client.SetAgentEnabled(id, enabled); err != nil {
+			reportError(fmt.Errorf("%s: %w", id, err))
+			failed++
+			continue
+		}
+		infof("%s %s", verb, id)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d agents failed", failed, len(ids))
+	}
+	return nil
+}
+
+func init() {
+	agentRunCmd.Flags().BoolVar(&agentWait, "wait", false, "wait for the delivery to finish")
+	agentRunCmd.Flags().DurationVar(&agentTimeout, "timeout", 30*time.Minute, "maximum time to wait with --wait")
+
+	agentCmd.AddCommand(agentListCmd, agentRunCmd, agentEnableCmd, agentDisableCmd)
+	rootCmd.AddCommand(agentCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// aliases are the request aliases of the config file, by name
+var aliases map[string]string
+
+// registerAliases reads the aliases of the config file and lists them in the help as
+// commands; running one is handled by expandAliasArgs before cobra parses the arguments
+func registerAliases(cfg *oac.Config) {
+	if cfg == nil || len(cfg.Aliases) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aliases = map[string]string{}
+	rootCmd.AddGroup(&cobra.Group{ID: "aliases", Title: "Aliases (from " + cfg.Path + "):"})
+	for _, name := range names {
+		if c, _, err := rootCmd.Find([]string{name}); err == nil && c != rootCmd {
+			continue // built-in commands win
+		}
+		command := cfg.Aliases[name]
+		aliases[name] = command
+		use := name
+		for i := 1; i <= oac.AliasArgs(command); i++ {
+			use += fmt.Sprintf(" <arg%d>", i)
+		}
+		rootCmd.AddCommand(&cobra.Command{
+			Use:                use,
+			Short:              command,
+			GroupID:            "aliases",
+			DisableFlagParsing: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("alias %s was not expanded", name)
+			},
+		})
+	}
+}
+
+// expandAliasArgs replaces an alias in the command line by its request, keeping the
+// global flags given before it
+func expandAliasArgs(args []string) ([]string, error) {
+	i := firstPositional(args)
+	if i < 0 {
+		return args, nil
+	}
+	command, ok := aliases[args[i]]
+	if !ok {
+		return args, nil
+	}
+	expanded, err := oac.ExpandAlias(args[i], command, args[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	return append(args[:i:i], expanded...), nil
+}
+
+// firstPositional returns the index of the first argument that is neither a flag nor a
+// flag value, -1 when there is none
+func firstPositional(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		if f := lookupRootFlag(arg); f != nil && f.NoOptDefVal == "" {
+			i++ // skip the flag's value
+		}
+	}
+	return -1
+}
+
+// lookupRootFlag finds a flag of the root command by its --name or -shorthand
+func lookupRootFlag(arg string) *pflag.Flag {
+	for _, flags := range []*pflag.FlagSet{rootCmd.PersistentFlags(), rootCmd.Flags()} {
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			if f := flags.Lookup(name); f != nil {
+				return f
+			}
+			continue
+		}
+		// -abc groups boolean shorthands; only the last one can take a value
+		if f := flags.ShorthandLookup(arg[len(arg)-1:]); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// configFileFromArgs returns the config file selected by --config in args, before cobra
+// parses them
+func configFileFromArgs(args []string) string {
+	if f := configFlag(args); f != "" {
+		return f
+	}
+	return configFile()
+}
+
+// configFlag returns the value of --config in args, before cobra parses them
+func configFlag(args []string) string {
+	for i, arg := range args {
+		if v, ok := strings.CutPrefix(arg, "--config="); ok {
+			return v
+		}
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// apiSpec is the OpenAPI spec the api subcommands were generated from
+var apiSpec *oac.OpenAPISpec
+
+// apiCmd groups the operations of the OpenAPI spec
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Call OAC REST API operations described by an OpenAPI spec",
+	Long: `Call OAC REST API operations described by an OpenAPI spec.
+
+Every operation of the spec becomes a subcommand named after its operationId
+in kebab case, with a typed flag per parameter and --body for the request
+body. The spec is read from OAC_OPENAPI_SPEC, or openapi.yaml / openapi.json
+next to the config file.`,
+	Example: `  OAC_OPENAPI_SPEC=oac.yaml oac-client api --help
+  oac-client api get-snapshot --snapshot-id 7f3a
+  oac-client api create-snapshot --body snapshot.json`,
+}
+
+// registerAPICommands adds a subcommand for every operation of the OpenAPI spec found
+// for the config file
+func registerAPICommands(config string) {
+	file := oac.OpenAPISpecPath(config)
+	if file == "" {
+		apiCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("no OpenAPI spec found: set OAC_OPENAPI_SPEC")
+		}
+		return
+	}
+
+	spec, err := oac.LoadOpenAPI(file)
+	if err != nil {
+		apiCmd.RunE = func(cmd *cobra.Command, args []string) error { return err }
+		return
+	}
+	apiSpec = spec
+	if apiSpec.Title != "" {
+		apiCmd.Short = fmt.Sprintf("Call %s %s operations", apiSpec.Title, apiSpec.Version)
+	}
+
+	groups := map[string]bool{}
+	for _, op := range apiSpec.Operations {
+		c := operationCommand(op)
+		if len(op.Tags) > 0 {
+			tag := op.Tags[0]
+			if !groups[tag] {
+				groups[tag] = true
+				apiCmd.AddGroup(&cobra.Group{ID: tag, Title: tag + ":"})
+			}
+			c.GroupID = tag
+		}
+		apiCmd.AddCommand(c)
+	}
+}
+
+// operationCommand builds the command calling one operation
+func operationCommand(op *oac.Operation) *cobra.Command {
+	c := &cobra.Command{
+		Use:   op.CommandName(),
+		Short: op.Summary,
+		Long:  strings.TrimSpace(op.Method + " " + op.Path + "\n\n" + op.Description),
+		Args:  cobra.NoArgs,
+	}
+	if c.Short == "" {
+		c.Short = op.Method + " " + op.Path
+	}
+
+	flags := c.Flags()
+	names := make(map[string]oac.Parameter, len(op.Parameters))
+	for _, p := range op.Parameters {
+		name := parameterFlag(p)
+		names[name] = p
+		usage := p.Description
+		if len(p.Enum) > 0 {
+			usage = strings.TrimSpace(usage + " (" + strings.Join(p.Enum, "|") + ")")
+		}
+		switch p.Type {
+		case "integer":
+			flags.Int64(name, 0, usage)
+		case "number":
+			flags.Float64(name, 0, usage)
+		case "boolean":
+			flags.Bool(name, false, usage)
+		case "array":
+			flags.StringSlice(name, nil, usage)
+		default:
+			flags.String(name, "", usage)
+		}
+		if p.Required {
+			_ = c.MarkFlagRequired(name)
+		}
+	}
+	if op.Body != nil {
+		flags.String("body", "", "request body: a file, - for stdin, or literal JSON")
+		if op.BodyRequired {
+			_ = c.MarkFlagRequired("body")
+		}
+	}
+
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		pathValues := map[string]string{}
+		query := url.Values{}
+		header := http.Header{}
+
+		var verr error
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			p, ok := names[f.Name]
+			if !ok || verr != nil {
+				return
+			}
+			values := []string{f.Value.String()}
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				values = sv.GetSlice()
+			}
+			for _, v := range values {
+				if err := p.Validate(v); err != nil {
+					verr = err
+					return
+				}
+				switch p.In {
+				case "path":
+					pathValues[p.Name] = v
+				case "query":
+					query.Add(p.Name, v)
+				case "header":
+					header.Add(p.Name, v)
+				}
+			}
+		})
+		if verr != nil {
+			return verr
+		}
+
+		path, err := op.ExpandPath(pathValues)
+		if err != nil {
+			return err
+		}
+		var body []byte
+		if op.Body != nil {
+			bodyArg, _ := cmd.Flags().GetString("body")
+			if body, err = oac.ReadBody(bodyArg); err != nil {
+				return fmt.Errorf("failed to read body: %w", err)
+			}
+			if err := checkRequestBody(op, op.Method, path, body); err != nil {
+				return err
+			}
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		req := &oac.Request{Method: op.Method, Path: path, Query: query, Header: header}
+		if len(body) > 0 {
+			req.Body = bytes.NewReader(body)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			if tooLarge := (*oac.ResponseTooLargeError)(nil); errors.As(err, &tooLarge) {
+				return printSpooled(tooLarge)
+			}
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+		return printResponse(resp)
+	}
+	return c
+}
+
+// parameterFlag returns the flag name of a parameter, avoiding global flag names
+func parameterFlag(p oac.Parameter) string {
+	name := oac.KebabCase(p.Name)
+	if rootCmd.PersistentFlags().Lookup(name) != nil || name == "body" || name == "help" {
+		name = p.In + "-" + name
+	}
+	return name
+}
+
+var (
+	apiListFilter string
+	apiListSpec   string
+	apiListJSON   bool
+)
+
+// apiListCmd lists the endpoints of the API
+var apiListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the REST API endpoints with their descriptions",
+	Long: `List the REST API endpoints with their method, path and summary.
+
+The endpoints come from the OpenAPI spec of the api subcommands when one is
+configured, from --spec (a file or URL), or else from the catalog of OAC
+endpoints built into oac-client. --filter keeps those whose path, operation,
+summary or tag contains the text.`,
+	Example: `  oac-client api list --filter snapshot
+  oac-client api list --spec https://example.com/oac-openapi.yaml --filter dataset
+  oac-client api list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := listedSpec()
+		if err != nil {
+			return err
+		}
+
+		type endpoint struct {
+			Method      string   `json:"method"`
+			Path        string   `json:"path"`
+			Summary     string   `json:"summary,omitempty"`
+			OperationID string   `json:"operationId"`
+			Tags        []string `json:"tags,omitempty"`
+		}
+		endpoints := []endpoint{}
+		width := 0
+		for _, op := range spec.Operations {
+			if apiListFilter != "" && !op.Matches(apiListFilter) {
+				continue
+			}
+			endpoints = append(endpoints, endpoint{Method: op.Method, Path: op.Path, Summary: op.Summary, OperationID: op.ID, Tags: op.Tags})
+			width = max(width, len(op.Path))
+		}
+
+		if apiListJSON {
+			data, err := json.Marshal(endpoints)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+		if len(endpoints) == 0 {
+			infof("No endpoints match %q", apiListFilter)
+			return nil
+		}
+		for _, e := range endpoints {
+			fmt.Printf("%-7s %-*s %s\n", e.Method, width, e.Path, e.Summary)
+		}
+		return nil
+	},
+}
+
+// listedSpec returns the spec api list describes: --spec, the configured spec, or the
+// built-in endpoints
+func listedSpec() (*oac.OpenAPISpec, error) {
+	switch {
+	case strings.HasPrefix(apiListSpec, "http://") || strings.HasPrefix(apiListSpec, "https://"):
+		client, err := oac.HTTPClient(transportConfig)
+		if err != nil {
+			return nil, err
+		}
+		return oac.FetchOpenAPI(commandCtx, client, apiListSpec)
+	case apiListSpec != "":
+		return oac.LoadOpenAPI(apiListSpec)
+	case apiSpec != nil:
+		return apiSpec, nil
+	case oac.OpenAPISpecPath(configFile()) != "":
+		// the configured spec failed to load; report why
+		return oac.LoadOpenAPI(oac.OpenAPISpecPath(configFile()))
+	}
+	return oac.KnownEndpoints()
+}
+
+func init() {
+	apiListCmd.Flags().StringVar(&apiListFilter, "filter", "", "only list endpoints whose path, operation, summary or tag contains this text")
+	apiListCmd.Flags().StringVar(&apiListSpec, "spec", "", "OpenAPI spec to list, a file or an http(s) URL")
+	apiListCmd.Flags().BoolVar(&apiListJSON, "json", false, "print the endpoints as JSON")
+
+	apiCmd.AddCommand(apiListCmd)
+	rootCmd.AddCommand(apiCmd)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	applyFile  string
+	applyPlan  bool
+	applyPrune bool
+
+	exportStateOut      string
+	exportStateSections []string
+)
+
+// applyCmd converges the instance to a declarative state file
+var applyCmd = &cobra.Command{
+	Use:   "apply -f desired-state.yaml",
+	Short: "Converge connections, roles, settings and agents to a state file",
+	Long: `Converge the instance to a declarative state file, creating, updating and
+deleting what differs:
+
+  connections:
+    ADW Sales:
+      type: oracle-adw
+      host: adw.example.com
+      username: SALES
+      password: secret://ocid1.vaultsecret.oc1..example
+  roles:
+    FinanceAuthors:
+      description: Authors of the Finance folder
+      members:
+        - {name: finance-team, type: group}
+  settings:
+    QueryLimitsMaxRows: 250000
+  agents:
+    /shared/Finance/Agents/Daily Revenue: {enabled: true, schedule: "0 6 * * *"}
+
+Sections left out of the file are not managed. The plan is printed before
+anything changes; --plan stops there. Connections and roles missing from their
+section are only deleted with --prune. Connection fields the instance does not
+return, such as passwords, are compared only when the connection is created,
+and may reference secrets (secret://, vault://). Agents must already exist.`,
+	Example: `  oac-client apply -f desired-state.yaml --plan
+  oac-client --profile prod apply -f desired-state.yaml --prune`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := oac.LoadState(applyFile)
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		changes, err := client.PlanState(commandCtx, state, applyPrune)
+		if err != nil {
+			return err
+		}
+		counts := map[string]int{}
+		for _, ch := range changes {
+			fmt.Println(ch.String())
+			counts[ch.Op]++
+		}
+		if len(changes) == 0 {
+			infof("The instance matches %s", applyFile)
+			return nil
+		}
+		summary := fmt.Sprintf("%d to create, %d to update, %d to delete", counts["+"], counts["~"], counts["-"])
+		if applyPlan {
+			infof("Plan: %s", summary)
+			return nil
+		}
+
+		if err := client.ApplyState(changes); err != nil {
+			return err
+		}
+		infof("Applied: %s", summary)
+		return nil
+	},
+}
+
+// exportStateCmd writes the configuration of the instance in the format apply reads
+var exportStateCmd = &cobra.Command{
+	Use:   "export-state [--out state.yaml]",
+	Short: "Export connections, roles, settings and agents as a state file",
+	Long: `Export the connections, application roles, system settings and agent
+schedules of the instance in the format apply reads. Applying the file of one
+environment to another with --plan shows the drift between them.
+
+Connection fields the instance does not return, such as passwords, are not
+exported; add them, e.g. as secret:// references, before applying the file to
+create connections.`,
+	Example: `  oac-client --profile prod export-state --out prod-state.yaml
+  oac-client --profile test apply -f prod-state.yaml --plan
+  oac-client export-state --section settings,roles`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		state, err := client.ExportState(exportStateSections)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(state); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		if exportStateOut == "" || exportStateOut == "-" {
+			_, err = os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(exportStateOut, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		infof("Saved %s", exportStateOut)
+		return nil
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "YAML file declaring the desired state")
+	applyCmd.Flags().BoolVar(&applyPlan, "plan", false, "print the plan without applying it")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete connections and roles missing from the file")
+	_ = applyCmd.MarkFlagRequired("file")
+
+	exportStateCmd.Flags().StringVarP(&exportStateOut, "out", "o", "", "file to write, stdout when empty or -")
+	exportStateCmd.Flags().StringSliceVar(&exportStateSections, "section", nil, "sections to export: connections, roles, settings, agents (default all)")
+
+	rootCmd.AddCommand(applyCmd, exportStateCmd)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+// authCmd groups token management commands
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the cached IDCS access token",
+}
+
+// authLoginCmd forces a new login
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Discard cached tokens and authenticate again",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if err := client.Login(); err != nil {
+			return err
+		}
+		return printTokenStatus(client)
+	},
+}
+
+// authStatusCmd decodes the cached token
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show subject, scopes and expiry of the cached token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		return printTokenStatus(client)
+	},
+}
+
+// authRefreshCmd replaces the access token
+var authRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Obtain a new access token, using the refresh token when available",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if err := client.Refresh(); err != nil {
+			return err
+		}
+		return printTokenStatus(client)
+	},
+}
+
+// authLogoutCmd clears the token cache
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove cached tokens",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if err := client.Logout(); err != nil {
+			return err
+		}
+		infof("Logged out.")
+		return nil
+	},
+}
+
+// warmMargin is how long before expiry auth warm replaces a token, warmCheckInterval
+// how often it checks
+const (
+	warmMargin        = 5 * time.Minute
+	warmCheckInterval = 30 * time.Second
+)
+
+var warmFor time.Duration
+
+// authWarmCmd obtains tokens ahead of time and keeps them fresh
+var authWarmCmd = &cobra.Command{
+	Use:   "warm [profile...] --for 30m",
+	Short: "Obtain tokens for profiles now and keep them fresh for a while",
+	Long: `Obtain access tokens for the given profiles, the --profile one or else every
+configured profile, and replace them in the token cache before they expire until
+--for has elapsed or the command is stopped. Run it in the background at the
+start of a CI job: later steps skip the token request and can run with
+--no-refresh, failing instead of contacting IDCS when no cached token is valid.
+
+The token cache must be shared with those steps, the default file cache or a
+redis:// OAC_TOKEN_STORE.`,
+	Example: `  oac-client auth warm --for 30m &
+  oac-client --no-refresh --profile prod catalog export-all --out catalog/
+
+  oac-client auth warm dev prod --for 2h`,
+	ValidArgsFunction: completeProfiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if warmFor <= 0 {
+			return fmt.Errorf("--for must be a positive duration, e.g. 30m")
+		}
+		names := args
+		if len(names) == 0 && profileName != "" {
+			names = []string{profileName}
+		}
+		if len(names) == 0 {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			names = cfg.ProfileNames()
+		}
+		if len(names) == 0 {
+			// no config file, the environment configures the only profile
+			names = []string{""}
+		}
+
+		clients := map[string]*oac.OacClient{}
+		expiries := map[string]time.Time{}
+		for _, name := range names {
+			client, err := newClientForProfile(name)
+			if err != nil {
+				return fmt.Errorf("failed to create OAC client for profile %q: %w", name, err)
+			}
+			label := client.ProfileName()
+			if label == "" {
+				label = "environment"
+			}
+			expiry, err := client.EnsureToken(warmMargin)
+			if err != nil {
+				slog.Warn("failed to obtain a token", "profile", label, "error", err)
+				continue
+			}
+			clients[label], expiries[label] = client, expiry
+			infof("%s: token valid until %s", label, expiry.Format(time.RFC3339))
+		}
+		if len(clients) == 0 {
+			return fmt.Errorf("no token could be obtained")
+		}
+
+		ctx, cancel := context.WithTimeout(commandCtx, warmFor)
+		defer cancel()
+		ticker := time.NewTicker(warmCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				infof("Stopped keeping tokens fresh")
+				return nil
+			case <-ticker.C:
+			}
+			for label, client := range clients {
+				expiry, err := client.EnsureToken(warmMargin)
+				if err != nil {
+					// retried at the next check, the current token may still be valid
+					slog.Warn("failed to refresh the token", "profile", label, "error", err)
+					continue
+				}
+				if !expiry.Equal(expiries[label]) {
+					expiries[label] = expiry
+					infof("%s: token refreshed, valid until %s", label, expiry.Format(time.RFC3339))
+				}
+			}
+		}
+	},
+}
+
+// printTokenStatus prints the claims of the client's cached token
+func printTokenStatus(client *oac.OacClient) error {
+	token, expiry := client.CachedToken()
+	if token == "" {
+		fmt.Println("Not logged in (no valid cached token).")
+		return nil
+	}
+
+	fmt.Printf("Token expires: %s (in %s)\n", expiry.Format(time.RFC3339), time.Until(expiry).Round(time.Second))
+
+	claims, err := oac.DecodeTokenClaims(token)
+	if err != nil {
+		fmt.Println("Claims:        unavailable,", err)
+		return nil
+	}
+
+	fmt.Printf("Subject:       %s\n", claims.Subject)
+	if claims.DisplayName != "" {
+		fmt.Printf("Display name:  %s\n", claims.DisplayName)
+	}
+	if claims.ClientID != "" {
+		fmt.Printf("Client ID:     %s\n", claims.ClientID)
+	}
+	fmt.Printf("Issuer:        %s\n", claims.Issuer)
+	fmt.Printf("Scopes:        %s\n", strings.Join(claims.Scopes, " "))
+	fmt.Printf("Issued at:     %s\n", claims.IssuedAt.Format(time.RFC3339))
+	return nil
+}
+
+func init() {
+	authWarmCmd.Flags().DurationVar(&warmFor, "for", 0, "how long to keep the tokens fresh, e.g. 30m")
+	_ = authWarmCmd.MarkFlagRequired("for")
+
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authRefreshCmd, authLogoutCmd, authWarmCmd)
+	rootCmd.AddCommand(authCmd)
+}
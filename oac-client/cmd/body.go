@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+)
+
+// loadBody resolves the request body from --data, --data-file, --data-stdin or the
+// positional bodyFile argument, rendering it as a template when variables are given
+func loadBody(method string, positional []string) ([]byte, error) {
+	explicit := dataLiteral != "" || dataFile != "" || dataStdin
+	if explicit && len(positional) > 0 {
+		return nil, fmt.Errorf("use either the bodyFile argument or --data/--data-file/--data-stdin, not both")
+	}
+
+	var body []byte
+	var err error
+
+	switch {
+	case dataLiteral != "":
+		body = []byte(dataLiteral)
+	case dataFile != "":
+		if body, err = os.ReadFile(dataFile); err != nil {
+			return nil, fmt.Errorf("failed to read body file: %w", err)
+		}
+	case dataStdin:
+		if body, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("failed to read body from stdin: %w", err)
+		}
+	case len(positional) > 0:
+		if body, err = oac.ReadBody(positional[0]); err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+	case requiresBody(method):
+		return nil, fmt.Errorf("%s requires a body file", method)
+	}
+
+	if len(body) > 0 && (len(templateVars) > 0 || varFile != "" || bytes.Contains(body, []byte("{{"))) {
+		return renderBody(body)
+	}
+	return body, nil
+}
+
+// bodyFile returns the file the request body is read from, "" when it is not a file
+func bodyFile(positional []string) string {
+	switch {
+	case dataLiteral != "" || dataStdin:
+		return ""
+	case dataFile != "":
+		return dataFile
+	case len(positional) > 0 && positional[0] != "-":
+		if info, err := os.Stat(positional[0]); err == nil && !info.IsDir() {
+			return positional[0]
+		}
+	}
+	return ""
+}
+
+// verifyBodyFile checks the body file against its .sha256 file when --verify is set
+func verifyBodyFile(positional []string) error {
+	if !verifyBody {
+		return nil
+	}
+	file := bodyFile(positional)
+	if file == "" {
+		return fmt.Errorf("--verify needs the body as a file, with --data-file or the bodyFile argument")
+	}
+	return oac.VerifyChecksum(file)
+}
+
+// requiresBody returns true if the HTTP method requires a body
+func requiresBody(method string) bool {
+	return method == "POST" || method == "PUT" || method == "PATCH"
+}
+
+// renderBody expands the body template with the variables of commandVars
+func renderBody(body []byte) ([]byte, error) {
+	vars, err := commandVars(profileName)
+	if err != nil {
+		return nil, err
+	}
+	return oac.RenderTemplate(body, vars)
+}
+
+// expandPath expands the variables of a request path with those of commandVars
+func expandPath(profile, path string) (string, error) {
+	if !strings.Contains(path, "{{") {
+		return path, nil
+	}
+	vars, err := commandVars(profile)
+	if err != nil {
+		return "", err
+	}
+	return oac.ExpandPath(path, vars)
+}
+
+// commandVars returns the vars of a profile overridden by --var-file values, in turn
+// overridden by --var
+func commandVars(profile string) (oac.TemplateVars, error) {
+	vars := oac.TemplateVars{}
+	p, err := loadProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		vars = vars.Merge(p.Vars)
+	}
+	if varFile != "" {
+		fileVars, err := oac.LoadVarFile(varFile)
+		if err != nil {
+			return nil, err
+		}
+		vars = vars.Merge(fileVars)
+	}
+
+	flagVars, err := oac.ParseVars(templateVars)
+	if err != nil {
+		return nil, err
+	}
+	return vars.Merge(flagVars), nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cachePurgeAll      bool
+	cachePurgeDatabase string
+	cachePurgeTable    string
+	cachePurgeTimeout  time.Duration
+)
+
+// cacheCmd groups local and server cache commands
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local response cache and the OAC query cache",
+}
+
+// cacheClearCmd removes every cached response
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all responses cached with --cache-ttl",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := oac.ClearResponseCache(); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		infof("Response cache cleared")
+		return nil
+	},
+}
+
+// cachePurgeCmd purges the query cache of the OAC instance
+var cachePurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Purge the OAC query cache",
+	Long: `Purge the query cache of the OAC instance, e.g. from an ETL completion hook
+once new data is loaded.
+
+Examples:
+  oac-client cache purge --all
+  oac-client cache purge --database "ADW Sales"
+  oac-client cache purge --database "ADW Sales" --table SALES.ORDERS
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		wrID, err := client.PurgeCache(oac.CachePurge{All: cachePurgeAll, Database: cachePurgeDatabase, Table: cachePurgeTable})
+		if err != nil {
+			return fmt.Errorf("failed to purge cache: %w", err)
+		}
+		if wrID != "" {
+			if _, err := waitWorkRequest(client, "Purging cache", wrID, 2*time.Second, cachePurgeTimeout); err != nil {
+				return err
+			}
+		}
+		infof("Query cache purged")
+		return nil
+	},
+}
+
+func init() {
+	flags := cachePurgeCmd.Flags()
+	flags.BoolVar(&cachePurgeAll, "all", false, "purge every cache entry")
+	flags.StringVar(&cachePurgeDatabase, "database", "", "purge the entries of a database")
+	flags.StringVar(&cachePurgeTable, "table", "", "purge the entries of a table")
+	flags.DurationVar(&cachePurgeTimeout, "timeout", 10*time.Minute, "maximum time to wait for the purge")
+	cachePurgeCmd.MarkFlagsOneRequired("all", "database", "table")
+	cachePurgeCmd.MarkFlagsMutuallyExclusive("all", "database")
+	cachePurgeCmd.MarkFlagsMutuallyExclusive("all", "table")
+
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePurgeCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
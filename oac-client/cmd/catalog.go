@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	catalogFindType string
+	catalogFindName string
+	catalogFindPath string
+	catalogOutDir   string
+	catalogDest     string
+	catalogVerify   bool
+
+	exportAllPath        string
+	exportAllFilter      string
+	exportAllConcurrency int
+	exportAllManifest    string
+)
+
+// catalogCmd groups catalog browsing commands
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Browse and search the OAC catalog",
+}
+
+// catalogLsCmd lists a folder
+var catalogLsCmd = &cobra.Command{
+	Use:     "ls [folder]",
+	Short:   "List the items in a catalog folder",
+	Example: `  oac-client catalog ls /shared/Finance`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folder := "/shared"
+		if len(args) == 1 {
+			folder = args[0]
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		items, err := client.ListFolder(folder)
+		if err != nil {
+			return fmt.Errorf("error listing %s: %w", folder, err)
+		}
+
+		folders := []string{folder}
+		for _, item := range items {
+			name := item.Name
+			if item.IsFolder() {
+				name += "/"
+				folders = append(folders, item.FullPath())
+			}
+			fmt.Printf("%-14s %-25s %s\n", item.Type, item.LastModified, name)
+		}
+		rememberCompletions("folders", folders)
+		return nil
+	},
+}
+
+// catalogFindCmd searches by type and name pattern
+var catalogFindCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Search catalog items by type and name",
+	Example: `  oac-client catalog find --type analysis --name "*Revenue*"
+  oac-client catalog find --type workbooks --path /shared/Finance`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		items, err := client.SearchCatalog(catalogFindType, catalogFindName)
+		if err != nil {
+			return fmt.Errorf("error searching catalog: %w", err)
+		}
+
+		prefix := strings.TrimRight(catalogFindPath, "/")
+		for _, item := range items {
+			itemPath := item.FullPath()
+			if prefix != "" && !strings.HasPrefix(itemPath, prefix+"/") {
+				continue
+			}
+			if catalogFindName != "" {
+				if ok, _ := path.Match(catalogFindName, item.Name); !ok {
+					continue
+				}
+			}
+			fmt.Printf("%-14s %s\n", item.Type, itemPath)
+		}
+		return nil
+	},
+}
+
+// catalogTreeCmd renders the folder hierarchy
+var catalogTreeCmd = &cobra.Command{
+	Use:     "tree [folder]",
+	Short:   "Print the catalog hierarchy below a folder",
+	Example: `  oac-client catalog tree /shared/Finance`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folder := "/shared"
+		if len(args) == 1 {
+			folder = args[0]
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		fmt.Println(folder)
+		return printCatalogTree(client, folder, "")
+	},
+}
+
+// printCatalogTree recursively prints the children of folder with box-drawing guides
+func printCatalogTree(client *oac.OacClient, folder, prefix string) error {
+	items, err := client.ListFolder(folder)
+	if err != nil {
+		return fmt.Errorf("error listing %s: %w", folder, err)
+	}
+
+	for i, item := range items {
+		branch, indent := "├── ", "│   "
+		if i == len(items)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if !item.IsFolder() {
+			fmt.Printf("%s%s%s\n", prefix, branch, item.Name)
+			continue
+		}
+
+		fmt.Printf("%s%s%s/\n", prefix, branch, item.Name)
+		if err := printCatalogTree(client, item.FullPath(), prefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catalogExportCmd backs up a folder recursively
+var catalogExportCmd = &cobra.Command{
+	Use:     "export <folder>",
+	Short:   "Export catalog items and their permissions recursively",
+	Example: `  oac-client catalog export /shared/Finance --out ./backup`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		exported, err := client.ExportCatalog(args[0], catalogOutDir)
+		for _, p := range exported {
+			infof("exported %s", p)
+		}
+		if err != nil {
+			return fmt.Errorf("export stopped after %d items: %w", len(exported), err)
+		}
+		return nil
+	},
+}
+
+// catalogImportCmd restores a backup written by catalog export
+var catalogImportCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Import catalog items exported with catalog export",
+	Long: `Import catalog items exported with catalog export. The current version of
+every item is backed up before it is replaced; when the import fails halfway,
+oac-client rollback --to <manifest> restores the items it touched.`,
+	Example: `  oac-client catalog import ./backup --dest /shared/Finance --verify`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enableBackup()
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		imported, err := client.ImportCatalog(args[0], catalogDest, catalogVerify)
+		for _, p := range imported {
+			infof("imported %s", p)
+		}
+		reportBackup(client, err != nil)
+		if err != nil {
+			return fmt.Errorf("import stopped after %d items: %w", len(imported), err)
+		}
+		return nil
+	},
+}
+
+// catalogExportAllCmd exports matching items in parallel and records them in a manifest
+var catalogExportAllCmd = &cobra.Command{
+	Use:   "export-all",
+	Short: "Export matching catalog items in parallel, with a manifest",
+	Long: `Export every item below --path matching --filter with --concurrency workers,
+in the layout of catalog export (catalog import restores it), and write a
+manifest listing the id, path, SHA-256 and export time of each item, and the
+error of those that failed.
+
+--filter joins clauses with &&; each compares type, name, path, owner or id
+with == or != to a value that may hold * wildcards. Types match in singular or
+plural.`,
+	Example: `  oac-client catalog export-all --filter 'type==workbook' --concurrency 6 --manifest manifest.json
+  oac-client catalog export-all --path /shared/Finance --filter 'type==dataset && name!=*_tmp' --out ./backup`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := oac.ParseItemFilter(exportAllFilter)
+		if err != nil {
+			return err
+		}
+		manifestFile := exportAllManifest
+		if manifestFile == "" {
+			manifestFile = filepath.Join(catalogOutDir, "manifest.json")
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		manifest, err := client.ExportAll(commandCtx, oac.ExportAllOptions{
+			Root:        exportAllPath,
+			Filter:      filter,
+			OutDir:      catalogOutDir,
+			Concurrency: exportAllConcurrency,
+			Report: func(item oac.ManifestItem) {
+				if item.Error != "" {
+					fmt.Printf("FAILED   %s: %s\n", item.Path, item.Error)
+					return
+				}
+				infof("exported %s", item.Path)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(manifestFile), 0o755); err != nil {
+			return err
+		}
+		if err := oac.WriteExportManifest(manifestFile, manifest); err != nil {
+			return err
+		}
+		failed := len(manifest.Failed())
+		infof("Exported %d of %d items, manifest %s", len(manifest.Items)-failed, len(manifest.Items), manifestFile)
+		if failed > 0 {
+			return fmt.Errorf("%d items failed to export", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	catalogFindCmd.Flags().StringVar(&catalogFindType, "type", "", "catalog item type, e.g. workbooks, datasets, analysis")
+	catalogFindCmd.Flags().StringVar(&catalogFindName, "name", "", "name pattern with * wildcards")
+	catalogFindCmd.Flags().StringVar(&catalogFindPath, "path", "", "only show items below this folder")
+
+	catalogExportCmd.Flags().StringVar(&catalogOutDir, "out", ".", "local directory to write the export to")
+	catalogImportCmd.Flags().StringVar(&catalogDest, "dest", "", "catalog folder to import into")
+	catalogImportCmd.Flags().BoolVar(&catalogVerify, "verify", false, "refuse to import unless every archive matches its .sha256 file")
+	addBackupFlags(catalogImportCmd)
+	_ = catalogImportCmd.MarkFlagRequired("dest")
+
+	catalogExportAllCmd.Flags().StringVar(&exportAllPath, "path", "/shared", "folder to search")
+	catalogExportAllCmd.Flags().StringVar(&exportAllFilter, "filter", "", "items to export, e.g. 'type==workbook' (default all)")
+	catalogExportAllCmd.Flags().IntVarP(&exportAllConcurrency, "concurrency", "c", 4, "number of parallel exports")
+	catalogExportAllCmd.Flags().StringVar(&exportAllManifest, "manifest", "", "manifest file to write (default manifest.json in --out)")
+	catalogExportAllCmd.Flags().StringVar(&catalogOutDir, "out", ".", "local directory to write the export to")
+
+	catalogCmd.AddCommand(catalogLsCmd, catalogFindCmd, catalogTreeCmd, catalogExportCmd, catalogImportCmd, catalogExportAllCmd)
+	rootCmd.AddCommand(catalogCmd)
+}
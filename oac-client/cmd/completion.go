@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+// maxCompletions caps how many values are remembered per kind
+const maxCompletions = 500
+
+// completionCmd writes shell completion scripts
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Generate a shell completion script.
+
+Besides commands and flags, profiles and the ids and paths seen in previous
+list commands (connection list, catalog ls/find) are completed.
+
+Examples:
+  # bash
+  source <(oac-client completion bash)
+  oac-client completion bash > /etc/bash_completion.d/oac-client
+
+  # zsh
+  oac-client completion zsh > "${fpath[1]}/_oac-client"
+
+  # fish
+  oac-client completion fish > ~/.config/fish/completions/oac-client.fish
+
+  # PowerShell
+  oac-client completion powershell | Out-String | Invoke-Expression
+	`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(out, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(out)
+		case "fish":
+			return rootCmd.GenFishCompletion(out, true)
+		default:
+			return rootCmd.GenPowerShellCompletionWithDesc(out)
+		}
+	},
+}
+
+// completionFile is where values seen in list commands are remembered for the current profile
+func completionFile() string {
+	name := profileName
+	if name == "" {
+		name = os.Getenv("OAC_PROFILE")
+	}
+	file := "completion.json"
+	if name != "" {
+		file = "completion_" + name + ".json"
+	}
+	return filepath.Join(oac.CacheDir(), file)
+}
+
+func loadCompletions() map[string][]string {
+	cache := map[string][]string{}
+	if data, err := os.ReadFile(completionFile()); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// rememberCompletions records values of a kind (e.g. connection ids) for later completion.
+// It is best effort: failures never affect the command that produced the values.
+func rememberCompletions(kind string, values []string) {
+	cache := loadCompletions()
+
+	merged := append(values, cache[kind]...)
+	sort.Strings(merged)
+	merged = slices.Compact(merged)
+	if len(merged) > maxCompletions {
+		merged = merged[:maxCompletions]
+	}
+	cache[kind] = merged
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	file := completionFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(file, data, 0o600)
+}
+
+// completeCached completes the first argument from remembered values of kind
+func completeCached(kind string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return filterPrefix(loadCompletions()[kind], toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProfiles completes profile names from the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(cfg.ProfileNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRoot completes the method, then paths from the request history
+func completeRoot(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		methods := make([]string, 0, len(supportedMethods))
+		for m := range supportedMethods {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		return filterPrefix(methods, strings.ToUpper(toComplete)), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		var paths []string
+		entries, _ := oac.ReadHistory(oac.DefaultHistoryPath())
+		for _, e := range entries {
+			paths = append(paths, e.Path)
+		}
+		sort.Strings(paths)
+		return filterPrefix(slices.Compact(paths), toComplete), cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// registerCompletions wires completion functions once every command has defined its flags
+func registerCompletions() {
+	rootCmd.ValidArgsFunction = completeRoot
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	for _, c := range []*cobra.Command{promoteCmd, diffCmd} {
+		_ = c.RegisterFlagCompletionFunc("from", completeProfiles)
+		_ = c.RegisterFlagCompletionFunc("to", completeProfiles)
+	}
+
+	for _, c := range []*cobra.Command{connectionGetCmd, connectionUpdateCmd, connectionDeleteCmd, connectionTestCmd} {
+		c.ValidArgsFunction = completeCached("connections")
+	}
+	for _, c := range []*cobra.Command{catalogLsCmd, catalogTreeCmd, catalogExportCmd, permsGetCmd} {
+		c.ValidArgsFunction = completeCached("folders")
+	}
+	for _, c := range []*cobra.Command{agentRunCmd, agentEnableCmd, agentDisableCmd} {
+		c.ValidArgsFunction = completeCached("agents")
+	}
+	sessionKillCmd.ValidArgsFunction = completeCached("sessions")
+	for _, c := range []*cobra.Command{settingsGetCmd, settingsSetCmd} {
+		c.ValidArgsFunction = completeCached("settings")
+	}
+}
+
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var configShowAll bool
+
+// settingFlags are the global flags overriding a setting
+var settingFlags = map[string]string{
+	"OAC_CA_BUNDLE":            "ca-bundle",
+	"OAC_CLIENT_CERT":          "client-cert",
+	"OAC_CLIENT_KEY":           "client-key",
+	"OAC_INSECURE_SKIP_VERIFY": "insecure-skip-verify",
+	"OAC_TLS_MIN_VERSION":      "tls-min-version",
+	"OAC_TLS_CIPHERS":          "tls-ciphers",
+	"OAC_RATE_LIMIT":           "rate",
+	"OAC_MAX_RESPONSE_SIZE":    "max-response-size",
+	"OAC_API_VERSION":          "api-version",
+}
+
+// configCmd groups the configuration commands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the effective configuration",
+}
+
+// configShowCmd prints the effective settings with the layer each comes from
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective settings and where they come from",
+	Long: `Show the effective settings and where they come from.
+
+Settings are resolved in this order, the first one set wins:
+
+  1. flags (--instance, --scope, --ca-bundle, ...)
+  2. variables exported in the environment
+  3. the selected profile of the config file
+  4. .env in the current directory, then .env next to the config file
+  5. the env section of the config file
+
+Profiles rank above .env files, so that a .env holding one instance's
+settings does not send the requests of every profile to that instance.
+
+Client secrets and passwords are masked unless they reference a vault.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		file := cfg.Path
+		if _, err := os.Stat(file); err != nil {
+			file += " (not found)"
+		}
+		fmt.Printf("Config file: %s (%s)\n", file, configFileSource(cmd))
+		profile := client.ProfileName()
+		if profile == "" {
+			profile = "(none)"
+		} else {
+			profile += " (" + profileSource(cmd, cfg) + ")"
+		}
+		fmt.Printf("Profile:     %s\n", profile)
+		var dotEnv []string
+		for _, f := range oac.DotEnvFiles(cfg.Path) {
+			if _, err := os.Stat(f); err == nil {
+				dotEnv = append(dotEnv, f)
+			}
+		}
+		if len(dotEnv) > 0 {
+			fmt.Printf(".env files:  %s\n", strings.Join(dotEnv, ", "))
+		}
+		fmt.Println()
+
+		fmt.Printf("%-24s %-50s %s\n", "SETTING", "VALUE", "SOURCE")
+		for _, s := range client.Settings() {
+			if flag, ok := settingFlags[s.Key]; ok && cmd.Flags().Changed(flag) {
+				s.Value, s.Source = cmd.Flag(flag).Value.String(), "--"+flag
+			}
+			if s.Source == "" {
+				if !configShowAll {
+					continue
+				}
+				s.Source = "(not set)"
+			}
+			fmt.Printf("%-24s %-50s %s\n", s.Key, s.Masked(), s.Source)
+		}
+		return nil
+	},
+}
+
+// configFileSource names where the config file path comes from
+func configFileSource(cmd *cobra.Command) string {
+	switch {
+	case cmd.Flags().Changed("config"):
+		return "--config"
+	case os.Getenv("OAC_CONFIG") != "":
+		return "OAC_CONFIG from " + oac.EnvSource("OAC_CONFIG")
+	}
+	return "default"
+}
+
+// profileSource names where the selected profile comes from
+func profileSource(cmd *cobra.Command, cfg *oac.Config) string {
+	switch {
+	case cmd.Flags().Changed("profile"):
+		return "--profile"
+	case os.Getenv("OAC_PROFILE") != "":
+		return "OAC_PROFILE from " + oac.EnvSource("OAC_PROFILE")
+	}
+	return "default_profile of " + cfg.Path
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&configShowAll, "all", false, "also list settings that are not set")
+
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
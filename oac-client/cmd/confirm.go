@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"oac-client/core/oac"
+)
+
+var assumeYes bool
+
+// promptMu keeps prompts from concurrent batch workers apart
+var promptMu sync.Mutex
+
+// confirmOnTerminal asks on the controlling terminal before a protected request is sent,
+// and refuses when there is no terminal and --yes was not given
+func confirmOnTerminal(profile, method, path string) error {
+	if assumeYes {
+		return nil
+	}
+
+	// stdin may carry the request body, so prompt on the terminal itself
+	in, out, closeTerminal, err := openTerminal()
+	if err != nil {
+		return fmt.Errorf("%w: %s %s is protected on profile %q, pass --yes to run it non-interactively",
+			oac.ErrNotConfirmed, method, path, profile)
+	}
+	defer closeTerminal()
+
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	fmt.Fprintf(out, "%s %s on profile %q. Continue? [y/N] ", method, path, profile)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s", oac.ErrNotConfirmed, method, path)
+}
+
+// openTerminal opens the console to prompt on: /dev/tty, CONIN$ and CONOUT$ on Windows,
+// or stdin and stderr when stdin is a terminal that /dev/tty cannot be opened for
+func openTerminal() (io.Reader, io.Writer, func(), error) {
+	if runtime.GOOS == "windows" {
+		in, err := os.OpenFile("CONIN$", os.O_RDWR, 0)
+		if err == nil {
+			out, err := os.OpenFile("CONOUT$", os.O_RDWR, 0)
+			if err == nil {
+				return in, out, func() { in.Close(); out.Close() }, nil
+			}
+			in.Close()
+		}
+	} else if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		return tty, tty, func() { tty.Close() }, nil
+	}
+	if isTerminal(os.Stdin) {
+		return os.Stdin, os.Stderr, func() {}, nil
+	}
+	return nil, nil, nil, errors.New("no terminal")
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "do not ask before requests protected by the profile (required when not on a terminal)")
+}
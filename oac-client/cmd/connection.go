@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+// connectionCmd groups data connection commands
+var connectionCmd = &cobra.Command{
+	Use:     "connection",
+	Aliases: []string{"connections"},
+	Short:   "Manage OAC data connections",
+}
+
+var connectionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List data connections",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		items, err := client.ListConnections()
+		if err != nil {
+			return fmt.Errorf("error listing connections: %w", err)
+		}
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			fmt.Printf("%-40s %s\n", item.Name, item.ID)
+			ids = append(ids, item.ID)
+		}
+		rememberCompletions("connections", ids)
+		return nil
+	},
+}
+
+var connectionGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Show a connection definition",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return connectionAction(func(client *oac.OacClient) (json.RawMessage, error) {
+			return client.GetConnection(args[0])
+		})
+	},
+}
+
+var connectionCreateCmd = &cobra.Command{
+	Use:     "create <spec.yaml|spec.json>",
+	Short:   "Create a connection from a YAML or JSON spec",
+	Example: `  oac-client connection create adw-sales.yaml`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := oac.LoadSpecFile(args[0])
+		if err != nil {
+			return err
+		}
+		return connectionAction(func(client *oac.OacClient) (json.RawMessage, error) {
+			return client.CreateConnection(spec)
+		})
+	},
+}
+
+var connectionUpdateCmd = &cobra.Command{
+	Use:   "update <id> <spec.yaml|spec.json>",
+	Short: "Replace a connection definition from a YAML or JSON spec",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := oac.LoadSpecFile(args[1])
+		if err != nil {
+			return err
+		}
+		return connectionAction(func(client *oac.OacClient) (json.RawMessage, error) {
+			return client.UpdateConnection(args[0], spec)
+		})
+	},
+}
+
+var connectionDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a connection",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return connectionAction(func(client *oac.OacClient) (json.RawMessage, error) {
+			return nil, client.DeleteConnection(args[0])
+		})
+	},
+}
+
+var connectionTestCmd = &cobra.Command{
+	Use:   "test <id>",
+	Short: "Validate a connection's credentials against the target database",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return connectionAction(func(client *oac.OacClient) (json.RawMessage, error) {
+			return client.TestConnection(args[0])
+		})
+	},
+}
+
+// connectionAction runs fn with a new client and prints its JSON result
+func connectionAction(fn func(*oac.OacClient) (json.RawMessage, error)) error {
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+
+	resp, err := fn(client)
+	if err != nil {
+		return fmt.Errorf("error executing REST call: %w", err)
+	}
+	return printJSON(resp)
+}
+
+func init() {
+	connectionCmd.AddCommand(connectionListCmd, connectionGetCmd, connectionCreateCmd,
+		connectionUpdateCmd, connectionDeleteCmd, connectionTestCmd)
+	rootCmd.AddCommand(connectionCmd)
+}
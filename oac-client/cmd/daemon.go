@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonJobsFile     string
+	daemonHealthAddr   string
+	daemonJobTimeout   time.Duration
+	daemonShutdownWait time.Duration
+)
+
+// daemonCmd runs the jobs of a jobs file on their cron schedules
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run recurring jobs on cron schedules",
+	Long: `Run recurring jobs (snapshot backups, dataset reloads, cache purges, ...) on
+cron schedules as a long-lived process.
+
+Each job is an oac-client command line run as a child process:
+
+  jobs:
+    - name: nightly-backup
+      schedule: "0 2 * * *"
+      args: [snapshot, schedule, --keep, "7", --target-dir, /backups/oac]
+    - name: reload-sales
+      schedule: "*/30 6-20 * * 1-5"
+      profile: prod
+      timeout: 15m
+      args: [POST, /api/20210901/datasets/sales/reload]
+
+A job is skipped when its previous run is still going. With --health-addr,
+GET /healthz reports the last and next run of every job. On SIGINT or SIGTERM
+no new runs start and running jobs get --shutdown-wait to finish.`,
+	Example: `  oac-client daemon --jobs jobs.yaml --health-addr :8081`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobs, err := oac.LoadJobs(daemonJobsFile)
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		ctx := commandCtx
+
+		d := &daemon{exe: exe, jobs: make([]*jobState, len(jobs))}
+		for i, job := range jobs {
+			d.jobs[i] = &jobState{Job: job}
+		}
+
+		var srv *http.Server
+		if daemonHealthAddr != "" {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthz", d.serveHealth)
+			srv = &http.Server{Addr: daemonHealthAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					slog.Error("health endpoint failed", "error", err)
+				}
+			}()
+		}
+
+		slog.Info("daemon started", "jobs", len(jobs), "file", daemonJobsFile)
+		d.run(ctx)
+
+		slog.Info("shutting down, waiting for running jobs", "wait", daemonShutdownWait)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), daemonShutdownWait)
+		defer cancel()
+		if srv != nil {
+			_ = srv.Shutdown(shutdownCtx)
+		}
+		return d.wait(shutdownCtx)
+	},
+}
+
+// jobState is a job with the outcome of its runs
+type jobState struct {
+	*oac.Job
+
+	running  bool
+	cancel   context.CancelFunc
+	runs     int
+	failures int
+	lastRun  time.Time
+	lastErr  string
+	lastTook time.Duration
+	next     time.Time
+}
+
+// daemon schedules jobs and tracks their runs
+type daemon struct {
+	exe  string
+	jobs []*jobState
+
+	mu      sync.Mutex
+	running sync.WaitGroup
+	started time.Time
+}
+
+// run starts due jobs every minute until ctx is done
+func (d *daemon) run(ctx context.Context) {
+	d.mu.Lock()
+	d.started = time.Now()
+	for _, j := range d.jobs {
+		j.next = j.Cron.Next(d.started)
+		slog.Info("job scheduled", "job", j.Name, "schedule", j.Cron.String(), "next", j.next.Format(time.RFC3339))
+	}
+	d.mu.Unlock()
+
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		d.mu.Lock()
+		now = time.Now()
+		for _, j := range d.jobs {
+			if j.next.IsZero() || now.Before(j.next) {
+				continue
+			}
+			j.next = j.Cron.Next(now)
+			if j.running {
+				slog.Warn("job skipped, previous run still going", "job", j.Name)
+				continue
+			}
+			d.start(j)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// start runs a job in the background; d.mu must be held
+func (d *daemon) start(j *jobState) {
+	timeout := j.Timeout
+	if timeout <= 0 {
+		timeout = daemonJobTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	j.running = true
+	j.cancel = cancel
+	d.running.Add(1)
+
+	go func() {
+		defer d.running.Done()
+		defer cancel()
+
+		start := time.Now()
+		slog.Info("job started", "job", j.Name)
+		err := d.exec(ctx, j.Job)
+		took := time.Since(start)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		j.running = false
+		j.cancel = nil
+		j.runs++
+		j.lastRun = start
+		j.lastTook = took
+		j.lastErr = ""
+		if err != nil {
+			j.failures++
+			j.lastErr = err.Error()
+			slog.Error("job failed", "job", j.Name, "duration", took.Round(time.Millisecond), "error", err)
+			return
+		}
+		slog.Info("job finished", "job", j.Name, "duration", took.Round(time.Millisecond))
+	}()
+}
+
+// exec runs the job's command line with this executable
+func (d *daemon) exec(ctx context.Context, job *oac.Job) error {
+	var args []string
+	if job.Profile != "" {
+		args = append(args, "--profile", job.Profile)
+	}
+	args = append(args, job.Args...)
+
+	c := exec.CommandContext(ctx, d.exe, args...)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), "OAC_JOB="+job.Name)
+	c.Cancel = func() error { return c.Process.Signal(syscall.SIGTERM) }
+	c.WaitDelay = 30 * time.Second
+	if err := c.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+		return err
+	}
+	return nil
+}
+
+// wait lets running jobs finish, cancelling those still going when ctx is done
+func (d *daemon) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	var names []string
+	for _, j := range d.jobs {
+		if j.cancel != nil {
+			names = append(names, j.Name)
+			j.cancel()
+		}
+	}
+	d.mu.Unlock()
+	<-done
+	return fmt.Errorf("jobs cancelled at shutdown: %v", names)
+}
+
+// jobHealth is the /healthz view of a job
+type jobHealth struct {
+	Name       string    `json:"name"`
+	Schedule   string    `json:"schedule"`
+	Running    bool      `json:"running"`
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	LastRun    time.Time `json:"lastRun,omitzero"`
+	LastTookMs int64     `json:"lastDurationMs,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+	NextRun    time.Time `json:"nextRun,omitzero"`
+}
+
+// serveHealth reports the daemon's jobs; it answers 503 when the last run of any job failed
+func (d *daemon) serveHealth(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	status := "ok"
+	jobs := make([]jobHealth, len(d.jobs))
+	for i, j := range d.jobs {
+		jobs[i] = jobHealth{
+			Name:       j.Name,
+			Schedule:   j.Cron.String(),
+			Running:    j.running,
+			Runs:       j.runs,
+			Failures:   j.failures,
+			LastRun:    j.lastRun,
+			LastTookMs: j.lastTook.Milliseconds(),
+			LastError:  j.lastErr,
+			NextRun:    j.next,
+		}
+		if j.lastErr != "" {
+			status = "failing"
+		}
+	}
+	uptime := time.Since(d.started).Round(time.Second).String()
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": status, "uptime": uptime, "jobs": jobs})
+}
+
+func init() {
+	flags := daemonCmd.Flags()
+	flags.StringVarP(&daemonJobsFile, "jobs", "f", "jobs.yaml", "YAML file defining the jobs")
+	flags.StringVar(&daemonHealthAddr, "health-addr", "", "address serving GET /healthz, e.g. :8081")
+	flags.DurationVar(&daemonJobTimeout, "job-timeout", time.Hour, "maximum run time of a job without its own timeout")
+	flags.DurationVar(&daemonShutdownWait, "shutdown-wait", 5*time.Minute, "time running jobs get to finish on shutdown")
+
+	rootCmd.AddCommand(daemonCmd)
+}
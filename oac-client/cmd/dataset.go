@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	datasetRows    int
+	datasetFormat  string
+	datasetMaxRows int
+	datasetJSON    bool
+
+	datasetPushFile      string
+	datasetPushName      string
+	datasetPushMode      string
+	datasetPushChunk     string
+	datasetPushDelimiter string
+	datasetPushRestart   bool
+	datasetPushNoWait    bool
+)
+
+// datasetCmd groups the dataset commands
+var datasetCmd = &cobra.Command{
+	Use:   "dataset",
+	Short: "Inspect and load datasets",
+}
+
+// datasetPreviewCmd prints the first rows of a dataset
+var datasetPreviewCmd = &cobra.Command{
+	Use:   "preview <dataset>",
+	Short: "Print the first rows of a dataset",
+	Long: `Print the first rows of a dataset as CSV or JSON.
+
+The dataset is given by its id, or as owner.name.`,
+	Example: `  oac-client dataset preview admin.Sales --rows 50
+  oac-client dataset preview J2FkbWluJy4nU2FsZXMn --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w, err := newQueryWriter(datasetFormat, true)
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		page, err := client.PreviewDataset(args[0], datasetRows)
+		if err != nil {
+			return err
+		}
+		if err := w.Page(page); err != nil {
+			return err
+		}
+		return w.Close()
+	},
+}
+
+// datasetProfileCmd prints per-column statistics of a dataset
+var datasetProfileCmd = &cobra.Command{
+	Use:   "profile <dataset>",
+	Short: "Print column types, null counts and basic statistics of a dataset",
+	Long: `Read a dataset and print, for every column, its type, the number of nulls
+and distinct values, the minimum and maximum, and the mean of numeric columns.
+Use it to sanity-check a dataset after a reload. --max-rows profiles only the
+first rows of large datasets.`,
+	Example: `  oac-client dataset profile admin.Sales
+  oac-client dataset profile admin.Sales --max-rows 100000 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		profiles, rows, err := client.ProfileDataset(args[0], datasetMaxRows)
+		if err != nil {
+			return err
+		}
+		if datasetJSON {
+			data, err := json.Marshal(map[string]any{"rows": rows, "columns": profiles})
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+
+		fmt.Printf("%-30s %-10s %8s %8s %20s %20s %14s\n", "COLUMN", "TYPE", "NULLS", "DISTINCT", "MIN", "MAX", "MEAN")
+		for _, p := range profiles {
+			mean := ""
+			if p.Mean != nil {
+				mean = strconv.FormatFloat(*p.Mean, 'g', 8, 64)
+			}
+			fmt.Printf("%-30s %-10s %8d %8d %20s %20s %14s\n", truncate(p.Name, 30), truncate(p.Type, 10),
+				p.Nulls, p.Distinct, truncate(csvValue(p.Min), 20), truncate(csvValue(p.Max), 20), mean)
+		}
+		infof("\n%d rows, %d columns", rows, len(profiles))
+		return nil
+	},
+}
+
+// datasetPushCmd loads a local CSV or Parquet file into a dataset
+var datasetPushCmd = &cobra.Command{
+	Use:   "push --file <file> --name <dataset>",
+	Short: "Load a local CSV or Parquet file into a dataset and reload it",
+	Long: `Load a local CSV or Parquet file into a dataset and reload it.
+
+The file is converted to CSV and uploaded in chunks of about --chunk-size;
+--mode replace overwrites the data of the dataset and --mode append adds to
+it. Progress is recorded in a hidden .push file next to the input, so when an
+upload fails, running the same command again resumes after the last chunk
+uploaded. --restart starts over instead.`,
+	Example: `  oac-client dataset push --file sales.parquet --name Sales --mode replace
+  oac-client dataset push --file daily.csv --name Sales --mode append --delimiter ';'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chunkSize, err := oac.ParseSize(datasetPushChunk)
+		if err != nil {
+			return fmt.Errorf("invalid --chunk-size: %w", err)
+		}
+		var delimiter rune
+		if datasetPushDelimiter != "" {
+			r := []rune(datasetPushDelimiter)
+			if datasetPushDelimiter == `\t` {
+				r = []rune{'\t'}
+			}
+			if len(r) != 1 {
+				return fmt.Errorf("--delimiter must be a single character")
+			}
+			delimiter = r[0]
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		opts := oac.PushOptions{
+			Name:      datasetPushName,
+			Mode:      datasetPushMode,
+			ChunkSize: chunkSize,
+			Delimiter: delimiter,
+			Restart:   datasetPushRestart,
+			OnChunk: func(chunk, rows int, size int64, skipped bool) {
+				if skipped {
+					infof("Chunk %d: already uploaded, skipped", chunk+1)
+				} else {
+					infof("Chunk %d: %d rows, %s", chunk+1, rows, byteSize(size))
+				}
+			},
+		}
+		result, err := client.PushDataset(datasetPushFile, opts)
+		if err != nil {
+			return err
+		}
+		infof("Uploaded %d rows in %d chunks to %s", result.Rows, result.Chunks, datasetPushName)
+
+		if result.WorkRequestID == "" || datasetPushNoWait {
+			return nil
+		}
+		if _, err := waitWorkRequest(client, "Reloading "+datasetPushName, result.WorkRequestID, 5*time.Second, time.Hour); err != nil {
+			return err
+		}
+		infof("Reloaded %s", datasetPushName)
+		return nil
+	},
+}
+
+func init() {
+	datasetPreviewCmd.Flags().IntVar(&datasetRows, "rows", 20, "number of rows to print")
+	datasetPreviewCmd.Flags().StringVar(&datasetFormat, "format", "csv", "output format: csv or json")
+	datasetProfileCmd.Flags().IntVar(&datasetMaxRows, "max-rows", 0, "profile only the first rows (0 for all)")
+	datasetProfileCmd.Flags().BoolVar(&datasetJSON, "json", false, "print the profile as JSON")
+
+	pushFlags := datasetPushCmd.Flags()
+	pushFlags.StringVarP(&datasetPushFile, "file", "f", "", "CSV or Parquet file to load")
+	pushFlags.StringVar(&datasetPushName, "name", "", "dataset to load")
+	pushFlags.StringVar(&datasetPushMode, "mode", "replace", "replace or append")
+	pushFlags.StringVar(&datasetPushChunk, "chunk-size", "8MiB", "approximate size of each uploaded chunk")
+	pushFlags.StringVar(&datasetPushDelimiter, "delimiter", "", `field delimiter of CSV input (default ","; \t for tab)`)
+	pushFlags.BoolVar(&datasetPushRestart, "restart", false, "ignore an interrupted push and start from the first chunk")
+	pushFlags.BoolVar(&datasetPushNoWait, "no-wait", false, "do not wait for the reload to finish")
+	_ = datasetPushCmd.MarkFlagRequired("file")
+	_ = datasetPushCmd.MarkFlagRequired("name")
+
+	datasetCmd.AddCommand(datasetPreviewCmd, datasetProfileCmd, datasetPushCmd)
+	rootCmd.AddCommand(datasetCmd)
+}
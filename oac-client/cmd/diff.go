@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFrom string
+	diffTo   string
+	diffPath string
+	diffHash bool
+	diffAll  bool
+)
+
+// diffCmd compares catalog content between two profiles
+var diffCmd = &cobra.Command{
+	Use:   "diff --from <profile> --to <profile>",
+	Short: "Compare catalog content between two instances",
+	Long: `Compare catalog content between two instances.
+
+Each item under --path is reported as:
+  missing    only in the --from instance
+  extra      only in the --to instance
+  different  in both, with a different modified timestamp (or definition with --hash)
+
+Examples:
+  oac-client diff --from dev --to prod --path /shared/Finance
+  oac-client diff --from dev --to prod --hash
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := newClientForProfile(diffFrom)
+		if err != nil {
+			return fmt.Errorf("failed to create client for %s: %w", diffFrom, err)
+		}
+		dst, err := newClientForProfile(diffTo)
+		if err != nil {
+			return fmt.Errorf("failed to create client for %s: %w", diffTo, err)
+		}
+
+		entries, err := oac.CompareCatalog(src, dst, diffPath, diffHash)
+		if err != nil {
+			return fmt.Errorf("error comparing catalogs: %w", err)
+		}
+
+		for _, e := range entries {
+			if e.Status == oac.DiffSame && !diffAll {
+				continue
+			}
+			fmt.Printf("%-10s %-14s %s\n", e.Status, e.Type, e.Path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "source profile")
+	diffCmd.Flags().StringVar(&diffTo, "to", "", "target profile")
+	diffCmd.Flags().StringVar(&diffPath, "path", "/shared", "catalog folder to compare")
+	diffCmd.Flags().BoolVar(&diffHash, "hash", false, "compare exported definitions instead of modified timestamps")
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "also list items that are the same")
+	_ = diffCmd.MarkFlagRequired("from")
+	_ = diffCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(diffCmd)
+}
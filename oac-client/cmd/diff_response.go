@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffResponseAgainst string
+	diffResponseJSON    bool
+)
+
+// diffResponseCmd compares two responses, or a response with a file
+var diffResponseCmd = &cobra.Command{
+	Use:   "diff-response <method> <path> [<method> <path> | --against <file>]",
+	Short: "Compare two API responses, or a response with a JSON file",
+	Long: `Compare two API responses, or a response with a JSON file, and print the
+keys added (+), removed (-) and changed (~) from the first to the second.
+
+Arrays are compared element by element. Save a response before an update and
+compare the object afterwards with --against.`,
+	Example: `  oac-client diff-response GET /api/20210901/snapshots/A GET /api/20210901/snapshots/B
+  oac-client -s GET /api/20210901/catalog/connections/$ID > before.json
+  oac-client diff-response GET /api/20210901/catalog/connections/$ID --against before.json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if diffResponseAgainst != "" {
+			return cobra.ExactArgs(2)(cmd, args)
+		}
+		if len(args) != 4 {
+			return fmt.Errorf("expected two requests, <method> <path> <method> <path>, or one request and --against")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		from, err := fetchForDiff(client, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		var to []byte
+		if diffResponseAgainst != "" {
+			// the file is the earlier state, the response the current one
+			if to, err = os.ReadFile(diffResponseAgainst); err != nil {
+				return err
+			}
+			from, to = to, from
+		} else if to, err = fetchForDiff(client, args[2], args[3]); err != nil {
+			return err
+		}
+
+		changes, err := oac.DiffJSON(from, to)
+		if err != nil {
+			return err
+		}
+		if diffResponseJSON {
+			if changes == nil {
+				changes = []oac.JSONChange{}
+			}
+			data, err := json.Marshal(changes)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+		if len(changes) == 0 {
+			infof("No differences")
+			return nil
+		}
+		printChanges(changes)
+		return nil
+	},
+}
+
+// diffColors are the terminal colors of added, removed and changed values
+var diffColors = map[string]string{"+": "\x1b[32m", "-": "\x1b[31m", "~": "\x1b[33m"}
+
+// printChanges prints JSON changes one per line, colored on a terminal
+func printChanges(changes []oac.JSONChange) {
+	color := useColor(os.Stdout)
+	for _, ch := range changes {
+		line := ch.String()
+		if color {
+			line = diffColors[ch.Op] + line + "\x1b[0m"
+		}
+		fmt.Println(line)
+	}
+}
+
+// fetchForDiff performs one request of diff-response and returns its body
+func fetchForDiff(client *oac.OacClient, method, path string) ([]byte, error) {
+	resp, err := client.Do(&oac.Request{Method: strings.ToUpper(method), Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+	}
+	return resp.Bytes(), nil
+}
+
+func init() {
+	diffResponseCmd.Flags().StringVar(&diffResponseAgainst, "against", "", "JSON file holding the earlier state to compare the response with")
+	diffResponseCmd.Flags().BoolVar(&diffResponseJSON, "json", false, "print the changes as a JSON array")
+
+	rootCmd.AddCommand(diffResponseCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the CLI
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitAuth        = 3
+	exitClientError = 4
+	exitServerError = 5
+	exitTimeout     = 6
+	exitInterrupted = 130
+)
+
+var failSilently bool
+
+// runError marks errors raised while running a command, as opposed to usage errors
+type runError struct {
+	err error
+}
+
+func (e *runError) Error() string { return e.err.Error() }
+func (e *runError) Unwrap() error { return e.err }
+
+// exitStatus ends a command with a specific exit code, e.g. the Nagios plugin codes;
+// an empty message prints nothing, the command has reported the outcome itself
+type exitStatus struct {
+	code int
+	msg  string
+}
+
+func (e *exitStatus) Error() string { return e.msg }
+
+// markRunErrors wraps every RunE so failures after argument validation are told apart from usage errors
+func markRunErrors(c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			if err := run(cmd, args); err != nil {
+				cmd.SilenceUsage = true
+				return &runError{err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range c.Commands() {
+		markRunErrors(sub)
+	}
+}
+
+// exitCode maps an error onto the documented exit codes
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var re *runError
+	if !errors.As(err, &re) {
+		return exitUsage
+	}
+	var status *exitStatus
+	if errors.As(err, &status) {
+		return status.code
+	}
+
+	var timeoutErr *oac.TimeoutError
+	var authErr *oac.AuthError
+	var openErr *oac.CircuitOpenError
+	switch {
+	case errors.As(err, &timeoutErr), errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.As(err, &authErr):
+		return exitAuth
+	case errors.As(err, &openErr):
+		return exitServerError
+	}
+
+	switch status := oac.StatusCode(err); {
+	case status == 401 || status == 403:
+		return exitAuth
+	case status >= 500:
+		return exitServerError
+	case status >= 400:
+		return exitClientError
+	}
+
+	return exitError
+}
+
+// reportError prints err to stderr, leaving out the response body with --fail-silently
+func reportError(err error) {
+	var status *exitStatus
+	if errors.As(err, &status) && status.msg == "" {
+		return
+	}
+	var apiErr *oac.APIError
+	if failSilently && errors.As(err, &apiErr) {
+		fmt.Fprintf(os.Stderr, "%s request failed with status %d", errorPrefix(), apiErr.StatusCode)
+		if apiErr.OCIRequestID != "" {
+			fmt.Fprintf(os.Stderr, " (opc-request-id: %s)", apiErr.OCIRequestID)
+		}
+		fmt.Fprintln(os.Stderr)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %v\n", errorPrefix(), err)
+}
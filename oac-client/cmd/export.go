@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat  string
+	exportOut     string
+	exportType    string
+	exportTimeout time.Duration
+)
+
+// exportCmd renders a report or workbook to a file
+var exportCmd = &cobra.Command{
+	Use:   "export <catalog path>",
+	Short: "Export an analysis or workbook to PDF, XLSX or CSV",
+	Long: `Export an analysis or workbook to PDF, XLSX or CSV.
+
+The render job runs asynchronously on the instance; the command waits for it
+and downloads the file. --out defaults to the item name with the format's
+extension, use --out - to write to stdout. A file is checked against the
+SHA-256 reported by the instance, when there is one, and its checksum is
+written next to it as <file>.sha256.`,
+	Example: `  oac-client export /shared/Finance/Revenue --format pdf --out revenue.pdf
+  oac-client export /shared/Sales/Pipeline --type workbooks --format xlsx
+  oac-client export /shared/Finance/Revenue --format csv --out - | head`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(exportFormat)
+		out := exportOut
+		if out == "" {
+			out = path.Base(args[0]) + "." + format
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		wrID, err := client.StartExport(exportType, args[0], format)
+		if err != nil {
+			return fmt.Errorf("failed to start export: %w", err)
+		}
+		if _, err := waitWorkRequest(client, "Rendering "+path.Base(args[0]), wrID, 2*time.Second, exportTimeout); err != nil {
+			return err
+		}
+
+		body, err := client.StreamExport(wrID)
+		if err != nil {
+			return fmt.Errorf("failed to download export: %w", err)
+		}
+		defer body.Close()
+
+		if out == "-" {
+			if _, err := io.Copy(os.Stdout, body); err != nil {
+				return err
+			}
+			return body.Verify("export")
+		}
+
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		progress := newTransfer("Downloading "+out, 0)
+		_, err = io.Copy(f, progress.Reader(body))
+		progress.Done()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = body.Verify(out)
+		}
+		if err != nil {
+			os.Remove(out)
+			return fmt.Errorf("failed to download export: %w", err)
+		}
+		if err := oac.WriteChecksum(out, body.Sum()); err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Fprintln(os.Stderr, "Saved", out)
+		}
+		return nil
+	},
+}
+
+func init() {
+	flags := exportCmd.Flags()
+	flags.StringVar(&exportFormat, "format", "pdf", "output format: pdf, xlsx or csv")
+	flags.StringVarP(&exportOut, "out", "o", "", "file to write, - for stdout")
+	flags.StringVar(&exportType, "type", "reports", "catalog type of the item: reports or workbooks")
+	flags.DurationVar(&exportTimeout, "timeout", 15*time.Minute, "maximum time to wait for the render job")
+
+	rootCmd.AddCommand(exportCmd)
+}
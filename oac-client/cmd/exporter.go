@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterPort     int
+	exporterAddress  string
+	exporterInterval time.Duration
+)
+
+// exporterCmd serves OAC operational metrics to Prometheus
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve OAC operational metrics to Prometheus",
+	Long: `Scrape operational metrics of the instance through the REST API every
+--interval and serve them on /metrics in the Prometheus text format:
+
+  oac_sessions_active, oac_sessions_running_queries      open sessions
+  oac_cache_entries, oac_cache_hits_total,
+  oac_cache_misses_total, oac_cache_hit_ratio            query cache usage
+  oac_agents{state}, oac_agents_failed,
+  oac_agent_last_run_failed{agent,path}                  agents
+  oac_snapshots, oac_snapshot_size_bytes{snapshot,id},
+  oac_snapshot_created_timestamp_seconds{snapshot,id}    snapshots
+
+oac_scrape_success{source} is 0 for a source that failed, e.g. when the
+client's role may not list sessions; the other sources are still served.
+Prometheus scrapes the cached result, so scraping often does not load the
+instance.`,
+	Example: `  oac-client exporter --port 9090 --interval 1m --profile prod`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exporterInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		ctx := commandCtx
+
+		e := &exporter{client: client}
+		e.scrape()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", e.serveMetrics)
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintln(w, `<html><body><h1>oac-client exporter</h1><a href="/metrics">Metrics</a></body></html>`)
+		})
+		addr := net.JoinHostPort(exporterAddress, strconv.Itoa(exporterPort))
+		srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		errs := make(chan error, 1)
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+		slog.Info("exporter listening", "addr", addr, "interval", exporterInterval)
+
+		ticker := time.NewTicker(exporterInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.scrape()
+			case err := <-errs:
+				return fmt.Errorf("failed to serve metrics: %w", err)
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				return srv.Shutdown(shutdownCtx)
+			}
+		}
+	},
+}
+
+// exporter holds the metrics of the last scrape
+type exporter struct {
+	client *oac.OacClient
+
+	mu      sync.Mutex
+	metrics []byte
+}
+
+// scrape collects the metrics of the instance and replaces the served ones
+func (e *exporter) scrape() {
+	start := time.Now()
+	metrics := e.client.CollectMetrics()
+	last := oac.Metric{Name: "oac_exporter_last_scrape_timestamp_seconds", Help: "When the metrics were last scraped.", Type: "gauge"}
+	last.Samples = []oac.Sample{{Value: float64(start.Unix())}}
+	metrics = append(metrics, last)
+
+	var buf bytes.Buffer
+	if err := oac.WriteMetrics(&buf, metrics); err != nil {
+		slog.Error("failed to write metrics", "error", err)
+		return
+	}
+	e.mu.Lock()
+	e.metrics = buf.Bytes()
+	e.mu.Unlock()
+	slog.Debug("metrics scraped", "duration", time.Since(start))
+}
+
+func (e *exporter) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	metrics := e.metrics
+	e.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(metrics)
+}
+
+func init() {
+	flags := exporterCmd.Flags()
+	flags.IntVar(&exporterPort, "port", 9090, "port serving /metrics")
+	flags.StringVar(&exporterAddress, "address", "", "address to listen on, all interfaces when empty")
+	flags.DurationVar(&exporterInterval, "interval", time.Minute, "how often the instance is scraped")
+
+	rootCmd.AddCommand(exporterCmd)
+}
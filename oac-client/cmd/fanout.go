@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"oac-client/core/oac"
+)
+
+// fanOutResult is the source-labelled outcome of one request in a fan-out
+type fanOutResult struct {
+	Source   string          `json:"source"`
+	Instance string          `json:"instance,omitempty"`
+	Status   int             `json:"status,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// fanOutTarget is a profile, or an instance URL used with the current profile
+type fanOutTarget struct {
+	label    string
+	profile  string
+	instance string
+}
+
+// fanOutTargets resolves --instances or --all-profiles
+func fanOutTargets() ([]fanOutTarget, error) {
+	if allProfiles {
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no profiles configured for --all-profiles")
+		}
+		targets := make([]fanOutTarget, len(names))
+		for i, name := range names {
+			targets[i] = fanOutTarget{label: name, profile: name}
+		}
+		return targets, nil
+	}
+
+	var targets []fanOutTarget
+	for _, v := range fanOutInstances {
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "://") {
+			targets = append(targets, fanOutTarget{label: v, profile: profileName, instance: v})
+		} else if v != "" {
+			targets = append(targets, fanOutTarget{label: v, profile: v})
+		}
+	}
+	return targets, nil
+}
+
+// fanOutCall runs a read-only request against every target in parallel and prints the
+// results as one JSON array, in target order
+func fanOutCall(method, path string) error {
+	if method != "GET" && method != "HEAD" && method != "OPTIONS" {
+		return fmt.Errorf("--instances and --all-profiles only run GET, HEAD and OPTIONS requests")
+	}
+
+	targets, err := fanOutTargets()
+	if err != nil {
+		return err
+	}
+	header, err := parseHeaders(headerFlags)
+	if err != nil {
+		return err
+	}
+	query, err := parseQuery(queryFlags)
+	if err != nil {
+		return err
+	}
+
+	results := make([]fanOutResult, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fanOutOne(t, &oac.Request{Method: method, Path: path, Query: query, Header: header.Clone()})
+		}()
+	}
+	wg.Wait()
+
+	out, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(out); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instances failed", failed, len(results))
+	}
+	return nil
+}
+
+// fanOutOne executes the request for a single target
+func fanOutOne(t fanOutTarget, req *oac.Request) fanOutResult {
+	result := fanOutResult{Source: t.label}
+
+	client, err := newClientFor(t.profile, t.instance)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Instance = client.Instance()
+	if req.Path, err = expandPath(t.profile, req.Path); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = oac.StatusCode(err)
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = resp.StatusCode
+	switch body := strings.TrimSpace(string(resp.Bytes())); {
+	case body == "":
+	case json.Valid([]byte(body)):
+		result.Body = json.RawMessage(body)
+	default:
+		result.Body, _ = json.Marshal(body)
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	genTypesLang    string
+	genTypesPackage string
+	genTypesName    string
+	genTypesSamples []string
+	genTypesOut     string
+)
+
+// genTypesCmd infers types from live responses
+var genTypesCmd = &cobra.Command{
+	Use:   "gen-types <method> <path>",
+	Short: "Generate Go or TypeScript types from an API response",
+	Long: `Call the API, infer a JSON schema from the response and print Go structs,
+TypeScript interfaces or the JSON Schema itself.
+
+A single response only shows the fields that happen to be set. Add saved
+responses of the same kind with --sample to widen the schema: fields missing
+from some samples become optional (omitempty in Go, ? in TypeScript) and fields
+seen as null become pointers or "| null".
+
+The root type is named after the last path segment unless --name is given.`,
+	Example: `  oac-client gen-types GET /api/20210901/datasets --lang go --package oacmodels > datasets.go
+  oac-client gen-types GET /api/20210901/snapshots --lang ts --out snapshots.ts
+  oac-client gen-types GET /api/20210901/catalog/connections/$ID --name Connection --sample other.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method, path := strings.ToUpper(args[0]), args[1]
+		switch genTypesLang {
+		case "go", "ts", "schema":
+		default:
+			return fmt.Errorf("invalid --lang %q, expected go, ts or schema", genTypesLang)
+		}
+		name := genTypesName
+		if name == "" {
+			name = typeNameForPath(path)
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		resp, err := client.Do(&oac.Request{Method: method, Path: path})
+		if err != nil {
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+
+		var schema oac.ResponseSchema
+		if err := schema.Add(resp.Bytes()); err != nil {
+			return err
+		}
+		for _, sample := range genTypesSamples {
+			data, err := os.ReadFile(sample)
+			if err != nil {
+				return err
+			}
+			if err := schema.Add(data); err != nil {
+				return fmt.Errorf("%s: %w", sample, err)
+			}
+		}
+
+		var out []byte
+		source := method + " " + path
+		switch genTypesLang {
+		case "go":
+			out, err = schema.GoTypes(genTypesPackage, name, source)
+		case "ts":
+			out, err = schema.TypeScript(name, source)
+		case "schema":
+			if out, err = schema.JSONSchema(name); err == nil {
+				out = append(out, '\n')
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if genTypesOut != "" {
+			if err := os.WriteFile(genTypesOut, out, 0o644); err != nil {
+				return err
+			}
+			infof("Wrote %s", genTypesOut)
+			return nil
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	},
+}
+
+// typeNameForPath names the type of a response after the last segment of its path,
+// e.g. Datasets for /api/20210901/datasets
+func typeNameForPath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	last := segments[len(segments)-1]
+	if name := oac.GoIdentifier(last); name != "" && last[0] > '9' {
+		return name
+	}
+	return "Response"
+}
+
+func init() {
+	genTypesCmd.Flags().StringVar(&genTypesLang, "lang", "go", "output: go, ts (TypeScript) or schema (JSON Schema)")
+	genTypesCmd.Flags().StringVar(&genTypesPackage, "package", "models", "package of the generated Go file")
+	genTypesCmd.Flags().StringVar(&genTypesName, "name", "", "name of the root type")
+	genTypesCmd.Flags().StringArrayVar(&genTypesSamples, "sample", nil, "saved response of the same kind to merge into the schema (repeatable)")
+	genTypesCmd.Flags().StringVarP(&genTypesOut, "out", "o", "", "write to this file instead of stdout")
+
+	rootCmd.AddCommand(genTypesCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthFormat  string
+	healthPath    string
+	healthTimeout time.Duration
+	healthWarn    time.Duration
+)
+
+// Nagios plugin exit codes
+const (
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+)
+
+// healthCmd checks the instance, its identity domain and the API
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check that the instance, IDCS and the API respond, with their latency",
+	Long: `Check that the instance, its IDCS token endpoint and the REST API respond,
+measuring the latency of each:
+
+  instance  unauthenticated GET of the instance root, alive below 500
+  idcs      a new token is issued (browser and device logins without a
+            refresh token only check the endpoint answers)
+  api       authenticated GET of --path, a listing of /shared by default
+
+The command fails when any check fails. --format selects the output: text,
+json, prometheus (text exposition, e.g. for the node exporter's textfile
+collector) or nagios (one status line with perfdata, exiting 0 OK, 1 WARNING
+when a check is slower than --warn-latency, 2 CRITICAL).`,
+	Example: `  oac-client health
+  oac-client health --format json
+  oac-client health --format nagios --warn-latency 2s --profile prod
+  oac-client health --format prometheus > /var/lib/node_exporter/oac.prom`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch healthFormat {
+		case "text", "json", "prometheus", "nagios":
+		default:
+			return fmt.Errorf("invalid --format %q, expected text, json, prometheus or nagios", healthFormat)
+		}
+
+		client, err := newClient()
+		if err != nil {
+			if healthFormat == "nagios" {
+				fmt.Printf("OAC CRITICAL - %v\n", err)
+				return &exitStatus{code: nagiosCritical}
+			}
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		report := client.Health(commandCtx, oac.HealthOptions{APIPath: healthPath, Timeout: healthTimeout})
+
+		switch healthFormat {
+		case "json":
+			data, err := json.Marshal(report)
+			if err != nil {
+				return err
+			}
+			if err := printJSON(data); err != nil {
+				return err
+			}
+		case "prometheus":
+			if err := printHealthMetrics(report); err != nil {
+				return err
+			}
+		case "nagios":
+			return nagiosHealth(report)
+		default:
+			for _, ch := range report.Checks {
+				state := "ok"
+				if !ch.OK {
+					state = "FAIL"
+				} else if healthWarn > 0 && ch.Latency > healthWarn {
+					state = "slow"
+				}
+				status := "-"
+				if ch.Status != 0 {
+					status = fmt.Sprint(ch.Status)
+				}
+				line := fmt.Sprintf("%-9s %-5s %-4s %7s  %s", ch.Name, state, status, ch.Latency.Round(time.Millisecond), ch.URL)
+				if ch.Error != "" {
+					line += "  " + ch.Error
+				}
+				fmt.Println(line)
+			}
+		}
+
+		if !report.OK {
+			return fmt.Errorf("health check failed: %s", strings.Join(report.Failed(), ", "))
+		}
+		return nil
+	},
+}
+
+// printHealthMetrics writes the report in the Prometheus text exposition format
+func printHealthMetrics(report *oac.HealthReport) error {
+	up := oac.Metric{Name: "oac_health_up", Help: "Whether the check passed.", Type: "gauge"}
+	latency := oac.Metric{Name: "oac_health_latency_seconds", Help: "How long the check took.", Type: "gauge"}
+	status := oac.Metric{Name: "oac_health_status_code", Help: "HTTP status of the check, 0 without a response.", Type: "gauge"}
+	for _, ch := range report.Checks {
+		labels := []string{"instance", report.Instance, "check", ch.Name}
+		value := 0.0
+		if ch.OK {
+			value = 1
+		}
+		up.Samples = append(up.Samples, oac.Sample{Labels: labels, Value: value})
+		latency.Samples = append(latency.Samples, oac.Sample{Labels: labels, Value: ch.Latency.Seconds()})
+		status.Samples = append(status.Samples, oac.Sample{Labels: labels, Value: float64(ch.Status)})
+	}
+	return oac.WriteMetrics(os.Stdout, []oac.Metric{up, latency, status})
+}
+
+// nagiosHealth prints the report as a Nagios plugin status line with perfdata and
+// returns the matching exit status
+func nagiosHealth(report *oac.HealthReport) error {
+	code, state := nagiosOK, "OK"
+	var summary, perf []string
+	for _, ch := range report.Checks {
+		text := fmt.Sprintf("%s %s", ch.Name, ch.Latency.Round(time.Millisecond))
+		switch {
+		case !ch.OK:
+			text = fmt.Sprintf("%s failed: %s", ch.Name, ch.Error)
+			code, state = nagiosCritical, "CRITICAL"
+		case healthWarn > 0 && ch.Latency > healthWarn:
+			text += " (slow)"
+			if code == nagiosOK {
+				code, state = nagiosWarning, "WARNING"
+			}
+		}
+		summary = append(summary, text)
+		warn := ""
+		if healthWarn > 0 {
+			warn = fmt.Sprintf("%.3f", healthWarn.Seconds())
+		}
+		perf = append(perf, fmt.Sprintf("%s=%.3fs;%s;;0", ch.Name, ch.Latency.Seconds(), warn))
+	}
+	fmt.Printf("OAC %s - %s | %s\n", state, strings.Join(summary, ", "), strings.Join(perf, " "))
+	if code == nagiosOK {
+		return nil
+	}
+	return &exitStatus{code: code}
+}
+
+func init() {
+	flags := healthCmd.Flags()
+	flags.StringVar(&healthFormat, "format", "text", "output: text, json, prometheus or nagios")
+	flags.StringVar(&healthPath, "path", "", "authenticated endpoint to call (default a listing of /shared)")
+	flags.DurationVar(&healthTimeout, "timeout", 10*time.Second, "maximum time for each check")
+	flags.DurationVar(&healthWarn, "warn-latency", 0, "report checks slower than this, WARNING with --format nagios")
+
+	rootCmd.AddCommand(healthCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+
+// historyCmd groups the audit log commands
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show and replay previously executed requests",
+	Long: `Show and replay previously executed requests.
+
+Every request is appended to ~/.local/state/oac-client/history.jsonl with its
+time, profile, method, path, status, duration and caller. Set OAC_HISTORY_FILE
+to use another file, or to "off" to disable the log.`,
+}
+
+// historyListCmd prints the latest entries
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent requests",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := oac.ReadHistory(historyFile())
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[len(entries)-historyLimit:]
+		}
+
+		for _, e := range entries {
+			target := e.Path
+			if e.Query != "" {
+				target += "?" + e.Query
+			}
+			fmt.Printf("%s  %s  %-10s %-7s %3d %6dms  %s\n",
+				e.ID, e.Time.Local().Format("2006-01-02 15:04:05"), e.Profile, e.Method, e.Status, e.DurationMs, target)
+		}
+		return nil
+	},
+}
+
+// historyShowCmd prints one entry in full
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a request from the history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := oac.FindHistory(historyFile(), args[0])
+		if err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+// historyReplayCmd executes a recorded request again with the same profile
+var historyReplayCmd = &cobra.Command{
+	Use:   "replay <id>",
+	Short: "Execute a request from the history again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := oac.FindHistory(historyFile(), args[0])
+		if err != nil {
+			return err
+		}
+
+		profile := entry.Profile
+		if profileName != "" {
+			profile = profileName
+		}
+		client, err := newClientForProfile(profile)
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		if entry.Redacted {
+			return fmt.Errorf("history entry %s held credentials, which were not recorded; send the request again with them", entry.ID)
+		}
+
+		query, err := url.ParseQuery(entry.Query)
+		if err != nil {
+			return fmt.Errorf("invalid query in history entry: %w", err)
+		}
+
+		resp, err := client.Do(&oac.Request{
+			Method: entry.Method,
+			Path:   entry.Path,
+			Query:  query,
+			Body:   bytes.NewReader([]byte(entry.Body)),
+		})
+		if err != nil {
+			if tooLarge := (*oac.ResponseTooLargeError)(nil); errors.As(err, &tooLarge) {
+				return printSpooled(tooLarge)
+			}
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+		return printResponse(resp)
+	},
+}
+
+// historyFile returns the audit log location, warning when it is disabled
+func historyFile() string {
+	file := oac.DefaultHistoryPath()
+	if file == "" {
+		slog.Warn("history is disabled", "OAC_HISTORY_FILE", "off")
+	}
+	return file
+}
+
+func init() {
+	historyListCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of entries to show (0 for all)")
+
+	historyCmd.AddCommand(historyListCmd, historyShowCmd, historyReplayCmd)
+	rootCmd.AddCommand(historyCmd)
+}
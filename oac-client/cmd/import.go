@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOut string
+	importID  string
+	importAll bool
+)
+
+// importCurlCmd converts a curl command into a batch file line
+var importCurlCmd = &cobra.Command{
+	Use:   "import-curl <curl command>",
+	Short: "Convert a curl command into a batch request",
+	Long: `Convert a curl command, e.g. one copied with "Copy as cURL" from the
+browser's developer tools, into a JSON line for batch --file.
+
+The method, path and body are kept. Headers, cookies and the host are dropped:
+the request is sent to the profile's instance with its own authentication.
+With --out the line is appended to a batch file, building it up one
+request at a time.`,
+	Example: `  oac-client import-curl "curl -X POST 'https://oac.example.com/api/20210901/catalog/folders' -H 'Authorization: Bearer ...' --data-raw '{\"id\":\"L3NoYXJlZC9OZXc\"}'"
+  oac-client import-curl --id new-folder --out requests.jsonl "$(pbpaste)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := oac.ParseCurl(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse curl command: %w", err)
+		}
+		req.ID = importID
+		return writeImported([]oac.BatchRequest{req})
+	},
+}
+
+// importHARCmd converts the REST calls of a HAR file into batch file lines
+var importHARCmd = &cobra.Command{
+	Use:   "import-har <file.har>",
+	Short: "Convert the REST calls of a HAR file into batch requests",
+	Long: `Convert the requests of a HAR file, saved from the network tab of the
+browser's developer tools, into JSON lines for batch --file.
+
+Only calls to the REST API (paths under /api/) are kept unless --all is given.
+Requests are numbered in the order they were made; headers, cookies and the
+host are dropped as with import-curl.`,
+	Example: `  oac-client import-har session.har > requests.jsonl
+  oac-client import-har session.har --out requests.jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open HAR file: %w", err)
+		}
+		defer f.Close()
+
+		reqs, err := oac.ReadHAR(f, importAll)
+		if err != nil {
+			return err
+		}
+		if len(reqs) == 0 {
+			infof("No REST calls found in %s", args[0])
+			return nil
+		}
+		if err := writeImported(reqs); err != nil {
+			return err
+		}
+		if importOut != "" {
+			infof("Added %d requests to %s", len(reqs), importOut)
+		}
+		return nil
+	},
+}
+
+// writeImported writes requests as JSON lines to stdout, or appends them to --out
+func writeImported(reqs []oac.BatchRequest) error {
+	var out io.Writer = os.Stdout
+	if importOut != "" {
+		f, err := os.OpenFile(importOut, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open batch file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	for _, req := range reqs {
+		if err := enc.Encode(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{importCurlCmd, importHARCmd} {
+		c.Flags().StringVarP(&importOut, "out", "o", "", "append to this batch file instead of printing")
+		rootCmd.AddCommand(c)
+	}
+	importCurlCmd.Flags().StringVar(&importID, "id", "", "id of the request in the batch file")
+	importHARCmd.Flags().BoolVar(&importAll, "all", false, "keep every request, not only calls to the REST API")
+}
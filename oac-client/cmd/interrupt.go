@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// commandCtx is the context of the running command: it carries its span and is
+// cancelled on SIGINT or SIGTERM, aborting requests, polls and downloads in flight
+var commandCtx = context.Background()
+
+// interruptContext returns a context cancelled by the first SIGINT or SIGTERM; the
+// default handlers are restored then, so a second Ctrl-C ends the process at once
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listenAddress string
+	listenPort    int
+	listenPath    string
+	listenExec    string
+	listenOn      []string
+	listenSecret  string
+	listenNoAuth  bool
+	listenTimeout time.Duration
+)
+
+// maxEventSize bounds the notification bodies accepted by listen
+const maxEventSize = 1 << 20
+
+// listenCmd receives OCI Events notifications and runs actions for them
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Receive OAC/OCI Events notifications over HTTP and run actions",
+	Long: `Receive OAC/OCI Events notifications over HTTP and run actions.
+
+Point an OCI Notifications HTTPS subscription at this server. The subscription
+is confirmed automatically. For each event, --exec and every --on action whose
+event type pattern matches is run with the event JSON on stdin and
+OAC_EVENT_TYPE, OAC_EVENT_ID, OAC_EVENT_SOURCE, OAC_EVENT_TIME and
+OAC_EVENT_RESOURCE in the environment.
+
+Requests must carry ?token=<secret> in the subscription URL, the secret of
+--secret; --no-auth accepts requests from anyone who can reach the port.
+Subscriptions are only confirmed through https URLs on oraclecloud.com hosts.`,
+	Example: `  oac-client listen --port 8080 --secret "$LISTEN_TOKEN" --exec ./handler.sh
+  oac-client listen --on 'com.oraclecloud.analytics.*snapshot*=./backup-done.sh' \
+    --on 'com.oraclecloud.dataflow.*.failed=./page-oncall.sh' --secret "$LISTEN_TOKEN"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		actions, err := parseEventActions(listenExec, listenOn)
+		if err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			return fmt.Errorf("nothing to do: set --exec or --on")
+		}
+		if listenSecret == "" && !listenNoAuth {
+			return fmt.Errorf("set --secret, or pass --no-auth to run actions for anyone who can reach the port")
+		}
+		httpClient, err := oac.HTTPClient(transportConfig)
+		if err != nil {
+			return err
+		}
+
+		ctx := commandCtx
+
+		mux := http.NewServeMux()
+		mux.Handle(listenPath, &eventHandler{ctx: ctx, actions: actions, client: httpClient})
+		srv := &http.Server{
+			Addr:              net.JoinHostPort(listenAddress, strconv.Itoa(listenPort)),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.ListenAndServe() }()
+		slog.Info("listening for events", "addr", srv.Addr, "path", listenPath, "actions", len(actions))
+
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+		}
+
+		slog.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// eventAction runs command for events whose type matches pattern
+type eventAction struct {
+	pattern string
+	command string
+}
+
+// parseEventActions combines --exec (every event) and --on pattern=command
+func parseEventActions(execAll string, on []string) ([]eventAction, error) {
+	var actions []eventAction
+	if execAll != "" {
+		actions = append(actions, eventAction{pattern: "*", command: execAll})
+	}
+	for _, v := range on {
+		pattern, command, ok := strings.Cut(v, "=")
+		if !ok || pattern == "" || command == "" {
+			return nil, fmt.Errorf("invalid --on %q, expected <event type pattern>=<command>", v)
+		}
+		actions = append(actions, eventAction{pattern: pattern, command: command})
+	}
+	return actions, nil
+}
+
+// eventHandler accepts notifications and starts the matching actions
+type eventHandler struct {
+	ctx     context.Context
+	actions []eventAction
+	// client confirms subscriptions
+	client *http.Client
+}
+
+func (h *eventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if listenSecret != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(listenSecret)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	if confirmURL := r.Header.Get(oac.ConfirmationURLHeader); confirmURL != "" {
+		if err := oac.ConfirmSubscription(r.Context(), h.client, confirmURL); err != nil {
+			slog.Error("subscription confirmation failed", "error", err)
+			http.Error(w, "confirmation failed", http.StatusBadGateway)
+			return
+		}
+		slog.Info("subscription confirmed")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventSize))
+	if err != nil {
+		http.Error(w, "event too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	event, err := oac.ParseEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	slog.Info("event received", "type", event.EventType, "id", event.EventID, "resource", event.ResourceName())
+
+	// answer right away, Notifications retries deliveries that take too long
+	for _, a := range h.actions {
+		if event.Matches(a.pattern) {
+			go runEventAction(h.ctx, a, event)
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// runEventAction runs an action's command through the shell with the event on stdin
+func runEventAction(ctx context.Context, a eventAction, event *oac.Event) {
+	ctx, cancel := context.WithTimeout(ctx, listenTimeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "sh", "-c", a.command)
+	c.Stdin = strings.NewReader(string(event.Raw))
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"OAC_EVENT_TYPE="+event.EventType,
+		"OAC_EVENT_ID="+event.EventID,
+		"OAC_EVENT_SOURCE="+event.Source,
+		"OAC_EVENT_TIME="+event.EventTime.Format(time.RFC3339),
+		"OAC_EVENT_RESOURCE="+event.ResourceName(),
+	)
+
+	start := time.Now()
+	if err := c.Run(); err != nil {
+		slog.Error("action failed", "command", a.command, "event", event.EventID, "error", err)
+		return
+	}
+	slog.Info("action finished", "command", a.command, "event", event.EventID, "duration", time.Since(start).Round(time.Millisecond))
+}
+
+func init() {
+	flags := listenCmd.Flags()
+	flags.StringVar(&listenAddress, "address", "", "address to listen on, all interfaces when empty")
+	flags.IntVar(&listenPort, "port", 8080, "port to listen on")
+	flags.StringVar(&listenPath, "path", "/", "URL path receiving notifications")
+	flags.StringVar(&listenExec, "exec", "", "command run for every event")
+	flags.StringArrayVar(&listenOn, "on", nil, "pattern=command run for events whose type matches the glob (repeatable)")
+	flags.StringVar(&listenSecret, "secret", "", "require ?token=<secret> on incoming requests")
+	flags.BoolVar(&listenNoAuth, "no-auth", false, "accept requests without ?token, e.g. behind an authenticating proxy")
+	flags.DurationVar(&listenTimeout, "action-timeout", 10*time.Minute, "maximum run time of an action")
+
+	rootCmd.AddCommand(listenCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevel  string
+	logFormat string
+	logFile   string
+)
+
+// setupLogging installs the default slog logger from the --log-* flags
+func setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", logLevel)
+	}
+	// --quiet keeps only errors unless a level is asked for explicitly
+	if quiet && !rootCmd.PersistentFlags().Changed("log-level") {
+		level = slog.LevelError
+	}
+
+	var out io.Writer = os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(logFormat) {
+	case "text":
+		handler = slog.NewTextHandler(out, opts)
+	case "json":
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		return fmt.Errorf("invalid --log-format %q: use text or json", logFormat)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// initCommand configures logging and fills the environment from .env files and the config
+// file before any command runs
+func initCommand(cmd *cobra.Command, args []string) error {
+	if err := setupLogging(); err != nil {
+		return err
+	}
+	if err := setupOutput(); err != nil {
+		return err
+	}
+	// an invalid config file is reported by the commands that need it
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = nil
+	}
+	found, err := oac.LoadEnvLayers(oac.DotEnvFiles(configFile()), cfg)
+
+	// completion requests run on every <tab> and must stay quiet
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		slog.Warn("no .env file found in the current directory")
+	}
+	setupTracing(cmd)
+	return nil
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&logLevel, "log-level", "info", "log level: debug, info, warn or error")
+	flags.StringVar(&logFormat, "log-format", "text", "log format: text or json")
+	flags.StringVar(&logFile, "log-file", "", "append logs to this file instead of stderr")
+
+	rootCmd.PersistentPreRunE = initCommand
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	modelFormat   string
+	modelOut      string
+	modelPassword string
+	modelDeploy   bool
+	modelComment  string
+	modelTimeout  time.Duration
+)
+
+// modelCmd groups semantic model commands
+var modelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Export, import and deploy the semantic model (RPD/SMML)",
+}
+
+var modelExportCmd = &cobra.Command{
+	Use:     "export",
+	Short:   "Download the deployed semantic model",
+	Example: `  oac-client model export --format rpd --out model.rpd`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		f, err := os.Create(modelOut)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		progress := newTransfer("Downloading "+modelOut, 0)
+		err = client.ExportModel(modelFormat, modelPasswordValue(), progress.Writer(f))
+		progress.Done()
+		if err != nil {
+			os.Remove(modelOut)
+			return fmt.Errorf("failed to export model: %w", err)
+		}
+		infof("Saved %s", modelOut)
+		return nil
+	},
+}
+
+var modelImportCmd = &cobra.Command{
+	Use:   "import <model.rpd|model.zip>",
+	Short: "Upload an .rpd file or SMML zip, optionally deploying it",
+	Example: `  oac-client model import model.rpd
+  oac-client model import model.zip --deploy`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		wrID, err := client.ImportModel(args[0], modelPasswordValue())
+		if err != nil {
+			return fmt.Errorf("failed to upload model: %w", err)
+		}
+		if wrID != "" {
+			if _, err := waitWorkRequest(client, "Processing upload", wrID, 5*time.Second, modelTimeout); err != nil {
+				return err
+			}
+		}
+		infof("Uploaded %s", args[0])
+
+		if modelDeploy {
+			return deployModel(client)
+		}
+		return nil
+	},
+}
+
+var modelDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy the uploaded semantic model and wait for completion",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		return deployModel(client)
+	},
+}
+
+// deployModel triggers a deployment and polls it to completion
+func deployModel(client *oac.OacClient) error {
+	wrID, err := client.DeployModel(modelComment)
+	if err != nil {
+		return fmt.Errorf("failed to deploy model: %w", err)
+	}
+
+	infof("Deploying, work request %s", wrID)
+	wr, err := waitWorkRequest(client, "Deploying", wrID, 5*time.Second, modelTimeout)
+	if err != nil {
+		return err
+	}
+	infof("Deployment %s", wr.Status)
+	return nil
+}
+
+// modelPasswordValue returns --password or OAC_MODEL_PASSWORD
+func modelPasswordValue() string {
+	if modelPassword != "" {
+		return modelPassword
+	}
+	return os.Getenv("OAC_MODEL_PASSWORD")
+}
+
+func init() {
+	modelCmd.PersistentFlags().StringVar(&modelPassword, "password", "", "RPD password (env OAC_MODEL_PASSWORD)")
+	modelCmd.PersistentFlags().DurationVar(&modelTimeout, "timeout", 30*time.Minute, "maximum time to wait for upload or deployment")
+
+	modelExportCmd.Flags().StringVar(&modelFormat, "format", "rpd", "model format: rpd or smml")
+	modelExportCmd.Flags().StringVar(&modelOut, "out", "model.rpd", "file to write the model to")
+
+	modelImportCmd.Flags().BoolVar(&modelDeploy, "deploy", false, "deploy the model after uploading it")
+	for _, c := range []*cobra.Command{modelImportCmd, modelDeployCmd} {
+		c.Flags().StringVar(&modelComment, "comment", "Deployed by oac-client", "deployment comment")
+	}
+
+	modelCmd.AddCommand(modelExportCmd, modelImportCmd, modelDeployCmd)
+	rootCmd.AddCommand(modelCmd)
+}
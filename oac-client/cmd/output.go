@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"oac-client/core/oac"
+)
+
+var (
+	rawOutput    bool
+	noColor      bool
+	noBody       bool
+	selectFields []string
+
+	outputFormat   string
+	outputTemplate *oac.OutputTemplate
+)
+
+// setupOutput parses --output: json, the default, go-template=TEMPLATE or
+// go-template-file=FILE
+func setupOutput() error {
+	kind, arg, _ := strings.Cut(outputFormat, "=")
+	switch kind {
+	case "", "json":
+		return nil
+	case "go-template":
+		arg = unescapeTemplateText(arg)
+	case "go-template-file":
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return fmt.Errorf("failed to read --output template: %w", err)
+		}
+		arg = string(data)
+	default:
+		return fmt.Errorf("invalid --output %q: use json, go-template=TEMPLATE or go-template-file=FILE", outputFormat)
+	}
+	tmpl, err := oac.ParseOutputTemplate(arg)
+	if err != nil {
+		return err
+	}
+	outputTemplate = tmpl
+	return nil
+}
+
+// unescapeTemplateText turns \n and \t outside {{ }} actions into newlines and tabs,
+// which shells pass on literally
+func unescapeTemplateText(s string) string {
+	unescape := strings.NewReplacer(`\n`, "\n", `\t`, "\t")
+	var b strings.Builder
+	for s != "" {
+		i := strings.Index(s, "{{")
+		if i < 0 {
+			i = len(s)
+		}
+		b.WriteString(unescape.Replace(s[:i]))
+		s = s[i:]
+		j := strings.Index(s, "}}")
+		if j < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:j+2])
+		s = s[j+2:]
+	}
+	return b.String()
+}
+
+// useColor reports whether output to f should be colored: f must be a terminal, and
+// neither --no-color, --raw, NO_COLOR nor TERM=dumb may be set
+func useColor(f *os.File) bool {
+	if noColor || rawOutput || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// errorPrefix returns the "Error:" label, in red on a terminal
+func errorPrefix() string {
+	if useColor(os.Stderr) {
+		return "\x1b[31;1mError:\x1b[0m"
+	}
+	return "Error:"
+}
+
+// printBody writes a response body to stdout, pretty-printed (and highlighted on a
+// terminal) unless --raw asks for the exact bytes received
+func printBody(r io.Reader) error {
+	return printResponseBody(0, nil, r)
+}
+
+// printResponse prints a response held in memory with its status and headers
+func printResponse(resp *oac.Response) error {
+	return printResponseBody(resp.StatusCode, resp.Header, bytes.NewReader(resp.Bytes()))
+}
+
+// printResponseBody is printBody with the status and headers of the response, which
+// --output go-template can show
+func printResponseBody(status int, header http.Header, r io.Reader) error {
+	if noBody {
+		_, err := io.Copy(io.Discard, r)
+		return err
+	}
+	if quiet {
+		// no "request succeeded" confirmation for empty bodies
+		br := bufio.NewReader(r)
+		if _, err := br.Peek(1); err == io.EOF {
+			return nil
+		}
+		r = br
+	}
+	if len(selectFields) > 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		// bodies that are not JSON are printed as they are
+		if selected, err := oac.SelectFields(data, selectFields); err == nil {
+			data = selected
+		}
+		r = bytes.NewReader(data)
+	}
+	if outputTemplate != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return outputTemplate.Execute(os.Stdout, data, status, header)
+	}
+	if rawOutput {
+		_, err := io.Copy(os.Stdout, r)
+		return err
+	}
+	if useColor(os.Stdout) {
+		return oac.PrettyPrintColor(os.Stdout, r)
+	}
+	return oac.PrettyPrint(os.Stdout, r)
+}
+
+// infof prints an informational message such as "Saved <file>" on stdout, unless --quiet
+func infof(format string, args ...any) {
+	if !quiet {
+		fmt.Printf(format+"\n", args...)
+	}
+}
+
+// printJSON prints a response body held in memory
+func printJSON(data []byte) error {
+	return printBody(bytes.NewReader(data))
+}
+
+// printSpooled prints a response that was too large to hold in memory: streamed from its
+// temporary file when stdout is redirected, named on a terminal
+func printSpooled(tooLarge *oac.ResponseTooLargeError) error {
+	if isTerminal(os.Stdout) {
+		fmt.Fprintf(os.Stderr, "Response of %s is too large to display and was saved to %s\n",
+			oac.FormatSize(tooLarge.Size), tooLarge.File)
+		return nil
+	}
+
+	defer os.Remove(tooLarge.File)
+	f, err := os.Open(tooLarge.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return printBody(f)
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&rawOutput, "raw", false, "print response bodies exactly as received, without formatting or messages")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also NO_COLOR)")
+	rootCmd.PersistentFlags().StringSliceVar(&selectFields, "fields", nil, "print only these fields of response objects, e.g. name,id,owner.name")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "", "print responses as json (default), go-template=TEMPLATE or go-template-file=FILE")
+	rootCmd.PersistentFlags().BoolVar(&noBody, "no-body", false, "do not print response bodies; the exit code tells the outcome")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"oac-client/core/oac"
+)
+
+var (
+	pageAll bool
+	ndjson  bool
+)
+
+// pagedCall follows the oa-next-page header of a GET with --all, printing the items of
+// every page as one {"items": [...]} document, or with --ndjson one compact item per
+// line as each page arrives
+func pagedCall(method, path string) error {
+	if method != "GET" {
+		return fmt.Errorf("--all only pages through GET requests")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+	header, err := parseHeaders(headerFlags)
+	if err != nil {
+		return err
+	}
+	query, err := parseQuery(queryFlags)
+	if err != nil {
+		return err
+	}
+	pages := oac.PaginateRequest[json.RawMessage](commandCtx, client, oac.Request{Path: path, Query: query, Header: header})
+
+	if !ndjson {
+		items := []json.RawMessage{}
+		for item := range pages.All() {
+			items = append(items, item)
+		}
+		if err := pages.Err(); err != nil {
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+		data, err := json.Marshal(map[string]any{"items": items})
+		if err != nil {
+			return err
+		}
+		return printJSON(data)
+	}
+
+	var line bytes.Buffer
+	for item := range pages.All() {
+		if len(selectFields) > 0 {
+			if selected, err := oac.SelectFields(item, selectFields); err == nil {
+				item = selected
+			}
+		}
+		line.Reset()
+		if err := json.Compact(&line, item); err != nil {
+			return err
+		}
+		line.WriteByte('\n')
+		if _, err := os.Stdout.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := pages.Err(); err != nil {
+		return fmt.Errorf("error executing REST call: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.BoolVar(&pageAll, "all", false, "follow every page of a list GET and print all items")
+	flags.BoolVar(&ndjson, "ndjson", false, "with --all, print one compact JSON item per line as pages arrive")
+}
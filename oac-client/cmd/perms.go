@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	permsRecursive bool
+	permsFile      string
+	permsDryRun    bool
+)
+
+// permsCmd groups the catalog permission commands
+var permsCmd = &cobra.Command{
+	Use:   "perms",
+	Short: "Read and apply catalog permissions",
+}
+
+// permsGetCmd prints ACLs in the format perms set reads
+var permsGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Print the permissions of a catalog item or folder as an ACL file",
+	Example: `  oac-client perms get /shared/Finance
+  oac-client perms get /shared/Finance --recursive > acl.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		root, err := client.LookupItem(args[0])
+		if err != nil {
+			return err
+		}
+		items := []oac.CatalogItem{root}
+		if permsRecursive && root.IsFolder() {
+			err := client.WalkCatalog(root.FullPath(), func(item oac.CatalogItem, depth int) error {
+				items = append(items, item)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		acls := oac.ACLFile{}
+		for _, item := range items {
+			entries, err := client.GetACL(item)
+			if err != nil {
+				return err
+			}
+			acls[item.FullPath()] = entries
+		}
+
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(acls)
+	},
+}
+
+// permsSetCmd applies an ACL file
+var permsSetCmd = &cobra.Command{
+	Use:   "set --file acl.yaml",
+	Short: "Apply the permissions of an ACL file to the catalog",
+	Long: `Apply the permissions of an ACL file to the catalog.
+
+The file maps catalog paths to the complete ACL each should have:
+
+  /shared/Finance:
+    - {principal: FinanceAuthors, type: role, permission: full}
+    - {principal: BIConsumer, type: role, permission: read}
+  /shared/Finance/Payroll:
+    - {principal: HRAuthors, type: role, permission: full}
+
+Principals missing from a listed ACL are removed. With --recursive the ACL of a
+folder also applies to everything below it, unless a deeper path of the file
+lists its own. Only items whose ACL differs are updated; --dry-run prints the
+changes without applying them.`,
+	Example: `  oac-client perms set --file acl.yaml --recursive --dry-run
+  oac-client perms set --file acl.yaml --recursive`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		acls, err := oac.LoadACLFile(permsFile)
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		plans, err := client.PlanPermissions(acls, permsRecursive)
+		if err != nil {
+			return err
+		}
+		for _, plan := range plans {
+			fmt.Println(plan.Item.FullPath())
+			for _, ch := range plan.Changes {
+				fmt.Println("  " + ch.String())
+			}
+		}
+		if len(plans) == 0 {
+			infof("Permissions are up to date")
+			return nil
+		}
+		if permsDryRun {
+			infof("%d items would change", len(plans))
+			return nil
+		}
+
+		if err := client.ApplyPermissions(plans); err != nil {
+			return err
+		}
+		infof("Updated the permissions of %d items", len(plans))
+		return nil
+	},
+}
+
+func init() {
+	permsGetCmd.Flags().BoolVarP(&permsRecursive, "recursive", "r", false, "include everything below a folder")
+	permsSetCmd.Flags().BoolVarP(&permsRecursive, "recursive", "r", false, "apply folder ACLs to everything below them")
+	permsSetCmd.Flags().StringVarP(&permsFile, "file", "f", "", "YAML file mapping catalog paths to ACL entries")
+	permsSetCmd.Flags().BoolVar(&permsDryRun, "dry-run", false, "show the changes without applying them")
+	_ = permsSetCmd.MarkFlagRequired("file")
+
+	permsCmd.AddCommand(permsGetCmd, permsSetCmd)
+	rootCmd.AddCommand(permsCmd)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"oac-client/core/oac"
+)
+
+var quiet bool
+
+// progressInterval limits how often progress lines are redrawn
+const progressInterval = 200 * time.Millisecond
+
+// showProgress reports whether progress bars and spinners should be drawn on stderr
+func showProgress() bool {
+	return !quiet && isTerminal(os.Stderr)
+}
+
+// transfer draws a byte count, rate and ETA for a stream on stderr
+type transfer struct {
+	label string
+	total int64
+	n     int64
+	start time.Time
+	drawn time.Time
+	on    bool
+}
+
+// newTransfer starts progress for a stream of total bytes (0 or less when unknown)
+func newTransfer(label string, total int64) *transfer {
+	return &transfer{label: label, total: total, start: time.Now(), on: showProgress()}
+}
+
+// Reader counts the bytes read from r
+func (t *transfer) Reader(r io.Reader) io.Reader {
+	return &transferReader{r: r, t: t}
+}
+
+// Writer counts the bytes written to w
+func (t *transfer) Writer(w io.Writer) io.Writer {
+	return &transferWriter{w: w, t: t}
+}
+
+func (t *transfer) add(n int) {
+	t.n += int64(n)
+	if t.on && time.Since(t.drawn) >= progressInterval {
+		t.draw()
+	}
+}
+
+func (t *transfer) draw() {
+	t.drawn = time.Now()
+	elapsed := time.Since(t.start)
+	rate := float64(t.n) / max(elapsed.Seconds(), 0.001)
+
+	line := fmt.Sprintf("%s %s", t.label, byteSize(t.n))
+	if t.total > 0 {
+		line += fmt.Sprintf(" / %s %3.0f%%", byteSize(t.total), float64(t.n)*100/float64(t.total))
+	}
+	line += fmt.Sprintf("  %s/s", byteSize(int64(rate)))
+	if t.total > 0 && rate > 0 && t.n < t.total {
+		eta := time.Duration(float64(t.total-t.n) / rate * float64(time.Second))
+		line += "  ETA " + eta.Round(time.Second).String()
+	}
+	fmt.Fprintf(os.Stderr, "\r\x1b[K%s", line)
+}
+
+// Done draws the final state and ends the progress line
+func (t *transfer) Done() {
+	if t.on {
+		t.draw()
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+type transferReader struct {
+	r io.Reader
+	t *transfer
+}
+
+func (tr *transferReader) Read(p []byte) (int, error) {
+	n, err := tr.r.Read(p)
+	tr.t.add(n)
+	return n, err
+}
+
+type transferWriter struct {
+	w io.Writer
+	t *transfer
+}
+
+func (tw *transferWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	tw.t.add(n)
+	return n, err
+}
+
+// spinner animates on stderr while a work request is polled
+type spinner struct {
+	label string
+	start time.Time
+
+	mu     sync.Mutex
+	status string
+	stop   chan struct{}
+	done   sync.WaitGroup
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// startSpinner starts a spinner, or returns an inactive one when progress is hidden
+func startSpinner(label string) *spinner {
+	s := &spinner{label: label, start: time.Now()}
+	if !showProgress() {
+		return s
+	}
+
+	s.stop = make(chan struct{})
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			s.mu.Lock()
+			fmt.Fprintf(os.Stderr, "\r\x1b[K%s %s %s (%s)", spinnerFrames[i%len(spinnerFrames)], s.label, s.status,
+				time.Since(s.start).Round(time.Second))
+			s.mu.Unlock()
+			select {
+			case <-s.stop:
+				fmt.Fprint(os.Stderr, "\r\x1b[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Update records the latest state of the polled work request
+func (s *spinner) Update(wr *oac.WorkRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = wr.Status
+	if wr.PercentComplete > 0 {
+		s.status += fmt.Sprintf(" %.0f%%", wr.PercentComplete)
+	}
+}
+
+// Stop clears the spinner line
+func (s *spinner) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		s.done.Wait()
+	}
+}
+
+// waitWorkRequest polls a work request with a spinner on stderr
+func waitWorkRequest(client *oac.OacClient, label, id string, interval, timeout time.Duration) (*oac.WorkRequest, error) {
+	s := startSpinner(label)
+	defer s.Stop()
+	return client.WatchWorkRequest(id, interval, timeout, s.Update)
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "script mode: no progress, warnings or informational messages, only response bodies and errors")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "silent", "s", false, "same as --quiet")
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	promoteFrom    string
+	promoteTo      string
+	promoteObjects string
+	promoteMapping string
+	promoteDryRun  bool
+)
+
+// promoteCmd copies content between two profiles
+var promoteCmd = &cobra.Command{
+	Use:   "promote --from <profile> --to <profile> --objects objects.yaml",
+	Short: "Promote workbooks, datasets and connections between environments",
+	Long: `Promote workbooks, datasets and connections between environments.
+
+The objects file lists what to copy, keyed by catalog type:
+
+  connections:
+    - "'admin'.'ADW Sales'"
+  datasets:
+    - /shared/Finance/Sales
+  workbooks:
+    - /shared/Finance/Revenue
+
+Connections are copied first, then datasets, then workbooks. The optional
+mapping file rewrites connection references inside exported content:
+
+  connections:
+    ADW_SALES_DEV: ADW_SALES_PROD
+
+Datasets and workbooks already in the target are backed up before they are
+replaced; when the promotion fails halfway, oac-client rollback --to <manifest>
+restores the items it touched.
+
+Examples:
+  oac-client promote --from dev --to prod --objects objects.yaml --mapping prod-map.yaml
+  oac-client promote --from dev --to prod --objects objects.yaml --dry-run
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if promoteFrom == promoteTo {
+			return fmt.Errorf("--from and --to must be different profiles")
+		}
+
+		objs, err := oac.LoadPromoteObjects(promoteObjects)
+		if err != nil {
+			return err
+		}
+
+		var mapping *oac.PromoteMapping
+		if promoteMapping != "" {
+			if mapping, err = oac.LoadPromoteMapping(promoteMapping); err != nil {
+				return err
+			}
+		}
+
+		if !promoteDryRun {
+			enableBackup()
+		}
+		src, err := newClientForProfile(promoteFrom)
+		if err != nil {
+			return fmt.Errorf("failed to create client for %s: %w", promoteFrom, err)
+		}
+		dst, err := newClientForProfile(promoteTo)
+		if err != nil {
+			return fmt.Errorf("failed to create client for %s: %w", promoteTo, err)
+		}
+
+		failed := 0
+		oac.Promote(src, dst, objs, mapping, promoteDryRun, func(res oac.PromoteResult) {
+			switch {
+			case promoteDryRun:
+				fmt.Printf("would promote %-12s %s\n", res.Type, res.Path)
+			case res.Err != nil:
+				failed++
+				fmt.Printf("FAILED   %-12s %s: %v\n", res.Type, res.Path, res.Err)
+			default:
+				fmt.Printf("promoted %-12s %s\n", res.Type, res.Path)
+			}
+		})
+
+		reportBackup(dst, failed > 0)
+		if failed > 0 {
+			return fmt.Errorf("%d objects failed to promote", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	promoteCmd.Flags().StringVar(&promoteFrom, "from", "", "source profile")
+	promoteCmd.Flags().StringVar(&promoteTo, "to", "", "target profile")
+	promoteCmd.Flags().StringVar(&promoteObjects, "objects", "", "YAML file listing the objects to promote")
+	promoteCmd.Flags().StringVar(&promoteMapping, "mapping", "", "YAML file mapping source connection references to target ones")
+	promoteCmd.Flags().BoolVar(&promoteDryRun, "dry-run", false, "only list what would be promoted")
+	addBackupFlags(promoteCmd)
+	for _, f := range []string{"from", "to", "objects"} {
+		_ = promoteCmd.MarkFlagRequired(f)
+	}
+
+	rootCmd.AddCommand(promoteCmd)
+}
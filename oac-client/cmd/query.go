@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryFile     string
+	queryFormat   string
+	queryPageSize int
+	queryMaxRows  int
+	queryNoHeader bool
+)
+
+// queryCmd runs logical SQL and streams the result
+var queryCmd = &cobra.Command{
+	Use:   "query [logical SQL]",
+	Short: "Run a logical SQL query and stream the result as CSV or JSON",
+	Long: `Run a logical SQL query against the semantic model and stream the result as
+CSV or JSON. Large results are fetched page by page (--page-size rows per
+request) and written as they arrive.
+
+The statement is the argument, or read from --file (- for stdin).`,
+	Example: `  oac-client query 'SELECT "Time"."Year", "Base Facts"."Revenue" FROM "Sample Sales"' --format csv
+  oac-client query -f revenue.sql --format json --max-rows 100`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sql, err := querySQL(args)
+		if err != nil {
+			return err
+		}
+		w, err := newQueryWriter(queryFormat, !queryNoHeader)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		if err := client.Query(sql, oac.QueryOptions{PageSize: queryPageSize, MaxRows: queryMaxRows}, w.Page); err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		return w.Close()
+	},
+}
+
+// querySQL returns the statement from the argument or --file
+func querySQL(args []string) (string, error) {
+	switch {
+	case len(args) > 0 && queryFile != "":
+		return "", fmt.Errorf("use either the query argument or --file, not both")
+	case len(args) > 0:
+		return args[0], nil
+	case queryFile == "-":
+		b, err := io.ReadAll(os.Stdin)
+		return strings.TrimSpace(string(b)), err
+	case queryFile != "":
+		b, err := os.ReadFile(queryFile)
+		return strings.TrimSpace(string(b)), err
+	}
+	return "", fmt.Errorf("a query is required, as argument or with --file")
+}
+
+// queryWriter writes result pages as they arrive
+type queryWriter interface {
+	Page(*oac.QueryPage) error
+	Close() error
+}
+
+// newQueryWriter returns the writer of a --format printing to stdout
+func newQueryWriter(format string, header bool) (queryWriter, error) {
+	switch format {
+	case "csv":
+		return &csvQueryWriter{w: csv.NewWriter(os.Stdout), header: header}, nil
+	case "json":
+		return &jsonQueryWriter{w: bufio.NewWriter(os.Stdout)}, nil
+	}
+	return nil, fmt.Errorf("unsupported --format %q, expected csv or json", format)
+}
+
+// csvQueryWriter writes a header row from the first page's columns, then every row
+type csvQueryWriter struct {
+	w       *csv.Writer
+	header  bool
+	started bool
+}
+
+func (q *csvQueryWriter) Page(p *oac.QueryPage) error {
+	if !q.started && q.header && len(p.Columns) > 0 {
+		names := make([]string, len(p.Columns))
+		for i, c := range p.Columns {
+			names[i] = c.Name
+		}
+		if err := q.w.Write(names); err != nil {
+			return err
+		}
+	}
+	q.started = true
+
+	record := []string{}
+	for _, row := range p.Rows {
+		record = record[:0]
+		for _, v := range row {
+			record = append(record, csvValue(v))
+		}
+		if err := q.w.Write(record); err != nil {
+			return err
+		}
+	}
+	q.w.Flush()
+	return q.w.Error()
+}
+
+func (q *csvQueryWriter) Close() error {
+	q.w.Flush()
+	return q.w.Error()
+}
+
+// csvValue formats a JSON value for a CSV cell
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// jsonQueryWriter writes a JSON array of objects keyed by column name, in column order
+type jsonQueryWriter struct {
+	w       *bufio.Writer
+	columns []string
+	rows    int
+}
+
+func (q *jsonQueryWriter) Page(p *oac.QueryPage) error {
+	if q.columns == nil && len(p.Columns) > 0 {
+		for _, c := range p.Columns {
+			name, _ := json.Marshal(c.Name)
+			q.columns = append(q.columns, string(name))
+		}
+	}
+
+	for _, row := range p.Rows {
+		if q.rows == 0 {
+			q.w.WriteString("[\n  {")
+		} else {
+			q.w.WriteString(",\n  {")
+		}
+		q.rows++
+		for i, v := range row {
+			if i > 0 {
+				q.w.WriteString(", ")
+			}
+			if i < len(q.columns) {
+				q.w.WriteString(q.columns[i])
+			} else {
+				fmt.Fprintf(q.w, `"column%d"`, i+1)
+			}
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			q.w.WriteString(": ")
+			q.w.Write(value)
+		}
+		q.w.WriteString("}")
+	}
+	return q.w.Flush()
+}
+
+func (q *jsonQueryWriter) Close() error {
+	if q.rows == 0 {
+		q.w.WriteString("[]\n")
+	} else {
+		q.w.WriteString("\n]\n")
+	}
+	return q.w.Flush()
+}
+
+func init() {
+	flags := queryCmd.Flags()
+	flags.StringVarP(&queryFile, "file", "f", "", "read the query from a file, - for stdin")
+	flags.StringVar(&queryFormat, "format", "csv", "output format: csv or json")
+	flags.IntVar(&queryPageSize, "page-size", 1000, "rows fetched per request")
+	flags.IntVar(&queryMaxRows, "max-rows", 0, "stop after this many rows (0 for all)")
+	flags.BoolVar(&queryNoHeader, "no-header", false, "omit the CSV header row")
+
+	rootCmd.AddCommand(queryCmd)
+}
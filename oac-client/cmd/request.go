@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// parseHeaders converts repeated "Name: value" flags into a header set
+func parseHeaders(values []string) (http.Header, error) {
+	header := http.Header{}
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", v)
+		}
+		header.Add(name, strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// parseQuery converts repeated key=value flags into query parameters
+func parseQuery(values []string) (url.Values, error) {
+	query := url.Values{}
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid query parameter %q, expected key=value", v)
+		}
+		query.Add(key, value)
+	}
+	return query, nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// backupDir makes the clients created next back up catalog items before importing
+	// them, see enableBackup
+	backupDir string
+	noBackup  bool
+
+	rollbackTo     string
+	rollbackDryRun bool
+	rollbackForce  bool
+)
+
+// rollbackCmd restores the items touched by an import or promotion
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback --to <manifest>",
+	Short: "Restore the catalog items touched by a failed import or promotion",
+	Long: `Restore the catalog items touched by catalog import or promote from the backup
+they took before changing anything. Only the items listed in the manifest are
+restored, last changed first: previous versions are imported again with their
+permissions, and items that did not exist before are deleted.
+
+The manifest is printed by the import or promotion; backups are kept in
+~/.local/state/oac-client/backups unless --backup-dir was given.`,
+	Example: `  oac-client --profile prod rollback --to ~/.local/state/oac-client/backups/20260301-101500/manifest.json --dry-run
+  oac-client --profile prod rollback --to ~/.local/state/oac-client/backups/20260301-101500/manifest.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifest, err := oac.LoadExportManifest(rollbackTo)
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if manifest.Instance != "" && manifest.Instance != client.Instance() && !rollbackForce {
+			return fmt.Errorf("%s was taken on %s, not %s; pass --force to restore it anyway", rollbackTo, manifest.Instance, client.Instance())
+		}
+
+		failed := 0
+		client.Rollback(manifest, rollbackDryRun, func(res oac.RollbackResult) {
+			if res.Err != nil {
+				failed++
+				fmt.Printf("FAILED   %s: %v\n", res.Item.Path, res.Err)
+				return
+			}
+			action := "restore"
+			if res.Deleted {
+				action = "delete"
+			}
+			if rollbackDryRun {
+				fmt.Printf("would %-7s %s\n", action, res.Item.Path)
+				return
+			}
+			fmt.Printf("%-8s %s\n", action+"d", res.Item.Path)
+		})
+		if failed > 0 {
+			return fmt.Errorf("%d items failed to roll back", failed)
+		}
+		return nil
+	},
+}
+
+// addBackupFlags adds the backup flags to a command importing catalog items
+func addBackupFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&backupDir, "backup-dir", "", "directory to back up changed items to (default a new directory in ~/.local/state/oac-client/backups)")
+	cmd.Flags().BoolVar(&noBackup, "no-backup", false, "do not back up items before changing them")
+}
+
+// enableBackup makes the clients created next back up catalog items before importing
+// them, unless --no-backup was given
+func enableBackup() {
+	if noBackup {
+		backupDir = ""
+		return
+	}
+	if backupDir == "" {
+		backupDir = filepath.Join(oac.StateDir(), "backups", time.Now().Format("20060102-150405"))
+	}
+}
+
+// reportBackup tells how to undo the changes of client, when it backed anything up
+func reportBackup(client *oac.OacClient, failed bool) {
+	manifest := client.BackupManifest()
+	if manifest == "" {
+		return
+	}
+	if failed {
+		profile := ""
+		if name := client.ProfileName(); name != "" {
+			profile = " --profile " + name
+		}
+		infof("Undo the changes with: oac-client%s rollback --to %s", profile, manifest)
+		return
+	}
+	infof("Previous versions saved, see %s", manifest)
+}
+
+func init() {
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "backup manifest written by an import or promotion")
+	rollbackCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false, "only list what would be restored")
+	rollbackCmd.Flags().BoolVar(&rollbackForce, "force", false, "restore a backup taken on another instance")
+	_ = rollbackCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(rollbackCmd)
+}
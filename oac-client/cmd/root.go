@@ -1,18 +1,70 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"oac-client/core/oac"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	transportConfig  oac.TransportConfig
+	rateLimit        string
+	circuitBreaker   string
+	compressRequests bool
+	cacheTTL         time.Duration
+	recordFile       string
+	replayFile       string
+	profileName      string
+	instanceURL      string
+	actAsUser        string
+	scopeOverride    []string
+	noRefresh        bool
+	saveDir          string
+	maxResponseSize  string
+	apiVersion       string
+	fanOutInstances  []string
+	allProfiles      bool
+	configPath       string
+	requestHooks     []string
+	printCurl        bool
+	requestPlugins   []string
+
+	bucketName      string
+	bucketNamespace string
+	outputObject    string
+	bodyObject      string
+
+	templateVars []string
+	varFile      string
+
+	dataLiteral string
+	dataFile    string
+	dataStdin   bool
+
+	headerFlags    []string
+	queryFlags     []string
+	includeHeaders bool
+	etagFlag       string
+	ifNoneMatch    bool
+
+	uploadChunkSize string
+	restartUpload   bool
+	verifyBody      bool
+)
+
 // rootCmd is the main CLI command
 var rootCmd = &cobra.Command{
-	Use:   "oac <method> <path> [bodyFile]",
+	Use:   "oac-client <method> <path> [bodyFile]",
 	Short: "OAC REST API client utility",
 	Long: `OAC REST API client utility.
 
@@ -25,51 +77,512 @@ Examples:
 
   # Update an existing report
   oac-client PUT /reports/123 update.json
+  oac-client PATCH /reports/123 patch.json
+
+  # Show the status line and main headers before the body
+  oac-client GET /reports/123 -i
+
+  # Show status and headers only
+  oac-client HEAD /reports/123
+  oac-client OPTIONS /reports
+
+  # Custom headers and query parameters
+  oac-client GET /api/20210901/catalog -q search='*' -q type=workbooks -H "Accept: application/json"
+
+  # Read the payload from stdin
+  cat payload.json | oac-client POST /reports -
+
+  # Be explicit about where the payload comes from
+  oac-client POST /reports --data '{"name": "Revenue"}'
+  oac-client POST /reports --data-file payload.json
+  cat payload.json | oac-client POST /reports --data-stdin
+
+  # Render a Go-template payload with variables
+  oac-client POST /api/20210901/snapshots snapshot.json --var name=nightly --var-file prod.yaml
+
+  # Run the same read-only request against several instances
+  oac-client GET /api/20210901/snapshots --instances dev,test,prod
+  oac-client GET /api/20210901/snapshots --all-profiles
+
+  # Stream a response into OCI Object Storage, or a request body out of it
+  oac-client GET /api/20210901/snapshots/123/actions/download --bucket backups --object snap.bar
+  oac-client POST /api/20210901/catalog/workbooks/abc/actions/import --bucket exports --body-object wb.dva
+
+  # Upload a large file in chunks; re-running after a failure resumes it
+  oac-client POST /api/20210901/snapshots/actions/upload --data-file backup.bar --chunk-size 16MiB
 
 Notes:
-  - The bodyFile argument is mandatory for POST and PUT requests.
+  - Supported methods: GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS.
+  - A body is mandatory for POST, PUT and PATCH, and optional for the others.
+  - The positional bodyFile is read as a file if it exists and used literally
+    otherwise; prefer --data, --data-file or --data-stdin to avoid ambiguity.
+  - Use - as bodyFile to read the payload from standard input.
 	`,
-	Args: cobra.MinimumNArgs(2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MinimumNArgs(2)(cmd, args); err != nil {
+			return err
+		}
+		if !supportedMethods[strings.ToUpper(args[0])] {
+			return fmt.Errorf("unsupported HTTP method: %s", args[0])
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		method := strings.ToUpper(args[0])
 		path := args[1]
 
-		var body string
-		if requiresBody(method) {
-			if len(args) < 3 {
-				return fmt.Errorf("%s requires a body file", method)
-			}
-			body = args[2]
+		if len(fanOutInstances) > 0 || allProfiles {
+			// paths are expanded with the variables of each target
+			return fanOutCall(method, path)
+		}
+		path, err := expandPath(profileName, path)
+		if err != nil {
+			return err
+		}
+
+		if outputObject != "" || bodyObject != "" {
+			return bucketCall(method, path)
+		}
+
+		if uploadChunkSize != "" {
+			return chunkedCall(method, path, args[2:])
+		}
+
+		if watchInterval > 0 || watchUntil != "" {
+			return watchCall(method, path)
+		}
+
+		if ndjson && !pageAll {
+			return fmt.Errorf("--ndjson needs --all")
+		}
+		if pageAll {
+			return pagedCall(method, path)
+		}
+
+		if err := verifyBodyFile(args[2:]); err != nil {
+			return err
+		}
+		bodyBytes, err := loadBody(method, args[2:])
+		if err != nil {
+			return err
+		}
+		if err := checkRequestBody(nil, method, path, bodyBytes); err != nil {
+			return err
 		}
 
-		client, err := oac.NewOacClient()
+		client, err := newClient()
 		if err != nil {
 			return fmt.Errorf("failed to create OAC client: %w", err)
 		}
 
-		resp, err := client.RestCall(method, path, body)
+		header, err := parseHeaders(headerFlags)
+		if err != nil {
+			return err
+		}
+		query, err := parseQuery(queryFlags)
+		if err != nil {
+			return err
+		}
+
+		req := &oac.Request{
+			Method: method,
+			Path:   path,
+			Query:  query,
+			Header: header,
+			Body:   bytes.NewReader(bodyBytes),
+		}
+		switch {
+		case etagFlag == "none":
+			req.NoETag = true
+		case etagFlag != "":
+			header.Set("If-Match", etagFlag)
+		}
+		if ifNoneMatch && method == "GET" {
+			if etag := client.CachedETag(path); etag != "" {
+				header.Set("If-None-Match", etag)
+			}
+		}
+
+		resp, err := client.Open(req)
 		if err != nil {
 			return fmt.Errorf("error executing REST call: %w", err)
 		}
+		defer resp.Body.Close()
+
+		statusLine := resp.Proto + " " + resp.Status
+		// templates render the status and headers of bodiless responses themselves
+		if (method == "HEAD" || method == "OPTIONS" || resp.StatusCode == http.StatusNotModified) && outputTemplate == nil {
+			fmt.Println(statusLine)
+			printHeaders(resp.Header, nil)
+			return nil
+		}
+
+		if includeHeaders {
+			fmt.Println(statusLine)
+			printHeaders(resp.Header, includedHeaders)
+			fmt.Println()
+		}
 
-		fmt.Println(resp)
+		// stream the body so large responses are never held in memory
+		if err := printResponseBody(resp.StatusCode, resp.Header, resp.Body); err != nil {
+			return fmt.Errorf("failed to print response: %w", err)
+		}
 		return nil
 	},
 }
 
-// requiresBody returns true if the HTTP method requires a body
-func requiresBody(method string) bool {
-	return method == "POST" || method == "PUT"
+// supportedMethods lists the HTTP methods accepted on the command line
+var supportedMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "HEAD": true, "OPTIONS": true,
+}
+
+// includedHeaders are the response headers shown by --include
+var includedHeaders = []string{
+	"Content-Type", "Content-Length", "Date", "Etag", "Last-Modified", "Location",
+	"Retry-After", "Opc-Request-Id", "Oa-Work-Request-Id",
+}
+
+// printHeaders writes response headers sorted by name, limited to only if not nil
+func printHeaders(header http.Header, only []string) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if only == nil || slices.Contains(only, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, v := range header[name] {
+			fmt.Printf("%s: %s\n", name, v)
+		}
+	}
+}
+
+// newClient creates an OAC client from the global flags
+func newClient() (*oac.OacClient, error) {
+	return newClientFor(profileName, instanceURL)
+}
+
+// configFile returns the config file selected by --config
+func configFile() string {
+	if configPath != "" {
+		return configPath
+	}
+	return oac.DefaultConfigPath()
+}
+
+// loadConfig reads the config file selected by --config
+func loadConfig() (*oac.Config, error) {
+	return oac.LoadConfig(configFile())
+}
+
+// newClientForProfile creates an OAC client for a named profile ("" for the default)
+func newClientForProfile(name string) (*oac.OacClient, error) {
+	return newClientFor(name, "")
+}
+
+// loadProfile returns a named profile of the config file, OAC_PROFILE or the default
+// one when name is empty, and nil when none is configured
+func loadProfile(name string) (*oac.Profile, error) {
+	if name == "" {
+		name = os.Getenv("OAC_PROFILE")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Profile(name)
+}
+
+// newClientFor creates an OAC client for a named profile, sending requests to instance
+// instead of the profile's OAC_INSTANCE when not empty; extra options are applied last
+func newClientFor(name, instance string, extra ...oac.Option) (*oac.OacClient, error) {
+	profile, err := loadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []oac.Option{
+		oac.WithTransport(transportConfig), oac.WithETagCache(""),
+		oac.WithConfirmation(confirmOnTerminal), oac.WithContext(commandCtx),
+	}
+	if file := oac.DefaultHistoryPath(); file != "" {
+		opts = append(opts, oac.WithHistory(file))
+	}
+	if profile != nil {
+		opts = append(opts, oac.WithProfile(profile))
+	}
+	if instance != "" {
+		opts = append(opts, oac.WithInstance(instance))
+	}
+	if actAsUser != "" {
+		opts = append(opts, oac.WithActAs(actAsUser))
+	}
+	if len(scopeOverride) > 0 {
+		opts = append(opts, oac.WithScopes(scopeOverride))
+	}
+	if noRefresh {
+		opts = append(opts, oac.WithCachedTokenOnly())
+	}
+
+	switch {
+	case recordFile != "":
+		opts = append(opts, oac.WithRecorder(recordFile))
+	case replayFile != "":
+		opts = append(opts, oac.WithReplay(replayFile))
+	}
+
+	if showStats {
+		opts = append(opts, oac.WithStats(collectStats))
+	}
+
+	if cacheTTL > 0 {
+		opts = append(opts, oac.WithResponseCache(cacheTTL))
+	}
+
+	if backupDir != "" {
+		opts = append(opts, oac.WithBackup(backupDir))
+	}
+
+	if saveDir != "" {
+		opts = append(opts, oac.WithResponseArchive(saveDir))
+	}
+
+	if compressRequests {
+		opts = append(opts, oac.WithRequestCompression(0))
+	}
+
+	if rateLimit != "" {
+		perSecond, err := oac.ParseRate(rateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --rate: %w", err)
+		}
+		opts = append(opts, oac.WithRateLimit(perSecond))
+	}
+
+	if circuitBreaker != "" {
+		threshold, cooldown, err := oac.ParseCircuitBreaker(circuitBreaker)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --circuit-breaker: %w", err)
+		}
+		opts = append(opts, oac.WithCircuitBreaker(threshold, cooldown))
+	}
+
+	if apiVersion != "" {
+		opts = append(opts, oac.WithAPIVersion(apiVersion))
+	}
+
+	if maxResponseSize != "" {
+		size, err := oac.ParseSize(maxResponseSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-response-size: %w", err)
+		}
+		opts = append(opts, oac.WithMaxResponseSize(size))
+	}
+
+	if printCurl {
+		opts = append(opts, oac.WithMiddleware(oac.PrintCurl(os.Stderr)))
+	}
+
+	for _, command := range requestHooks {
+		opts = append(opts, oac.WithRequestHooks(oac.ExecRequestHook(command)))
+	}
+	for _, file := range requestPlugins {
+		hook, err := oac.LoadRequestHookPlugin(file)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, oac.WithRequestHooks(hook))
+	}
+
+	return oac.NewOacClient(append(opts, extra...)...)
+}
+
+// bucketCall executes a REST call streaming the body from and/or the response to Object Storage
+func bucketCall(method, path string) error {
+	ctx := commandCtx
+	store, err := oac.NewObjectStorage(ctx, bucketNamespace, bucketName)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+
+	if bodyObject != "" {
+		body, err := store.Download(ctx, bodyObject)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		progress := newTransfer("Uploading "+bodyObject, 0)
+		resp, err := client.Upload(method, path, "application/octet-stream", progress.Reader(body))
+		progress.Done()
+		if err != nil {
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+		return printJSON(resp)
+	}
+
+	resp, err := client.Stream(method, path)
+	if err != nil {
+		return fmt.Errorf("error executing REST call: %w", err)
+	}
+	defer resp.Close()
+
+	progress := newTransfer("Downloading "+outputObject, 0)
+	err = store.Upload(ctx, outputObject, progress.Reader(resp))
+	progress.Done()
+	if err != nil {
+		return err
+	}
+	infof("Uploaded %s/%s", store, outputObject)
+	return nil
 }
 
-// Execute runs the CLI
+// chunkedCall uploads a body file in resumable chunks
+func chunkedCall(method, path string, positional []string) error {
+	file := bodyFile(positional)
+	if file == "" {
+		return fmt.Errorf("--chunk-size needs the body as a file, with --data-file or the bodyFile argument")
+	}
+	if err := verifyBodyFile(positional); err != nil {
+		return err
+	}
+	chunkSize, err := oac.ParseSize(uploadChunkSize)
+	if err != nil {
+		return fmt.Errorf("invalid --chunk-size: %w", err)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+	header, err := parseHeaders(headerFlags)
+	if err != nil {
+		return err
+	}
+
+	var progress *transfer
+	resp, err := client.UploadChunked(method, path, file, oac.ChunkedUploadOptions{
+		ChunkSize: chunkSize,
+		Header:    header,
+		Restart:   restartUpload,
+		OnProgress: func(confirmed, total int64) {
+			if progress == nil {
+				progress = newTransfer("Uploading "+filepath.Base(file), total)
+				if confirmed > 0 {
+					infof("Resuming upload of %s at %s", file, byteSize(confirmed))
+				}
+			}
+			progress.add(int(confirmed - progress.n))
+		},
+	})
+	if progress != nil {
+		progress.Done()
+	}
+	if err != nil {
+		return fmt.Errorf("error executing REST call: %w", err)
+	}
+	return printResponse(resp)
+}
+
+// Execute runs the CLI, exiting with a code describing the failure
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// the spec and aliases come from the --config given and may be set in .env files or
+	// the config file's env, so these are loaded before the commands are registered
+	config := configFileFromArgs(os.Args[1:])
+	cfg, err := oac.LoadConfig(config)
+	if err != nil {
+		cfg = nil
+	}
+	_, _ = oac.LoadEnvLayers(oac.DotEnvFiles(config), cfg)
+
+	registerCompletions()
+	registerAPICommands(config)
+	registerAliases(cfg)
+	// after the generated commands, so their failures are run errors too
+	markRunErrors(rootCmd)
+
+	ctx, stop := interruptContext()
+	defer stop()
+	commandCtx = ctx
+
+	args, err := expandAliasArgs(os.Args[1:])
+	if err == nil {
+		rootCmd.SetArgs(args)
+		err = rootCmd.ExecuteContext(ctx)
+	}
+	if err != nil && ctx.Err() != nil {
+		err = &runError{err: &exitStatus{code: exitInterrupted, msg: "interrupted"}}
+	}
+	finishTracing(err)
+	if showStats {
+		printStats(os.Stderr)
+	}
+	if err != nil {
+		reportError(err)
+		os.Exit(exitCode(err))
 	}
 }
 
-// init is left empty but can be used to add subcommands if needed
-func init() {}
+// init registers global flags
+func init() {
+	rootCmd.SilenceErrors = true
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVarP(&profileName, "profile", "p", "", "config profile to use (env OAC_PROFILE)")
+	flags.StringVar(&instanceURL, "instance", "", "OAC instance URL overriding OAC_INSTANCE for this invocation")
+	flags.StringVar(&actAsUser, "as-user", "", "act on behalf of this user through an IDCS token exchange")
+	flags.StringSliceVar(&scopeOverride, "scope", nil, "request a fresh, uncached token with these scopes instead of the configured ones")
+	flags.BoolVar(&noRefresh, "no-refresh", false, "use the cached token only and fail instead of requesting a new one, e.g. while auth warm keeps it fresh")
+	flags.StringVar(&configPath, "config", "", "config file path (env OAC_CONFIG, default ~/.config/oac-client/config.yaml)")
+	flags.StringVar(&transportConfig.CABundle, "ca-bundle", "", "PEM file with additional trusted CA certificates (env OAC_CA_BUNDLE)")
+	flags.StringVar(&transportConfig.ClientCert, "client-cert", "", "PEM client certificate for mutual TLS (env OAC_CLIENT_CERT)")
+	flags.StringVar(&transportConfig.ClientKey, "client-key", "", "PEM client private key for mutual TLS (env OAC_CLIENT_KEY)")
+	flags.BoolVar(&transportConfig.InsecureSkipVerify, "insecure-skip-verify", false, "do not verify TLS certificates, for self-signed test instances only (env OAC_INSECURE_SKIP_VERIFY)")
+	flags.StringVar(&transportConfig.TLSMinVersion, "tls-min-version", "", "lowest TLS version accepted: 1.0, 1.1, 1.2 or 1.3 (env OAC_TLS_MIN_VERSION, default 1.2)")
+	flags.StringVar(&transportConfig.CipherSuites, "tls-ciphers", "", "comma-separated TLS 1.2 cipher suites in order of preference (env OAC_TLS_CIPHERS)")
+	flags.BoolVar(&failSilently, "fail-silently", false, "do not print response bodies of failed requests")
+	flags.DurationVar(&cacheTTL, "cache-ttl", 0, "serve repeated GET requests from a local cache for this long, e.g. 5m")
+	flags.BoolVar(&compressRequests, "compress", false, "gzip request bodies of 64 KiB or more (env OAC_COMPRESS_MIN_SIZE sets the threshold)")
+	flags.StringVar(&saveDir, "save-dir", "", "write every response body to a timestamped file in this directory, listed in its index.jsonl")
+	flags.StringVar(&recordFile, "record", "", "record API calls and responses to this cassette file")
+	flags.StringVar(&replayFile, "replay", "", "answer API calls from this cassette file instead of OAC")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	flags.StringVar(&rateLimit, "rate", "", "maximum request rate, e.g. 5/s or 100/m (env OAC_RATE_LIMIT)")
+	flags.StringVar(&circuitBreaker, "circuit-breaker", "", "stop sending requests after this many failures in a row for a cooldown, e.g. 5/30s, or off (env OAC_CIRCUIT_BREAKER)")
+	flags.StringVar(&apiVersion, "api-version", "", "REST API version for relative paths and paths under /api/"+oac.DefaultAPIVersion+" (env OAC_API_VERSION)")
+	flags.BoolVar(&printCurl, "print-curl", false, "print each request to stderr as an equivalent curl command, with $OAC_TOKEN for the token")
+	flags.StringArrayVar(&requestHooks, "request-hook", nil, "command run before every request, printing headers to add, e.g. a signature (repeatable)")
+	flags.StringArrayVar(&requestPlugins, "request-plugin", nil, "Go plugin exporting a RequestHook run before every request (repeatable)")
+	flags.StringVar(&maxResponseSize, "max-response-size", "", "largest response held in memory, e.g. 256MiB; larger ones are spooled to a temp file (env OAC_MAX_RESPONSE_SIZE, default 64MiB)")
+
+	rootCmd.Flags().StringSliceVar(&fanOutInstances, "instances", nil, "run a GET, HEAD or OPTIONS against each of these profiles or instance URLs")
+	rootCmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "run a GET, HEAD or OPTIONS against every configured profile")
+	rootCmd.MarkFlagsMutuallyExclusive("instances", "all-profiles")
+	rootCmd.Flags().StringArrayVarP(&headerFlags, "header", "H", nil, "extra request header \"Name: value\" (repeatable)")
+	rootCmd.Flags().StringArrayVarP(&queryFlags, "query", "q", nil, "query parameter key=value (repeatable)")
+	rootCmd.Flags().BoolVarP(&includeHeaders, "include", "i", false, "print the status line and main response headers before the body")
+	rootCmd.Flags().StringVar(&etagFlag, "etag", "", "If-Match value for PUT/PATCH instead of the cached ETag (\"none\" sends none)")
+	rootCmd.Flags().BoolVar(&ifNoneMatch, "if-none-match", false, "send the cached ETag on GET and print only the status when not modified")
+	rootCmd.Flags().StringVarP(&dataLiteral, "data", "d", "", "literal request body")
+	rootCmd.Flags().StringVar(&dataFile, "data-file", "", "read the request body from this file")
+	rootCmd.Flags().BoolVar(&dataStdin, "data-stdin", false, "read the request body from standard input")
+	rootCmd.Flags().StringVar(&uploadChunkSize, "chunk-size", "", "upload the body file in resumable chunks of this size, e.g. 8MiB")
+	rootCmd.Flags().BoolVar(&restartUpload, "restart-upload", false, "with --chunk-size, ignore an interrupted upload and start over")
+	rootCmd.Flags().BoolVar(&verifyBody, "verify", false, "refuse to send a body file that does not match its .sha256 file")
+	rootCmd.MarkFlagsMutuallyExclusive("data", "data-file", "data-stdin")
+	rootCmd.Flags().StringArrayVar(&templateVars, "var", nil, "template variable key=value for the body (repeatable)")
+	rootCmd.Flags().StringVar(&varFile, "var-file", "", "YAML or JSON file with template variables for the body")
+	rootCmd.Flags().StringVar(&bucketName, "bucket", "", "OCI Object Storage bucket for --object/--body-object")
+	rootCmd.Flags().StringVar(&bucketNamespace, "namespace", "", "Object Storage namespace (looked up when empty)")
+	rootCmd.Flags().StringVar(&outputObject, "object", "", "stream the response body into this bucket object")
+	rootCmd.Flags().StringVar(&bodyObject, "body-object", "", "stream the request body from this bucket object")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"oac-client/core/oac"
+)
+
+// schemaCheck is warn, strict or off
+var schemaCheck string
+
+// checkRequestBody validates a JSON body against the OpenAPI spec operation matching
+// method and path, logging each issue and failing with --schema-check strict
+func checkRequestBody(op *oac.Operation, method, path string, body []byte) error {
+	switch schemaCheck {
+	case "warn", "strict", "off":
+	default:
+		return fmt.Errorf("invalid --schema-check %q, expected warn, strict or off", schemaCheck)
+	}
+	if schemaCheck == "off" || apiSpec == nil || len(body) == 0 || !json.Valid(body) {
+		return nil
+	}
+	if op == nil {
+		if op = apiSpec.FindOperation(method, path); op == nil {
+			return nil
+		}
+	}
+
+	issues, err := apiSpec.ValidateBody(op, body)
+	if err != nil || len(issues) == 0 {
+		return err
+	}
+	for _, issue := range issues {
+		slog.Warn("request body does not match the API schema", "operation", op.ID, "field", issue.Field, "problem", issue.Message)
+	}
+	if schemaCheck == "strict" {
+		return fmt.Errorf("request body has %d schema issues for %s, not sent", len(issues), op.ID)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&schemaCheck, "schema-check", "warn",
+		"check request bodies against the OpenAPI spec: warn, strict (do not send) or off")
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	servePort    int
+	serveToken   string
+	serveNoAuth  bool
+	serveRetries int
+	serveTLSCert string
+	serveTLSKey  string
+)
+
+// serveCmd runs an authenticated HTTP facade in front of the OAC REST API
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an authenticated HTTP facade proxying to the OAC REST API",
+	Long: `Serve an authenticated HTTP facade proxying to the OAC REST API, so internal
+tools reach OAC through one egress point holding the credentials instead of
+each holding their own.
+
+Requests under /api/ are forwarded to the instance of the profile with its
+cached token, retrying idempotent requests on 429, 502, 503 and 504 (--retries),
+within --rate and the circuit breaker; everything else is refused. Callers send
+Authorization: Bearer <token> with the token of --token (env OAC_SERVE_TOKEN),
+which is never forwarded. Requests the profile protects are refused with 403.
+
+GET /healthz needs no token and reports the profile, instance and token expiry.
+With --tls-cert and --tls-key the facade serves HTTPS. On SIGINT or SIGTERM it
+stops accepting requests and lets running ones finish.`,
+	Example: `  OAC_SERVE_TOKEN=$(openssl rand -hex 32) oac-client --profile prod serve --port 8088
+  curl -H "Authorization: Bearer $OAC_SERVE_TOKEN" http://oac-egress:8088/api/20210901/snapshots
+  oac-client serve --port 8443 --tls-cert egress.pem --tls-key egress.key --rate 10/s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serveToken == "" {
+			serveToken = os.Getenv("OAC_SERVE_TOKEN")
+		}
+		if serveToken == "" && !serveNoAuth {
+			return fmt.Errorf("set --token or OAC_SERVE_TOKEN, or pass --no-auth to accept requests from anyone who can reach the port")
+		}
+		if (serveTLSCert == "") != (serveTLSKey == "") {
+			return fmt.Errorf("--tls-cert and --tls-key go together")
+		}
+
+		// there is no terminal to confirm protected requests on
+		client, err := newClientFor(profileName, instanceURL, oac.WithConfirmation(nil), oac.WithRetries(serveRetries))
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if serveNoAuth {
+			slog.Warn("serving without authentication; anyone reaching the port acts with the profile's credentials")
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+			_, expiry := client.CachedToken()
+			health := map[string]any{"status": "ok", "profile": client.ProfileName(), "instance": client.Instance()}
+			if !expiry.IsZero() {
+				health["tokenExpiry"] = expiry.UTC()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(health)
+		})
+		mux.Handle("/", requireServeToken(client.ProxyHandler()))
+		srv := &http.Server{
+			Addr:              fmt.Sprintf(":%d", servePort),
+			Handler:           logServeRequests(mux),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		errc := make(chan error, 1)
+		go func() {
+			if serveTLSCert != "" {
+				errc <- srv.ListenAndServeTLS(serveTLSCert, serveTLSKey)
+			} else {
+				errc <- srv.ListenAndServe()
+			}
+		}()
+		slog.Info("serving OAC facade", "addr", srv.Addr, "profile", client.ProfileName(), "instance", client.Instance(), "tls", serveTLSCert != "")
+
+		select {
+		case err := <-errc:
+			return err
+		case <-commandCtx.Done():
+		}
+
+		slog.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// requireServeToken refuses requests without the bearer token of --token
+func requireServeToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveToken != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(serveToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="oac-client"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streamed downloads through as they arrive
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// logServeRequests logs every request with its status and duration
+func logServeRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status,
+			"duration", time.Since(start).Round(time.Millisecond), "remote", r.RemoteAddr)
+	})
+}
+
+func init() {
+	flags := serveCmd.Flags()
+	flags.IntVar(&servePort, "port", 8088, "port to listen on")
+	flags.StringVar(&serveToken, "token", "", "bearer token callers must send (env OAC_SERVE_TOKEN)")
+	flags.BoolVar(&serveNoAuth, "no-auth", false, "accept requests without a token, e.g. behind an authenticating proxy")
+	flags.IntVar(&serveRetries, "retries", 2, "retries of idempotent requests failing with 429, 502, 503 or 504")
+	flags.StringVar(&serveTLSCert, "tls-cert", "", "certificate file to serve HTTPS with")
+	flags.StringVar(&serveTLSKey, "tls-key", "", "private key file of --tls-cert")
+
+	rootCmd.AddCommand(serveCmd)
+}
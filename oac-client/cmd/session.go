@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionUser      string
+	sessionOlderThan time.Duration
+	sessionJSON      bool
+)
+
+// sessionCmd groups session management commands
+var sessionCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "List and terminate user sessions on the instance",
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List open sessions, longest running first",
+	Example: `  oac-client sessions list --older-than 30m
+  oac-client sessions list --user jdoe --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		sessions, err := client.ListSessions()
+		if err != nil {
+			return fmt.Errorf("error listing sessions: %w", err)
+		}
+		sessions = filterSessions(sessions, time.Now())
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].Started().Before(sessions[j].Started())
+		})
+
+		ids := make([]string, 0, len(sessions))
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+		rememberCompletions("sessions", ids)
+
+		if sessionJSON {
+			data, err := json.Marshal(sessions)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+		fmt.Printf("%-36s %-24s %-10s %7s %-16s %s\n", "ID", "USER", "AGE", "QUERIES", "CLIENT", "LAST ACTIVITY")
+		for _, s := range sessions {
+			age := ""
+			if started := s.Started(); !started.IsZero() {
+				age = time.Since(started).Truncate(time.Second).String()
+			}
+			fmt.Printf("%-36s %-24s %-10s %7d %-16s %s\n", s.ID, truncate(s.User, 24), age,
+				s.RunningQueries, truncate(s.ClientAddress, 16), s.LastActivityAt)
+		}
+		return nil
+	},
+}
+
+var sessionKillCmd = &cobra.Command{
+	Use:   "kill <id>...",
+	Short: "Terminate sessions and cancel their running queries",
+	Example: `  oac-client sessions kill 5f0c2a1e-7d1b-4c55-9a0e-2b8f3e1d9c40
+  oac-client sessions kill $(oac-client sessions list --user etl_batch --json | jq -r '.[].id')`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		failed := 0
+		for _, id := range args {
+			if err := client.KillSession(id); err != nil {
+				reportError(fmt.Errorf("%s: %w", id, err))
+				failed++
+				continue
+			}
+			infof("Killed session %s", id)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d sessions could not be killed", failed, len(args))
+		}
+		return nil
+	},
+}
+
+// filterSessions keeps the sessions matching --user and --older-than
+func filterSessions(sessions []oac.Session, now time.Time) []oac.Session {
+	var out []oac.Session
+	for _, s := range sessions {
+		if sessionUser != "" && !strings.EqualFold(s.User, sessionUser) {
+			continue
+		}
+		if sessionOlderThan > 0 && (s.Started().IsZero() || now.Sub(s.Started()) < sessionOlderThan) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func init() {
+	flags := sessionListCmd.Flags()
+	flags.StringVar(&sessionUser, "user", "", "only sessions of this user")
+	flags.DurationVar(&sessionOlderThan, "older-than", 0, "only sessions started at least this long ago")
+	flags.BoolVar(&sessionJSON, "json", false, "print the sessions as JSON")
+
+	sessionCmd.AddCommand(sessionListCmd, sessionKillCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	settingsJSON bool
+	settingsFile string
+	settingsDiff bool
+)
+
+// settingsCmd groups the system settings commands
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Read and change the system settings of the instance",
+	Long: `Read and change the system settings of the instance, those of the System
+Settings page of the console. For the settings of oac-client itself, see
+oac-client config show.`,
+}
+
+var settingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the system settings and their values",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		settings, err := client.ListSystemSettings()
+		if err != nil {
+			return fmt.Errorf("error listing system settings: %w", err)
+		}
+		keys := make([]string, 0, len(settings))
+		for _, s := range settings {
+			keys = append(keys, s.Key)
+		}
+		rememberCompletions("settings", keys)
+
+		if settingsJSON {
+			data, err := json.Marshal(settings)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+		for _, s := range settings {
+			fmt.Printf("%-50s %s\n", s.Key, s.ValueString())
+		}
+		return nil
+	},
+}
+
+var settingsGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a system setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		s, err := client.GetSystemSetting(args[0])
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", args[0], err)
+		}
+		if settingsJSON {
+			data, err := json.Marshal(s)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+		fmt.Println(s.ValueString())
+		return nil
+	},
+}
+
+var settingsSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change the value of a system setting",
+	Long: `Change the value of a system setting. The value is converted to the type of
+the current value: true or false for switches, a number for numeric settings.`,
+	Example: `  oac-client settings set EnableSubjectAreaAutoComplete true
+  oac-client settings set QueryLimitsMaxRows 250000`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		current, err := client.GetSystemSetting(args[0])
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", args[0], err)
+		}
+		value, err := oac.ParseSettingValue(current.Value, args[1])
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", args[0], err)
+		}
+		if err := client.SetSystemSetting(args[0], value); err != nil {
+			return fmt.Errorf("error setting %s: %w", args[0], err)
+		}
+		infof("%s: %s -> %s", args[0], current.ValueString(), args[1])
+		if current.RestartRequired {
+			infof("The change takes effect after the instance restarts")
+		}
+		return nil
+	},
+}
+
+var settingsApplyCmd = &cobra.Command{
+	Use:   "apply --file <settings.yaml>",
+	Short: "Set system settings to the values of a YAML file",
+	Long: `Set system settings to the values of a YAML or JSON file, mapping setting
+keys to values:
+
+  EnableSubjectAreaAutoComplete: true
+  QueryLimitsMaxRows: 250000
+
+Settings missing from the file are left alone. Only settings whose value
+differs are changed; --diff prints the changes without applying them, e.g. to
+check an environment for drift.`,
+	Example: `  oac-client settings apply --file settings.yaml --diff
+  oac-client --profile prod settings apply --file settings.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		desired, err := oac.LoadSystemSettingsFile(settingsFile)
+		if err != nil {
+			return err
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		changes, err := client.PlanSystemSettings(desired)
+		if err != nil {
+			return err
+		}
+		restart := false
+		for _, ch := range changes {
+			fmt.Println(ch.String())
+			restart = restart || ch.RestartRequired
+		}
+		if len(changes) == 0 {
+			infof("System settings are up to date")
+			return nil
+		}
+		if settingsDiff {
+			infof("%d settings would change", len(changes))
+			return nil
+		}
+
+		if err := client.ApplySystemSettings(changes); err != nil {
+			return err
+		}
+		infof("Changed %d settings", len(changes))
+		if restart {
+			infof("Some changes take effect after the instance restarts")
+		}
+		return nil
+	},
+}
+
+func init() {
+	settingsListCmd.Flags().BoolVar(&settingsJSON, "json", false, "print the settings as JSON, with descriptions")
+	settingsGetCmd.Flags().BoolVar(&settingsJSON, "json", false, "print the setting as JSON, with its description")
+	settingsApplyCmd.Flags().StringVarP(&settingsFile, "file", "f", "", "YAML or JSON file mapping setting keys to values")
+	settingsApplyCmd.Flags().BoolVar(&settingsDiff, "diff", false, "show the changes without applying them")
+	_ = settingsApplyCmd.MarkFlagRequired("file")
+
+	settingsCmd.AddCommand(settingsListCmd, settingsGetCmd, settingsSetCmd, settingsApplyCmd)
+	rootCmd.AddCommand(settingsCmd)
+}
@@ -0,0 +1,511 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotCron         string
+	snapshotKeep         int
+	snapshotTargetDir    string
+	snapshotPrefix       string
+	snapshotPassword     string
+	snapshotTimeout      time.Duration
+	snapshotPrintCrontab bool
+	snapshotBucket       string
+	snapshotNamespace    string
+	snapshotKinds        []string
+	snapshotJSON         bool
+	snapshotInclude      []string
+	snapshotExclude      []string
+	snapshotIncludeFile  string
+	snapshotNoWait       bool
+	snapshotVerify       bool
+)
+
+// snapshotCmd groups snapshot helpers
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot backup helpers",
+}
+
+// snapshotScheduleCmd creates, downloads and prunes snapshots
+var snapshotScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Create and download a snapshot, pruning old backups",
+	Long: `Create a snapshot, download it to a target directory and prune older backups.
+
+Run it from cron or a CI schedule. --keep applies both to snapshots on the
+instance whose name starts with --prefix and to BAR files in --target-dir
+or --bucket. With --bucket the BAR file is streamed straight to OCI Object
+Storage without touching local disk.
+Use --print-crontab to generate the crontab entry for --cron.
+
+Examples:
+  oac-client snapshot schedule --cron "0 2 * * *" --keep 7 --target-dir /backups/oac --print-crontab
+  oac-client snapshot schedule --keep 7 --target-dir /backups/oac
+  oac-client snapshot schedule --keep 14 --bucket oac-backups
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the new snapshot must survive retention, and other snapshots must not match it
+		if snapshotKeep < 1 {
+			return fmt.Errorf("--keep must be at least 1")
+		}
+		if snapshotPrefix == "" {
+			return fmt.Errorf("--prefix must not be empty")
+		}
+
+		var schedule *oac.CronSchedule
+		if snapshotCron != "" {
+			s, err := oac.ParseCron(snapshotCron)
+			if err != nil {
+				return err
+			}
+			schedule = s
+		}
+
+		if snapshotPrintCrontab {
+			if schedule == nil {
+				return fmt.Errorf("--print-crontab requires --cron")
+			}
+			return printSnapshotCrontab(schedule)
+		}
+
+		password := snapshotPassword
+		if password == "" {
+			password = os.Getenv("OAC_SNAPSHOT_PASSWORD")
+		}
+		if password == "" {
+			return fmt.Errorf("a snapshot password is required (--password or OAC_SNAPSHOT_PASSWORD)")
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		name := snapshotPrefix + "-" + time.Now().UTC().Format(backupTimeLayout)
+		infof("Creating snapshot %s", name)
+
+		wrID, err := client.CreateSnapshot(name, "Scheduled backup by oac-client", password)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+
+		wr, err := waitWorkRequest(client, "Creating snapshot", wrID, 10*time.Second, snapshotTimeout)
+		if err != nil {
+			return err
+		}
+
+		snapshotID, err := findSnapshotID(client, wr, name)
+		if err != nil {
+			return err
+		}
+
+		if snapshotTargetDir != "" {
+			if err := saveSnapshot(client, snapshotID, filepath.Join(snapshotTargetDir, name+".bar")); err != nil {
+				return err
+			}
+			if err := pruneLocalBackups(snapshotTargetDir, snapshotPrefix, snapshotKeep); err != nil {
+				return err
+			}
+		}
+
+		if snapshotBucket != "" {
+			if err := uploadSnapshot(client, snapshotID, name+".bar"); err != nil {
+				return err
+			}
+		}
+
+		deleted, err := client.PruneSnapshots(snapshotPrefix, snapshotKeep)
+		for _, s := range deleted {
+			infof("Deleted snapshot %s", s.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if schedule != nil {
+			infof("Next scheduled run: %s", schedule.Next(time.Now()).Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+// findSnapshotID resolves the snapshot created by a work request
+func findSnapshotID(client *oac.OacClient, wr *oac.WorkRequest, name string) (string, error) {
+	for _, r := range wr.Resources {
+		if r.Identifier != "" {
+			return r.Identifier, nil
+		}
+	}
+
+	snaps, err := client.ListSnapshots()
+	if err != nil {
+		return "", err
+	}
+	for _, s := range snaps {
+		if s.Name == name {
+			return s.ID, nil
+		}
+	}
+	return "", fmt.Errorf("snapshot %s not found after creation", name)
+}
+
+// saveSnapshot streams a snapshot BAR file to dest
+func saveSnapshot(client *oac.OacClient, id, dest string) error {
+	bar, err := client.StreamSnapshot(id)
+	if err != nil {
+		return fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	defer bar.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	progress := newTransfer("Downloading "+filepath.Base(dest), 0)
+	_, err = io.Copy(f, progress.Reader(bar))
+	progress.Done()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = bar.Verify(dest)
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	if err := oac.WriteChecksum(dest, bar.Sum()); err != nil {
+		return err
+	}
+	infof("Saved %s (sha256 %s)", dest, bar.Sum())
+	return nil
+}
+
+// uploadSnapshot streams a snapshot BAR file into the bucket and prunes old objects
+func uploadSnapshot(client *oac.OacClient, id, object string) error {
+	ctx := commandCtx
+	store, err := oac.NewObjectStorage(ctx, snapshotNamespace, snapshotBucket)
+	if err != nil {
+		return err
+	}
+
+	bar, err := client.StreamSnapshot(id)
+	if err != nil {
+		return fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	defer bar.Close()
+
+	progress := newTransfer("Uploading "+object, 0)
+	err = store.Upload(ctx, object, progress.Reader(bar))
+	progress.Done()
+	if err == nil {
+		err = bar.Verify(object)
+	}
+	if err != nil {
+		return err
+	}
+	infof("Uploaded %s/%s", store, object)
+
+	listed, err := store.List(ctx, snapshotPrefix)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, n := range listed {
+		if scheduledBackup(n, snapshotPrefix) {
+			names = append(names, n)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	if len(names) <= snapshotKeep {
+		return nil
+	}
+	for _, n := range names[snapshotKeep:] {
+		if err := store.Delete(ctx, n); err != nil {
+			return err
+		}
+		infof("Removed %s/%s", store, n)
+	}
+	return nil
+}
+
+// pruneLocalBackups keeps the newest keep BAR files of the schedule with prefix in dir,
+// removing the checksum files of the others along with them
+func pruneLocalBackups(dir, prefix string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && scheduledBackup(e.Name(), prefix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	// names embed a sortable UTC timestamp, newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	if len(files) <= keep {
+		return nil
+	}
+
+	for _, f := range files[keep:] {
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+		if err := os.Remove(f + oac.ChecksumExt); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		infof("Removed %s", f)
+	}
+	return nil
+}
+
+// backupTimeLayout is the UTC timestamp in the names of scheduled backups
+const backupTimeLayout = "20060102-150405"
+
+// scheduledBackup reports whether name is a BAR file snapshot schedule wrote for prefix,
+// <prefix>-<timestamp>.bar, and not one of a schedule whose prefix merely starts the same
+func scheduledBackup(name, prefix string) bool {
+	stamp, ok := strings.CutPrefix(name, prefix+"-")
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, ".bar")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(backupTimeLayout, stamp)
+	return err == nil
+}
+
+// printSnapshotCrontab prints the crontab entry running this command on schedule
+func printSnapshotCrontab(schedule *oac.CronSchedule) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{exe, "snapshot", "schedule", "--keep", fmt.Sprint(snapshotKeep), "--prefix", snapshotPrefix}
+	if snapshotBucket != "" {
+		args = append(args, "--bucket", snapshotBucket)
+		if snapshotNamespace != "" {
+			args = append(args, "--namespace", snapshotNamespace)
+		}
+	}
+	if snapshotTargetDir != "" {
+		dir, err := filepath.Abs(snapshotTargetDir)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--target-dir", dir)
+	}
+
+	for i, arg := range args {
+		args[i] = crontabQuote(arg)
+	}
+	fmt.Printf("%s %s\n", schedule, strings.Join(args, " "))
+	return nil
+}
+
+// crontabQuote quotes an argument of a crontab command for the shell, escaping the %
+// that cron would turn into a newline
+func crontabQuote(arg string) string {
+	safe := arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@+,", r))
+	}) < 0
+	if !safe {
+		arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.ReplaceAll(arg, "%", `\%`)
+}
+
+// snapshotInspectCmd lists the contents of a local BAR file
+var snapshotInspectCmd = &cobra.Command{
+	Use:   "inspect <file.bar>",
+	Short: "List the contents of a downloaded snapshot",
+	Long: `List the workbooks, datasets, connections, users and other content stored in
+a downloaded BAR file, without contacting the instance. Use it to verify a
+backup or to pick the items to restore. Encrypted BAR files can be listed
+without their password.`,
+	Example: `  oac-client snapshot inspect backup.bar
+  oac-client snapshot inspect backup.bar --kind workbooks,datasets
+  oac-client snapshot inspect backup.bar --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if snapshotVerify {
+			if err := oac.VerifyChecksum(args[0]); err != nil {
+				return err
+			}
+		}
+		entries, err := oac.InspectSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		if len(snapshotKinds) > 0 {
+			keep := map[string]bool{}
+			for _, k := range snapshotKinds {
+				keep[strings.ToLower(k)] = true
+			}
+			filtered := entries[:0]
+			for _, e := range entries {
+				if keep[e.Kind] {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+		if snapshotJSON {
+			data, err := json.Marshal(entries)
+			if err != nil {
+				return err
+			}
+			return printJSON(data)
+		}
+
+		counts := map[string]int{}
+		var total int64
+		fmt.Printf("%-12s %10s  %s\n", "KIND", "SIZE", "PATH")
+		for _, e := range entries {
+			fmt.Printf("%-12s %10s  %s\n", e.Kind, byteSize(e.Size), e.Path)
+			counts[e.Kind]++
+			total += e.Size
+		}
+
+		var summary []string
+		for _, k := range oac.SnapshotKinds {
+			if counts[k] > 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", counts[k], k))
+			}
+		}
+		infof("\n%d files, %s: %s", len(entries), byteSize(total), strings.Join(summary, ", "))
+		return nil
+	},
+}
+
+// snapshotRestoreCmd restores a snapshot, optionally only some kinds of content
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Short: "Restore a snapshot, entirely or selected kinds of content",
+	Long: `Restore a snapshot by id or name.
+
+By default everything in the snapshot is restored. --include restores only the
+listed kinds of content and --exclude leaves the listed kinds untouched;
+--include-file reads the kinds to include from a file, one or more per line.
+Kinds are ` + strings.Join(oac.SnapshotKinds[:len(oac.SnapshotKinds)-1], ", ") + `.
+Use snapshot inspect to see what a downloaded BAR file contains.`,
+	Example: `  oac-client snapshot restore oac-client-backup-20260101-020000
+  oac-client snapshot restore 7f3a --include workbooks,datasets --exclude users
+  oac-client snapshot restore 7f3a --include-file restore.txt --no-wait`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password := snapshotPassword
+		if password == "" {
+			password = os.Getenv("OAC_SNAPSHOT_PASSWORD")
+		}
+		if password == "" {
+			return fmt.Errorf("a snapshot password is required (--password or OAC_SNAPSHOT_PASSWORD)")
+		}
+		opts := oac.RestoreOptions{Password: password, Include: snapshotInclude, Exclude: snapshotExclude}
+		if snapshotIncludeFile != "" {
+			kinds, err := oac.LoadKindsFile(snapshotIncludeFile)
+			if err != nil {
+				return err
+			}
+			opts.Include = append(opts.Include, kinds...)
+		}
+		kinds, err := opts.Kinds()
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		snap, err := resolveSnapshot(client, args[0])
+		if err != nil {
+			return err
+		}
+
+		if kinds == nil {
+			infof("Restoring snapshot %s", snap.Name)
+		} else {
+			infof("Restoring %s from snapshot %s", strings.Join(kinds, ", "), snap.Name)
+		}
+		wrID, err := client.RestoreSnapshot(snap.ID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+		if snapshotNoWait {
+			fmt.Println(wrID)
+			return nil
+		}
+		if _, err := waitWorkRequest(client, "Restoring snapshot", wrID, 10*time.Second, snapshotTimeout); err != nil {
+			return err
+		}
+		infof("Restored snapshot %s", snap.Name)
+		return nil
+	},
+}
+
+// resolveSnapshot finds a snapshot by id or name
+func resolveSnapshot(client *oac.OacClient, ref string) (*oac.Snapshot, error) {
+	snaps, err := client.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snaps {
+		if s.ID == ref || s.Name == ref {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s not found", ref)
+}
+
+func init() {
+	snapshotInspectCmd.Flags().StringSliceVar(&snapshotKinds, "kind", nil, "only list these kinds ("+strings.Join(oac.SnapshotKinds, ", ")+")")
+	snapshotInspectCmd.Flags().BoolVar(&snapshotJSON, "json", false, "print the entries as JSON")
+	snapshotInspectCmd.Flags().BoolVar(&snapshotVerify, "verify", false, "fail unless the file matches its .sha256 file")
+
+	restoreFlags := snapshotRestoreCmd.Flags()
+	restoreFlags.StringSliceVar(&snapshotInclude, "include", nil, "only restore these kinds of content")
+	restoreFlags.StringSliceVar(&snapshotExclude, "exclude", nil, "kinds of content to leave untouched")
+	restoreFlags.StringVar(&snapshotIncludeFile, "include-file", "", "file listing the kinds of content to restore")
+	restoreFlags.StringVar(&snapshotPassword, "password", "", "snapshot password (env OAC_SNAPSHOT_PASSWORD)")
+	restoreFlags.DurationVar(&snapshotTimeout, "timeout", 2*time.Hour, "maximum time to wait for the restore")
+	restoreFlags.BoolVar(&snapshotNoWait, "no-wait", false, "print the work request id instead of waiting for the restore")
+
+	flags := snapshotScheduleCmd.Flags()
+	flags.StringVar(&snapshotCron, "cron", "", "cron expression the backup runs on, e.g. \"0 2 * * *\"")
+	flags.BoolVar(&snapshotPrintCrontab, "print-crontab", false, "print the crontab entry for --cron and exit")
+	flags.IntVar(&snapshotKeep, "keep", 7, "number of backups to retain, at least 1")
+	flags.StringVar(&snapshotTargetDir, "target-dir", "", "directory to download the BAR file to")
+	flags.StringVar(&snapshotBucket, "bucket", "", "OCI Object Storage bucket to upload the BAR file to")
+	flags.StringVar(&snapshotNamespace, "namespace", "", "Object Storage namespace (looked up when empty)")
+	flags.StringVar(&snapshotPrefix, "prefix", "oac-client-backup", "snapshot name prefix used for retention")
+	flags.StringVar(&snapshotPassword, "password", "", "snapshot password (env OAC_SNAPSHOT_PASSWORD)")
+	flags.DurationVar(&snapshotTimeout, "timeout", 2*time.Hour, "maximum time to wait for the snapshot")
+
+	snapshotCmd.AddCommand(snapshotScheduleCmd, snapshotInspectCmd, snapshotRestoreCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
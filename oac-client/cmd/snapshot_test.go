@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneLocalBackups(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"prod-20261001-020000.bar", "prod-20261001-020000.bar.sha256",
+		"prod-20261002-020000.bar", "prod-20261002-020000.bar.sha256",
+		"prod-20261003-020000.bar", "prod-20261003-020000.bar.sha256",
+		"prod-eu-20261001-020000.bar", "prod-eu-20261001-020000.bar.sha256",
+		"prod-manual.bar",
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneLocalBackups(dir, "prod", 2); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	want := []string{
+		"prod-20261002-020000.bar", "prod-20261002-020000.bar.sha256",
+		"prod-20261003-020000.bar", "prod-20261003-020000.bar.sha256",
+		"prod-eu-20261001-020000.bar", "prod-eu-20261001-020000.bar.sha256",
+		"prod-manual.bar",
+	}
+	if !slices.Equal(left, want) {
+		t.Errorf("left %v, want %v", left, want)
+	}
+}
+
+func TestCrontabQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"/usr/local/bin/oac-client", "/usr/local/bin/oac-client"},
+		{"--keep", "--keep"},
+		{"/mnt/OAC Backups", "'/mnt/OAC Backups'"},
+		{"it's", `'it'\''s'`},
+		{"daily-%d", `'daily-\%d'`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := crontabQuote(tt.arg); got != tt.want {
+			t.Errorf("crontabQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"oac-client/core/oac"
+)
+
+var showStats bool
+
+// collectedStats holds the stats of every request made by this invocation
+var collectedStats struct {
+	sync.Mutex
+	list []oac.RequestStats
+}
+
+// collectStats is the oac.StatsFunc used with --stats
+func collectStats(st oac.RequestStats) {
+	collectedStats.Lock()
+	defer collectedStats.Unlock()
+	collectedStats.list = append(collectedStats.list, st)
+}
+
+// printStats writes a timing line per request and a summary to w
+func printStats(w io.Writer) {
+	collectedStats.Lock()
+	defer collectedStats.Unlock()
+	if len(collectedStats.list) == 0 {
+		return
+	}
+
+	var total time.Duration
+	var sent, received int64
+	retries := 0
+	fmt.Fprintln(w, "--- stats")
+	for _, st := range collectedStats.list {
+		status := fmt.Sprint(st.Status)
+		if st.Err != nil {
+			status = "error"
+		}
+		retry := ""
+		if st.Retry {
+			retry = " (retry)"
+			retries++
+		}
+		fmt.Fprintf(w, "%s %s %s%s dns=%s connect=%s tls=%s ttfb=%s total=%s sent=%s received=%s\n",
+			st.Method, st.Path, status, retry, ms(st.DNS), ms(st.Connect), ms(st.TLS), ms(st.TTFB), ms(st.Total),
+			byteSize(st.RequestBytes), byteSize(st.ResponseBytes))
+		total += st.Total
+		sent += st.RequestBytes
+		received += st.ResponseBytes
+	}
+	fmt.Fprintf(w, "%d requests, %d retries, %s in requests, %s sent, %s received\n",
+		len(collectedStats.list), retries, ms(total), byteSize(sent), byteSize(received))
+}
+
+// ms rounds a duration for display
+func ms(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// byteSize formats a byte count with a binary unit
+func byteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&showStats, "stats", false, "print timing (DNS, connect, TLS, TTFB, total), sizes and retries of each request to stderr")
+}
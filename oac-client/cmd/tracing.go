@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// finishTracing ends the command span and flushes exported spans
+var finishTracing = func(error) {}
+
+// tracingEnabled reports whether the OTEL_* environment asks for OTLP trace export
+func tracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "otlp":
+		return true
+	case "", "none":
+	default:
+		slog.Warn("unsupported OTEL_TRACES_EXPORTER, only otlp is available", "value", os.Getenv("OTEL_TRACES_EXPORTER"))
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// setupTracing installs an OTLP/HTTP tracer provider configured from the standard OTEL_*
+// variables and starts the command span, continuing the trace in TRACEPARENT when set
+func setupTracing(cmd *cobra.Command) {
+	if !tracingEnabled() {
+		return
+	}
+
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		slog.Warn("tracing disabled", "error", err)
+		return
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "oac-client")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		slog.Warn("incomplete trace resource", "error", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// CI systems and job runners pass the parent trace in the environment
+	parent := otel.GetTextMapPropagator().Extract(commandCtx, propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+		"baggage":     os.Getenv("BAGGAGE"),
+	})
+
+	var span trace.Span
+	commandCtx, span = otel.Tracer("oac-client/cmd").Start(parent, cmd.CommandPath())
+	finishTracing = func(err error) {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}
+}
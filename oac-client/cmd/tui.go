@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"oac-client/core/oac"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// tuiCmd opens the interactive catalog browser
+var tuiCmd = &cobra.Command{
+	Use:   "tui [folder]",
+	Short: "Browse the catalog and work requests in a terminal UI",
+	Long: `Browse the catalog and work requests in a terminal UI.
+
+Keys:
+  ↑/↓ or k/j     move              enter or →/l   open folder or show details
+  ←/h, backspace parent folder      e              export the item archive to the current directory
+  r              refresh            tab            switch between catalog and work requests
+  q, ctrl+c      quit
+
+The work requests view refreshes every 5 seconds.`,
+	Example: `  oac-client tui /shared/Finance`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+			return fmt.Errorf("tui needs an interactive terminal")
+		}
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		folder := "/shared"
+		if len(args) > 0 {
+			folder = "/" + strings.Trim(args[0], "/")
+		}
+		_, err = tea.NewProgram(&tuiModel{client: client, folder: folder}, tea.WithAltScreen()).Run()
+		return err
+	},
+}
+
+// jobsInterval is how often the work requests view is refreshed
+const jobsInterval = 5 * time.Second
+
+// tuiModel is the state of the catalog browser
+type tuiModel struct {
+	client *oac.OacClient
+	width  int
+	height int
+
+	folder  string
+	items   []oac.CatalogItem
+	cursor  int
+	offset  int
+	detail  *oac.CatalogItem
+	loading bool
+
+	showJobs bool
+	jobs     []oac.WorkRequest
+	jobsAt   time.Time
+
+	status string
+}
+
+type folderMsg struct {
+	folder string
+	items  []oac.CatalogItem
+	err    error
+}
+
+type jobsMsg struct {
+	jobs []oac.WorkRequest
+	err  error
+}
+
+type exportMsg struct {
+	file string
+	err  error
+}
+
+type jobsTickMsg struct{}
+
+func (m *tuiModel) Init() tea.Cmd {
+	m.loading = true
+	return tea.Batch(m.loadFolder(m.folder), m.loadJobs())
+}
+
+func (m *tuiModel) loadFolder(folder string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		items, err := client.ListFolder(folder)
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].IsFolder() != items[j].IsFolder() {
+				return items[i].IsFolder()
+			}
+			return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+		})
+		return folderMsg{folder: folder, items: items, err: err}
+	}
+}
+
+func (m *tuiModel) loadJobs() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		jobs, err := client.ListWorkRequests()
+		return jobsMsg{jobs: jobs, err: err}
+	}
+}
+
+func (m *tuiModel) exportItem(item oac.CatalogItem) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		archive, err := client.ExportItem(item.Type, item.FullPath())
+		if err != nil {
+			return exportMsg{err: err}
+		}
+		file := item.Name + ".catalog"
+		return exportMsg{file: file, err: os.WriteFile(file, archive, 0o644)}
+	}
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case folderMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		m.folder, m.items, m.cursor, m.offset, m.detail = msg.folder, msg.items, 0, 0, nil
+		m.status = fmt.Sprintf("%d items", len(msg.items))
+
+	case jobsMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.jobs, m.jobsAt = msg.jobs, time.Now()
+		}
+		return m, tea.Tick(jobsInterval, func(time.Time) tea.Msg { return jobsTickMsg{} })
+
+	case jobsTickMsg:
+		return m, m.loadJobs()
+
+	case exportMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.status = "Exported to " + msg.file
+		}
+
+	case tea.KeyMsg:
+		return m.key(msg)
+	}
+	return m, nil
+}
+
+// key handles a key press
+func (m *tuiModel) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		m.showJobs = !m.showJobs
+		return m, nil
+	case "r":
+		if m.showJobs {
+			return m, m.loadJobs()
+		}
+		m.loading = true
+		return m, m.loadFolder(m.folder)
+	}
+	if m.showJobs || m.loading {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.detail = nil
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		m.detail = nil
+	case "enter", "right", "l":
+		if len(m.items) == 0 {
+			break
+		}
+		item := m.items[m.cursor]
+		if item.IsFolder() {
+			m.loading = true
+			return m, m.loadFolder(item.FullPath())
+		}
+		m.detail = &item
+	case "left", "h", "backspace":
+		if m.detail != nil {
+			m.detail = nil
+			break
+		}
+		if parent := path.Dir(m.folder); parent != m.folder {
+			m.loading = true
+			return m, m.loadFolder(parent)
+		}
+	case "e":
+		if len(m.items) == 0 || m.items[m.cursor].IsFolder() {
+			break
+		}
+		m.status = "Exporting " + m.items[m.cursor].Name + "..."
+		return m, m.exportItem(m.items[m.cursor])
+	}
+	return m, nil
+}
+
+func (m *tuiModel) View() string {
+	var b strings.Builder
+	if m.showJobs {
+		m.viewJobs(&b)
+	} else {
+		m.viewCatalog(&b)
+	}
+
+	// keep the status line at the bottom of the screen
+	lines := strings.Count(b.String(), "\n")
+	for i := lines; i < m.height-2; i++ {
+		b.WriteByte('\n')
+	}
+	status := m.status
+	if m.loading {
+		status = "Loading..."
+	}
+	keys := "  ·  tab: work requests  e: export  r: refresh  q: quit"
+	if m.showJobs {
+		keys = "  ·  tab: catalog  r: refresh  q: quit"
+	}
+	fmt.Fprintf(&b, "\n\x1b[7m %-*s\x1b[0m", max(m.width-1, 0), truncate(status+keys, m.width-1))
+	return b.String()
+}
+
+// viewCatalog draws the folder listing and, when selected, the item details
+func (m *tuiModel) viewCatalog(b *strings.Builder) {
+	fmt.Fprintf(b, "\x1b[1m%s\x1b[0m\n\n", m.folder)
+
+	rows := max(m.height-5, 1)
+	if m.detail != nil {
+		rows = max(rows-9, 1)
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+
+	for i := m.offset; i < len(m.items) && i < m.offset+rows; i++ {
+		item := m.items[i]
+		name := item.Name
+		if item.IsFolder() {
+			name = "▸ " + name + "/"
+		} else {
+			name = "  " + name
+		}
+		line := fmt.Sprintf("%-50s %-12s %s", truncate(name, 50), item.Type, item.LastModified)
+		if i == m.cursor {
+			fmt.Fprintf(b, "\x1b[7m%s\x1b[0m\n", truncate(line, m.width))
+		} else {
+			fmt.Fprintln(b, truncate(line, m.width))
+		}
+	}
+
+	if d := m.detail; d != nil {
+		fmt.Fprintf(b, "\n\x1b[1m%s\x1b[0m\n", d.Name)
+		for _, f := range [][2]string{
+			{"Type", d.Type}, {"Path", d.FullPath()}, {"Owner", d.Owner},
+			{"Modified", d.LastModified}, {"Description", d.Description}, {"ID", d.ID},
+		} {
+			if f[1] != "" {
+				fmt.Fprintf(b, "  %-12s %s\n", f[0], truncate(f[1], m.width-15))
+			}
+		}
+	}
+}
+
+// viewJobs draws the recent work requests
+func (m *tuiModel) viewJobs(b *strings.Builder) {
+	fmt.Fprintf(b, "\x1b[1mWork requests\x1b[0m")
+	if !m.jobsAt.IsZero() {
+		fmt.Fprintf(b, "  (updated %s)", m.jobsAt.Format("15:04:05"))
+	}
+	b.WriteString("\n\n")
+
+	for i, wr := range m.jobs {
+		if i >= m.height-5 {
+			break
+		}
+		status := wr.Status
+		switch status {
+		case "SUCCEEDED":
+			status = "\x1b[32m" + status + "\x1b[0m"
+		case "FAILED", "CANCELED":
+			status = "\x1b[31m" + status + "\x1b[0m"
+		}
+		fmt.Fprintf(b, "%-40s %-24s %s %3.0f%%\n", truncate(wr.ID, 40), wr.OperationType, status, wr.PercentComplete)
+	}
+	if len(m.jobs) == 0 {
+		b.WriteString("No work requests\n")
+	}
+}
+
+// truncate shortens s to n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"oac-client/core/oac"
+)
+
+var (
+	watchInterval time.Duration
+	watchUntil    string
+)
+
+// defaultWatchInterval is the polling interval of --until without --watch
+const defaultWatchInterval = 5 * time.Second
+
+// watchCall repeats a GET every --watch interval, redrawing the response and listing
+// what changed since the previous one, until the --until condition holds or the user
+// interrupts it
+func watchCall(method, path string) error {
+	if method != "GET" {
+		return fmt.Errorf("--watch and --until only repeat GET requests")
+	}
+	interval := watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	var until *oac.Condition
+	if watchUntil != "" {
+		var err error
+		if until, err = oac.CompileCondition(watchUntil); err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAC client: %w", err)
+	}
+	header, err := parseHeaders(headerFlags)
+	if err != nil {
+		return err
+	}
+	query, err := parseQuery(queryFlags)
+	if err != nil {
+		return err
+	}
+
+	ctx := commandCtx
+	terminal := isTerminal(os.Stdout)
+	color := useColor(os.Stdout)
+
+	var previous []byte
+	var previousAt time.Time
+	for {
+		resp, err := client.Do(&oac.Request{Method: method, Path: path, Query: query, Header: header.Clone()})
+		if err != nil {
+			return fmt.Errorf("error executing REST call: %w", err)
+		}
+		now := time.Now()
+
+		body := resp.Bytes()
+		if len(selectFields) > 0 {
+			if selected, err := oac.SelectFields(body, selectFields); err == nil {
+				body = selected
+			}
+		}
+		var changes []oac.JSONChange
+		if previous != nil {
+			changes, _ = oac.DiffJSON(previous, body)
+		}
+
+		if terminal {
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+		if !quiet {
+			fmt.Printf("Every %s: GET %s    %s\n", interval, path, now.Format(time.TimeOnly))
+			if len(changes) > 0 {
+				fmt.Printf("Changed since %s:\n", previousAt.Format(time.TimeOnly))
+				for _, ch := range changes {
+					line := "  " + ch.String()
+					if color {
+						line = diffColors[ch.Op] + line + "\x1b[0m"
+					}
+					fmt.Println(line)
+				}
+			}
+			fmt.Println()
+		}
+		if err := printJSON(body); err != nil {
+			return err
+		}
+		previous, previousAt = body, now
+
+		if until != nil {
+			done, err := until.Match(resp.Bytes())
+			if err != nil {
+				return err
+			}
+			if done {
+				infof("Condition %s met", until)
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.DurationVar(&watchInterval, "watch", 0, "repeat a GET at this interval, showing what changed, e.g. 10s")
+	flags.StringVar(&watchUntil, "until", "", "with --watch, stop once this JMESPath condition holds, e.g. \"status=='SUCCEEDED'\"")
+}
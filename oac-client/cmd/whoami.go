@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// whoamiCmd shows the identity and instance a command would run against
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the current user, tenant, scopes, roles and instance",
+	Long: `Show the current user, tenant, scopes, roles and instance.
+
+The IDCS user-info endpoint is asked for the user's name and roles (override
+its URL with IDCS_USERINFO_URL); when it is unavailable, e.g. for
+client_credentials tokens, only the token claims are shown.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+
+		id, err := client.WhoAmI()
+		if err != nil {
+			return err
+		}
+
+		profile := id.Profile
+		if profile == "" {
+			profile = "(environment)"
+		}
+		fmt.Printf("Profile:       %s\n", profile)
+		fmt.Printf("Instance:      %s\n", id.Instance)
+		fmt.Printf("User:          %s\n", id.User)
+		if id.DisplayName != "" {
+			fmt.Printf("Display name:  %s\n", id.DisplayName)
+		}
+		if id.Email != "" {
+			fmt.Printf("Email:         %s\n", id.Email)
+		}
+		if id.ClientID != "" {
+			fmt.Printf("Client ID:     %s\n", id.ClientID)
+		}
+		if id.Tenant != "" {
+			fmt.Printf("Tenant:        %s\n", id.Tenant)
+		}
+		if id.Actor != "" {
+			fmt.Printf("Actor:         %s\n", id.Actor)
+		}
+		fmt.Printf("Scopes:        %s\n", strings.Join(id.Scopes, " "))
+		if id.FromUserInfo {
+			fmt.Printf("Roles:         %s\n", strings.Join(id.Roles, ", "))
+		} else {
+			fmt.Println("Roles:         unavailable (no user-info for this token)")
+		}
+		fmt.Printf("Token expires: %s\n", id.ExpiresAt.Format(time.RFC3339))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"oac-client/core/oac"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workbookFormat  string
+	workbookOut     string
+	workbookFromDir string
+)
+
+// workbookCmd groups workbook archive commands
+var workbookCmd = &cobra.Command{
+	Use:     "workbook",
+	Aliases: []string{"workbooks"},
+	Short:   "Export and import workbook archives",
+}
+
+var workbookExportCmd = &cobra.Command{
+	Use:   "export <catalog path>",
+	Short: "Export a workbook as a .dva archive or an expanded directory",
+	Long: `Export a workbook as a .dva archive, or with --format expanded as a directory
+of its files: JSON files are pretty-printed with sorted keys, so exporting an
+unchanged workbook gives identical files and a changed one a readable diff, e.g.
+in a pull request. Re-exporting into the same directory removes files the
+workbook no longer has. workbook import --from-dir uploads the directory.
+
+--out defaults to the workbook name, with .dva for archives.`,
+	Example: `  oac-client workbook export /shared/Finance/Revenue
+  oac-client workbook export /shared/Finance/Revenue --format expanded --out workbooks/revenue`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if workbookFormat != "dva" && workbookFormat != "expanded" {
+			return fmt.Errorf("invalid --format %q, expected dva or expanded", workbookFormat)
+		}
+		out := workbookOut
+		if out == "" {
+			out = path.Base(args[0])
+			if workbookFormat == "dva" {
+				out += ".dva"
+			}
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		archive, err := client.ExportItem("workbooks", args[0])
+		if err != nil {
+			return err
+		}
+
+		if workbookFormat == "expanded" {
+			if err := oac.ExpandArchive("workbooks", args[0], archive, out); err != nil {
+				return err
+			}
+		} else if err := os.WriteFile(out, archive, 0o644); err != nil {
+			return err
+		}
+		infof("Saved %s", out)
+		return nil
+	},
+}
+
+var workbookImportCmd = &cobra.Command{
+	Use:   "import <catalog path> <file.dva> | --from-dir <dir> [catalog path]",
+	Short: "Import a workbook from a .dva archive or an expanded directory",
+	Long: `Import a workbook from a .dva archive, or with --from-dir from a directory
+written by workbook export --format expanded. The directory remembers the
+catalog path it was exported from, which is used unless another is given.`,
+	Example: `  oac-client workbook import /shared/Finance/Revenue revenue.dva
+  oac-client --profile prod workbook import --from-dir workbooks/revenue`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if workbookFromDir != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var archive []byte
+		var catalogPath string
+		itemType := "workbooks"
+		if workbookFromDir != "" {
+			var err error
+			if archive, itemType, catalogPath, err = oac.PackExpanded(workbookFromDir); err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				catalogPath = args[0]
+			}
+		} else {
+			var err error
+			if archive, err = os.ReadFile(args[1]); err != nil {
+				return err
+			}
+			catalogPath = args[0]
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create OAC client: %w", err)
+		}
+		if err := client.ImportItem(itemType, catalogPath, archive); err != nil {
+			return err
+		}
+		infof("Imported %s", catalogPath)
+		return nil
+	},
+}
+
+func init() {
+	workbookExportCmd.Flags().StringVar(&workbookFormat, "format", "dva", "dva archive or expanded directory")
+	workbookExportCmd.Flags().StringVarP(&workbookOut, "out", "o", "", "file or directory to write (default the workbook name)")
+	workbookImportCmd.Flags().StringVar(&workbookFromDir, "from-dir", "", "directory written by workbook export --format expanded")
+
+	workbookCmd.AddCommand(workbookExportCmd, workbookImportCmd)
+	rootCmd.AddCommand(workbookCmd)
+}
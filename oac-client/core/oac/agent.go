@@ -0,0 +1,67 @@
+package oac
+
+import (
+	"net/url"
+	"strings"
+)
+
+// agentsPath is the endpoint for agents, the scheduled deliveries of the catalog
+const agentsPath = apiBase + "/agents"
+
+// Agent is a scheduled delivery
+type Agent struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+	Enabled  bool   `json:"enabled"`
+	Schedule string `json:"schedule,omitempty"`
+	LastRun  string `json:"lastRun,omitempty"`
+	NextRun  string `json:"nextRun,omitempty"`
+	// LastRunStatus is the outcome of the last delivery, e.g. SUCCEEDED or FAILED
+	LastRunStatus string `json:"lastRunStatus,omitempty"`
+}
+
+// agentPath returns the endpoint of an agent given its id or catalog path
+func agentPath(idOrPath string) string {
+	id := idOrPath
+	if strings.HasPrefix(idOrPath, "/") {
+		id = EncodeCatalogID(idOrPath)
+	}
+	return agentsPath + "/" + url.PathEscape(id)
+}
+
+// ListAgents returns the agents visible to the caller
+func (c *OacClient) ListAgents() ([]Agent, error) {
+	var page struct {
+		Items []Agent `json:"items"`
+	}
+	if err := c.callJSON("GET", agentsPath, nil, &page); err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// RunAgent starts a delivery of an agent now and returns the work request id when
+// one is reported
+func (c *OacClient) RunAgent(idOrPath string) (string, error) {
+	resp, err := c.exchange("POST", agentPath(idOrPath)+"/actions/run", "application/json", nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("oa-work-request-id"), nil
+}
+
+// SetAgentEnabled enables or disables the schedule of an agent
+func (c *OacClient) SetAgentEnabled(idOrPath string, enabled bool) error {
+	action := "/actions/disable"
+	if enabled {
+		action = "/actions/enable"
+	}
+	return c.callJSON("POST", agentPath(idOrPath)+action, nil, nil)
+}
+
+// SetAgentSchedule changes the schedule of an agent
+func (c *OacClient) SetAgentSchedule(idOrPath, schedule string) error {
+	return c.callJSON("PATCH", agentPath(idOrPath), map[string]string{"schedule": schedule}, nil)
+}
@@ -0,0 +1,61 @@
+package oac
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// aliasPlaceholder matches the {1}, {2}, ... placeholders of an alias
+var aliasPlaceholder = regexp.MustCompile(`\{(\d+)\}`)
+
+// ExpandAlias turns the request of an alias into command line arguments. Aliases are
+// defined in the config file:
+//
+//	aliases:
+//	  reports-list: GET /api/20210901/catalog?type=report
+//	  snapshot-get: GET /api/20210901/snapshots/{1}
+//
+// {1}, {2}, ... are replaced by the arguments given after the alias name; arguments not
+// used by a placeholder, such as flags, are appended.
+func ExpandAlias(name, command string, args []string) ([]string, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("alias %s is empty", name)
+	}
+
+	used := make([]bool, len(args))
+	var missing error
+	out := make([]string, 0, len(fields)+len(args))
+	for _, field := range fields {
+		out = append(out, aliasPlaceholder.ReplaceAllStringFunc(field, func(m string) string {
+			n, _ := strconv.Atoi(m[1 : len(m)-1])
+			if n < 1 || n > len(args) {
+				missing = fmt.Errorf("alias %s needs argument %d: %s", name, n, command)
+				return m
+			}
+			used[n-1] = true
+			return args[n-1]
+		}))
+	}
+	if missing != nil {
+		return nil, missing
+	}
+	for i, arg := range args {
+		if !used[i] {
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// AliasArgs returns the number of placeholder arguments an alias expects
+func AliasArgs(command string) int {
+	n := 0
+	for _, m := range aliasPlaceholder.FindAllStringSubmatch(command, -1) {
+		i, _ := strconv.Atoi(m[1])
+		n = max(n, i)
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+package oac
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultAPIVersion is the OAC REST API version used unless another one is selected
+const DefaultAPIVersion = "20210901"
+
+// WithAPIVersion selects the REST API version, overriding the profile's api_version
+// and OAC_API_VERSION
+func WithAPIVersion(version string) Option {
+	return func(c *OacClient) {
+		c.apiVersion = version
+	}
+}
+
+// APIVersion returns the selected REST API version: WithAPIVersion, the profile's
+// api_version, OAC_API_VERSION or DefaultAPIVersion
+func (c *OacClient) APIVersion() string {
+	switch {
+	case c.apiVersion != "":
+		return c.apiVersion
+	case c.profile != nil && c.profile.APIVersion != "":
+		return c.profile.APIVersion
+	}
+	if v := c.setting("OAC_API_VERSION"); v != "" {
+		return v
+	}
+	return DefaultAPIVersion
+}
+
+// validateAPIVersion checks that the selected version looks like 20210901
+func (c *OacClient) validateAPIVersion() error {
+	v := c.APIVersion()
+	if len(v) != 8 || strings.Trim(v, "0123456789") != "" {
+		return fmt.Errorf("invalid API version %q: expected a date such as %s", v, DefaultAPIVersion)
+	}
+	return nil
+}
+
+// APIBase returns the versioned prefix of the REST API, e.g. /api/20210901
+func (c *OacClient) APIBase() string {
+	return "/api/" + c.APIVersion()
+}
+
+// APIPath returns the request path of a resource of the selected API version with its
+// ids escaped, e.g. c.APIPath("snapshots", id) is /api/20210901/snapshots/<id>
+func (c *OacClient) APIPath(resource string, ids ...string) string {
+	p := c.APIBase() + "/" + strings.Trim(resource, "/")
+	for _, id := range ids {
+		p += "/" + url.PathEscape(id)
+	}
+	return p
+}
+
+// URL returns the absolute URL of a resource of the selected API version, see APIPath
+func (c *OacClient) URL(resource string, ids ...string) string {
+	return c.Instance() + c.APIPath(resource, ids...)
+}
+
+// resolvePath maps a request path onto the selected API version. Relative paths get the
+// profile's base path or the API prefix, and paths under the default version, as built
+// by this package and found in existing scripts, move to the selected one.
+func (c *OacClient) resolvePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		base := c.APIBase()
+		if c.profile != nil && c.profile.BasePath != "" {
+			base = strings.TrimRight(c.profile.BasePath, "/")
+		}
+		path = base + "/" + path
+	}
+	if c.APIVersion() == DefaultAPIVersion {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, apiBase); ok && (rest == "" || rest[0] == '/' || rest[0] == '?') {
+		return c.APIBase() + rest
+	}
+	return path
+}
@@ -0,0 +1,152 @@
+package oac
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// interactiveLoginTimeout bounds how long the browser and device flows wait for the user
+const interactiveLoginTimeout = 5 * time.Minute
+
+// idcsEndpoint builds the IDCS OAuth2 endpoints, deriving the authorize and device
+// URLs from the token URL unless IDCS_AUTHORIZE_URL / IDCS_DEVICE_URL are set
+func (c *OacClient) idcsEndpoint(tokenURL string) oauth2.Endpoint {
+	base := strings.TrimSuffix(tokenURL, "/token")
+
+	authURL := c.setting("IDCS_AUTHORIZE_URL")
+	if authURL == "" {
+		authURL = base + "/authorize"
+	}
+	deviceURL := c.setting("IDCS_DEVICE_URL")
+	if deviceURL == "" {
+		deviceURL = base + "/device"
+	}
+
+	return oauth2.Endpoint{
+		TokenURL:      tokenURL,
+		AuthURL:       authURL,
+		DeviceAuthURL: deviceURL,
+	}
+}
+
+// authCodeToken runs the authorization code flow with PKCE: it opens the browser on the
+// IDCS login page and receives the code on a localhost callback port (default 8765)
+func authCodeToken(ctx context.Context, cfg *oauth2.Config, port string) (*oauth2.Token, error) {
+	if port == "" {
+		port = "8765"
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start login callback listener: %w", err)
+	}
+	defer listener.Close()
+
+	cfg.RedirectURL = fmt.Sprintf("http://localhost:%s/callback", port)
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+
+			q := r.URL.Query()
+			switch {
+			case q.Get("state") != state:
+				http.Error(w, "invalid state", http.StatusBadRequest)
+				results <- result{err: fmt.Errorf("login callback state mismatch")}
+			case q.Get("error") != "":
+				http.Error(w, "login failed", http.StatusBadRequest)
+				results <- result{err: fmt.Errorf("login failed: %s %s", q.Get("error"), q.Get("error_description"))}
+			default:
+				fmt.Fprintln(w, "Login complete, you can close this window.")
+				results <- result{code: q.Get("code")}
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+	fmt.Fprintf(os.Stderr, "Opening the browser to sign in. If it does not open, visit:\n  %s\n", authURL)
+	_ = openBrowser(authURL)
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return cfg.Exchange(ctx, res.code, oauth2.VerifierOption(verifier))
+	case <-time.After(interactiveLoginTimeout):
+		return nil, &TimeoutError{Op: "browser login", Err: fmt.Errorf("no callback after %s", interactiveLoginTimeout)}
+	}
+}
+
+// deviceCodeToken runs the device authorization flow for machines without a browser
+func deviceCodeToken(ctx context.Context, cfg *oauth2.Config) (*oauth2.Token, error) {
+	auth, err := cfg.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	if auth.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "To sign in, visit:\n  %s\n", auth.VerificationURIComplete)
+	} else {
+		fmt.Fprintf(os.Stderr, "To sign in, visit %s and enter the code: %s\n", auth.VerificationURI, auth.UserCode)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, interactiveLoginTimeout)
+	defer cancel()
+
+	token, err := cfg.DeviceAccessToken(ctx, auth)
+	if err != nil {
+		return nil, asTimeout("device login", err)
+	}
+	return token, nil
+}
+
+// randomState returns an unguessable OAuth2 state value
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// openBrowser opens url with the platform's default handler
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,180 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchRequest is a single REST call in a batch file
+type BatchRequest struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// IdempotencyKey overrides the key derived for POSTs, see BatchOptions.IdempotencyKeys
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// BatchResult is the outcome of one BatchRequest
+type BatchResult struct {
+	ID             string `json:"id"`
+	Method         string `json:"method"`
+	Path           string `json:"path"`
+	OK             bool   `json:"ok"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Response       string `json:"response,omitempty"`
+	Error          string `json:"error,omitempty"`
+	Duration       int64  `json:"durationMs"`
+}
+
+// BatchOptions controls parallelism of RunBatch.
+// Throttling is done by the client's rate limiter, see WithRateLimit.
+type BatchOptions struct {
+	Concurrency int
+	// IdempotencyKeys sends an Idempotency-Key header with every POST, so the server can
+	// drop duplicates when a request is retried or a batch file is run again
+	IdempotencyKeys bool
+}
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a POST
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReadBatchRequests parses newline-delimited JSON requests, skipping blank lines
+func ReadBatchRequests(r io.Reader) ([]BatchRequest, error) {
+	var reqs []BatchRequest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var req BatchRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if req.Method == "" || req.Path == "" {
+			return nil, fmt.Errorf("line %d: method and path are required", line)
+		}
+		if req.ID == "" {
+			req.ID = fmt.Sprintf("%d", line)
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, scanner.Err()
+}
+
+// bodyArg converts a batch body into the argument ReadBody expects.
+// A JSON string is a file path or literal, anything else is sent as-is.
+func (r BatchRequest) bodyArg() string {
+	if len(r.Body) == 0 || string(r.Body) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(r.Body, &s); err == nil {
+		return s
+	}
+	return string(r.Body)
+}
+
+// RunBatch executes requests with a worker pool and returns results in input order
+func (c *OacClient) RunBatch(ctx context.Context, reqs []BatchRequest, opts BatchOptions) ([]BatchResult, error) {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	// obtain the token up front so workers don't race to refresh it
+	if _, err := c.GetToken(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = BatchResult{ID: req.ID, Method: strings.ToUpper(req.Method), Path: req.Path, Error: "not executed"}
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.runBatchRequest(reqs[i], opts)
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for i := range reqs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, err
+}
+
+// idempotencyKey returns the request's own key, or one derived from its id, method, path
+// and body, so running the same batch file again reuses the keys
+func (r BatchRequest) idempotencyKey(body []byte) string {
+	if r.IdempotencyKey != "" {
+		return r.IdempotencyKey
+	}
+	h := sha256.New()
+	for _, part := range []string{r.ID, strings.ToUpper(r.Method), r.Path} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// runBatchRequest performs one request and records its result
+func (c *OacClient) runBatchRequest(req BatchRequest, opts BatchOptions) BatchResult {
+	result := BatchResult{ID: req.ID, Method: strings.ToUpper(req.Method), Path: req.Path}
+
+	body, err := ReadBody(req.bodyArg())
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	r := &Request{Method: req.Method, Path: req.Path, Body: bytes.NewReader(body)}
+	if opts.IdempotencyKeys && result.Method == "POST" {
+		result.IdempotencyKey = req.idempotencyKey(body)
+		r.Header = http.Header{IdempotencyKeyHeader: {result.IdempotencyKey}}
+	}
+
+	start := time.Now()
+	resp, err := c.Call(r)
+	result.Duration = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.OK = true
+	result.Response = resp
+	return result
+}
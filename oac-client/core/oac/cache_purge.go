@@ -0,0 +1,62 @@
+package oac
+
+import "fmt"
+
+// cachePurgePath is the action purging the query cache of the instance
+const cachePurgePath = apiBase + "/cache/actions/purge"
+
+// cacheStatsPath reports the usage of the query cache
+const cacheStatsPath = apiBase + "/cache/stats"
+
+// CacheStats is the usage of the query cache since the instance started
+type CacheStats struct {
+	Entries int64 `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// HitRatio returns the share of lookups answered from the cache, 0 without lookups
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// GetCacheStats returns the usage of the query cache
+func (c *OacClient) GetCacheStats() (*CacheStats, error) {
+	var stats CacheStats
+	if err := c.callJSON("GET", cacheStatsPath, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CachePurge selects the query cache entries to purge: everything, the entries of a
+// database, or those of a table (optionally within Database)
+type CachePurge struct {
+	All      bool   `json:"all,omitempty"`
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+}
+
+// PurgeCache purges the OAC query cache and returns the work request id when the
+// purge runs asynchronously, or "" when it completed
+func (c *OacClient) PurgeCache(p CachePurge) (string, error) {
+	if !p.All && p.Database == "" && p.Table == "" {
+		return "", fmt.Errorf("nothing to purge: select all entries, a database or a table")
+	}
+	if p.All && (p.Database != "" || p.Table != "") {
+		return "", fmt.Errorf("purging all entries cannot be combined with a database or table")
+	}
+
+	b, err := marshalBody(p)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.exchange("POST", cachePurgePath, "application/json", b)
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("oa-work-request-id"), nil
+}
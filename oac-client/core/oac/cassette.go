@@ -0,0 +1,225 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Interaction is one recorded request and its response. Request headers are not
+// recorded, so credentials never end up in a cassette.
+type Interaction struct {
+	Method      string      `json:"method"`
+	URI         string      `json:"uri"`
+	RequestBody string      `json:"requestBody,omitempty"`
+	StatusCode  int         `json:"statusCode"`
+	Status      string      `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body,omitempty"`
+	// Base64 is set when the bodies are not UTF-8 text and are stored base64 encoded
+	Base64 bool `json:"base64,omitempty"`
+}
+
+// cassetteFile is the on-disk form of a cassette
+type cassetteFile struct {
+	Recorded     time.Time     `json:"recorded"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// cassette records API calls to a file or replays them from it
+type cassette struct {
+	file   string
+	replay bool
+	base   http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	// end is the offset in the recorded file after the last interaction, 0 before the first
+	end int64
+}
+
+// WithRecorder records every authenticated API call with its response to the cassette
+// file, overwriting it. Token requests are not recorded.
+func WithRecorder(file string) Option {
+	return func(c *OacClient) {
+		c.cassette = &cassette{file: file}
+	}
+}
+
+// WithReplay answers API calls from a cassette written by WithRecorder instead of the
+// network. No token is requested, so replay works without credentials.
+func WithReplay(file string) Option {
+	return func(c *OacClient) {
+		c.cassette = &cassette{file: file, replay: true}
+	}
+}
+
+// load reads the interactions of a cassette for replay
+func (k *cassette) load() error {
+	data, err := os.ReadFile(k.file)
+	if err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var f cassetteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("invalid cassette %s: %w", k.file, err)
+	}
+	k.interactions = f.Interactions
+	k.used = make([]bool, len(f.Interactions))
+	return nil
+}
+
+func (k *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if k.replay {
+		return k.play(req, body)
+	}
+
+	resp, err := k.base.RoundTrip(req)
+	if err != nil || !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+		return resp, err
+	}
+
+	resBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	in := Interaction{
+		Method:     req.Method,
+		URI:        req.URL.RequestURI(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header.Clone(),
+	}
+	in.Base64 = !utf8.Valid(body) || !utf8.Valid(resBody)
+	in.RequestBody = encodeCassetteBody(body, in.Base64)
+	in.Body = encodeCassetteBody(resBody, in.Base64)
+	if err := k.append(in); err != nil {
+		slog.Warn("failed to record interaction", "file", k.file, "error", err)
+	}
+	return resp, nil
+}
+
+// play returns the first unused interaction matching the request's method, URI and body
+func (k *cassette) play(req *http.Request, body []byte) (*http.Response, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	uri := req.URL.RequestURI()
+	for i, in := range k.interactions {
+		if k.used[i] || in.Method != req.Method || in.URI != uri ||
+			in.RequestBody != encodeCassetteBody(body, in.Base64) {
+			continue
+		}
+		k.used[i] = true
+
+		resBody, err := decodeCassetteBody(in.Body, in.Base64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cassette body for %s %s: %w", in.Method, in.URI, err)
+		}
+		return &http.Response{
+			StatusCode:    in.StatusCode,
+			Status:        in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(resBody)),
+			ContentLength: int64(len(resBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s in %s", req.Method, uri, k.file)
+}
+
+// cassetteTrailer closes the interactions array and the document of a cassette file
+const cassetteTrailer = "\n  ]\n}\n"
+
+// append adds a recorded interaction to the cassette file. The file is written once
+// with its header and then only extended, writing each interaction over the trailer
+// and the trailer after it, so it is a complete cassette after every call.
+func (k *cassette) append(in Interaction) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	data, err := json.MarshalIndent(in, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	entry := append([]byte("    "), data...)
+
+	flags := os.O_WRONLY
+	if k.end == 0 {
+		recorded, err := json.Marshal(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		header := []byte("{\n  \"recorded\": " + string(recorded) + ",\n  \"interactions\": [\n")
+		entry = append(header, entry...)
+		flags |= os.O_CREATE | os.O_TRUNC
+		if dir := filepath.Dir(k.file); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+	} else {
+		entry = append([]byte(",\n"), entry...)
+	}
+
+	f, err := os.OpenFile(k.file, flags, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteAt(append(entry, cassetteTrailer...), k.end); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	k.end += int64(len(entry))
+	return nil
+}
+
+// readRequestBody reads the request body and returns a copy of req with an unread body
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return req, body, nil
+}
+
+func encodeCassetteBody(b []byte, b64 bool) string {
+	if b64 {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return string(b)
+}
+
+func decodeCassetteBody(s string, b64 bool) ([]byte, error) {
+	if b64 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
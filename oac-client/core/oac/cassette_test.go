@@ -0,0 +1,68 @@
+package oac_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oac-client/core/oac"
+	"oac-client/core/oac/oactest"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	srv := oactest.NewServer()
+	defer srv.Close()
+	srv.AddSnapshot(oac.Snapshot{Name: "nightly"})
+	srv.AddSnapshot(oac.Snapshot{Name: "weekly"})
+	for key := range srv.Profile().Env {
+		t.Setenv(key, "")
+	}
+	file := filepath.Join(t.TempDir(), "cassettes", "snapshots.json")
+
+	recorder, err := srv.Client(oac.WithTokenStore(oac.NewMemoryTokenStore()), oac.WithRecorder(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, snap := range srv.Snapshots() {
+		got, err := recorder.GetSnapshot(snap.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, got.ID)
+
+		// the cassette is complete after every recorded call
+		var doc struct {
+			Interactions []json.RawMessage `json:"interactions"`
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("cassette after %d calls: %v", len(ids), err)
+		}
+		if len(doc.Interactions) != len(ids) {
+			t.Fatalf("cassette holds %d interactions, want %d", len(doc.Interactions), len(ids))
+		}
+	}
+
+	player, err := srv.Client(oac.WithReplay(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := len(srv.Requests())
+	for _, id := range ids {
+		got, err := player.GetSnapshot(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != id {
+			t.Errorf("replayed snapshot %q, want %q", got.ID, id)
+		}
+	}
+	if n := len(srv.Requests()); n != calls {
+		t.Errorf("replay sent %d requests to the server", n-calls)
+	}
+}
@@ -0,0 +1,117 @@
+package oac
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// apiBase is the prefix of the OAC REST API paths built by this package; requests move
+// them to the selected version, see OacClient.resolvePath
+const apiBase = "/api/20210901"
+
+// CatalogItem is an entry returned by the catalog endpoints
+type CatalogItem struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	Path         string `json:"path,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Owner        string `json:"owner,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsFolder reports whether the item can contain other items
+func (item CatalogItem) IsFolder() bool {
+	return item.Type == "folder" || item.Type == "folders"
+}
+
+// FullPath returns the catalog path, decoding it from the id when not returned
+func (item CatalogItem) FullPath() string {
+	if item.Path != "" {
+		return item.Path
+	}
+	if p, err := DecodeCatalogID(item.ID); err == nil {
+		return p
+	}
+	return item.Name
+}
+
+// EncodeCatalogID converts a catalog path such as /shared/Finance into an API id
+func EncodeCatalogID(catalogPath string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(catalogPath))
+}
+
+// DecodeCatalogID converts an API id back into a catalog path
+func DecodeCatalogID(id string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(id, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid catalog id %q: %w", id, err)
+	}
+	return string(b), nil
+}
+
+// ListFolder returns the direct children of a catalog folder
+func (c *OacClient) ListFolder(folder string) ([]CatalogItem, error) {
+	folder = "/" + strings.Trim(folder, "/")
+
+	var items []CatalogItem
+	endpoint := fmt.Sprintf("%s/catalog/folders/%s/items", apiBase, EncodeCatalogID(folder))
+	if err := c.callJSON("GET", endpoint, nil, &items); err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		if items[i].Path == "" {
+			items[i].Path = path.Join(folder, items[i].Name)
+		}
+	}
+	return items, nil
+}
+
+// SearchCatalog finds items by type (empty for all types) and search pattern (* wildcards)
+func (c *OacClient) SearchCatalog(itemType, search string) ([]CatalogItem, error) {
+	if search == "" {
+		search = "*"
+	}
+
+	query := url.Values{}
+	query.Set("search", search)
+
+	endpoint := apiBase + "/catalog"
+	if itemType != "" {
+		endpoint += "/" + url.PathEscape(itemType)
+	}
+
+	var items []CatalogItem
+	if err := c.callJSON("GET", endpoint+"?"+query.Encode(), nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// WalkCatalog visits every item under root depth-first; depth is 0 for direct children
+func (c *OacClient) WalkCatalog(root string, fn func(item CatalogItem, depth int) error) error {
+	return c.walkCatalog(root, 0, fn)
+}
+
+func (c *OacClient) walkCatalog(folder string, depth int, fn func(CatalogItem, int) error) error {
+	items, err := c.ListFolder(folder)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", folder, err)
+	}
+
+	for _, item := range items {
+		if err := fn(item, depth); err != nil {
+			return err
+		}
+		if item.IsFolder() {
+			if err := c.walkCatalog(item.FullPath(), depth+1, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
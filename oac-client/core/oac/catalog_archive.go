@@ -0,0 +1,212 @@
+package oac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	archiveExt  = ".catalog"
+	itemMetaExt = ".item.json"
+)
+
+// archivedItem is the metadata written next to each exported archive
+type archivedItem struct {
+	Type string          `json:"type"`
+	Name string          `json:"name"`
+	Path string          `json:"path"`
+	ACL  json.RawMessage `json:"acl,omitempty"`
+}
+
+// itemEndpoint returns the API path of a catalog item
+func itemEndpoint(itemType, catalogPath string) string {
+	return fmt.Sprintf("%s/catalog/%s/%s", apiBase, url.PathEscape(itemType), EncodeCatalogID(catalogPath))
+}
+
+// ExportCatalog writes an archive, its checksum and an ACL file for every item under
+// root into outDir, mirroring the folder structure. It returns the catalog paths exported.
+func (c *OacClient) ExportCatalog(root, outDir string) ([]string, error) {
+	root = "/" + strings.Trim(root, "/")
+	var exported []string
+
+	err := c.WalkCatalog(root, func(item CatalogItem, depth int) error {
+		itemPath := item.FullPath()
+		rel := strings.TrimPrefix(strings.TrimPrefix(itemPath, root), "/")
+		target := filepath.Join(outDir, filepath.FromSlash(rel))
+
+		if item.IsFolder() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		if _, err := c.archiveItem(item, target); err != nil {
+			return err
+		}
+
+		exported = append(exported, itemPath)
+		return nil
+	})
+
+	return exported, err
+}
+
+// archiveItem writes the archive of item to target plus the archive extension, with
+// its checksum and its metadata and ACL next to it, and returns the checksum
+func (c *OacClient) archiveItem(item CatalogItem, target string) (string, error) {
+	itemPath := item.FullPath()
+	archive, err := c.ExportItem(item.Type, itemPath)
+	if err != nil {
+		return "", err
+	}
+
+	acl, err := c.send("POST", itemEndpoint(item.Type, itemPath)+"/actions/getACL", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read ACL of %s: %w", itemPath, err)
+	}
+
+	meta, err := json.MarshalIndent(archivedItem{Type: item.Type, Name: item.Name, Path: itemPath, ACL: acl}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(target+archiveExt, archive, 0o644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(archive)
+	hexSum := hex.EncodeToString(sum[:])
+	if err := WriteChecksum(target+archiveExt, hexSum); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(target+itemMetaExt, meta, 0o644); err != nil {
+		return "", err
+	}
+	return hexSum, nil
+}
+
+// ImportCatalog uploads archives written by ExportCatalog below dest, restoring ACLs.
+// With verify, every archive must match its checksum file or nothing is imported.
+// It returns the catalog paths imported.
+func (c *OacClient) ImportCatalog(srcDir, dest string, verify bool) ([]string, error) {
+	dest = "/" + strings.Trim(dest, "/")
+	var imported []string
+
+	if verify {
+		if err := verifyArchives(srcDir); err != nil {
+			return nil, err
+		}
+	}
+
+	err := filepath.WalkDir(srcDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, file)
+		if err != nil {
+			return err
+		}
+		catalogPath := path.Join(dest, filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			return c.createFolder(catalogPath)
+		}
+		if !strings.HasSuffix(file, itemMetaExt) {
+			return nil
+		}
+
+		metaBytes, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var meta archivedItem
+		if err := json.Unmarshal(metaBytes, &meta); err != nil {
+			return fmt.Errorf("invalid item metadata %s: %w", file, err)
+		}
+
+		archive, err := os.ReadFile(strings.TrimSuffix(file, itemMetaExt) + archiveExt)
+		if err != nil {
+			return err
+		}
+
+		catalogPath = strings.TrimSuffix(catalogPath, itemMetaExt)
+		endpoint := itemEndpoint(meta.Type, catalogPath)
+
+		if err := c.ImportItem(meta.Type, catalogPath, archive); err != nil {
+			return err
+		}
+		if len(meta.ACL) > 0 {
+			if _, err := c.send("POST", endpoint+"/actions/updateACL", meta.ACL); err != nil {
+				return fmt.Errorf("failed to restore ACL of %s: %w", catalogPath, err)
+			}
+		}
+
+		imported = append(imported, catalogPath)
+		return nil
+	})
+
+	return imported, err
+}
+
+// verifyArchives checks every archive below dir against its checksum file
+func verifyArchives(dir string) error {
+	return filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, archiveExt) {
+			return err
+		}
+		return VerifyChecksum(file)
+	})
+}
+
+// ExportItem returns the archive of a single catalog item, checked against the digest
+// the instance reports for it
+func (c *OacClient) ExportItem(itemType, catalogPath string) ([]byte, error) {
+	resp, err := c.exchange("POST", itemEndpoint(itemType, catalogPath)+"/actions/export", "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", catalogPath, err)
+	}
+	if err := verifyDigest(catalogPath, resp.Header, resp.Bytes()); err != nil {
+		return nil, err
+	}
+	return resp.Bytes(), nil
+}
+
+// ImportItem uploads an archive produced by ExportItem to catalogPath, backing up the
+// current version first with WithBackup
+func (c *OacClient) ImportItem(itemType, catalogPath string, archive []byte) error {
+	if c.backup != nil {
+		if err := c.saveBackup(itemType, catalogPath); err != nil {
+			return err
+		}
+	}
+	endpoint := itemEndpoint(itemType, catalogPath) + "/actions/import"
+	if _, err := c.sendContent("POST", endpoint, "application/octet-stream", archive); err != nil {
+		return fmt.Errorf("failed to import %s: %w", catalogPath, err)
+	}
+	return nil
+}
+
+// createFolder creates a catalog folder and any missing parents
+func (c *OacClient) createFolder(catalogPath string) error {
+	body := map[string]any{
+		"id":                        EncodeCatalogID(catalogPath),
+		"createIntermediateFolders": true,
+	}
+	if err := c.callJSON("POST", apiBase+"/catalog/folders", body, nil); err != nil {
+		// the folder may already exist, which OAC reports as a conflict
+		if StatusCode(err) == http.StatusConflict {
+			return nil
+		}
+		return fmt.Errorf("failed to create folder %s: %w", catalogPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package oac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ItemFilter selects catalog items with clauses joined by &&, each comparing a field
+// (type, name, path, owner or id) with == or != to a value that may hold * wildcards:
+//
+//	type==workbook && path==/shared/Finance/*
+type ItemFilter struct {
+	expr    string
+	clauses []filterClause
+}
+
+type filterClause struct {
+	field  string
+	negate bool
+	value  string
+}
+
+// ParseItemFilter parses a filter expression; an empty one matches every item
+func ParseItemFilter(expr string) (*ItemFilter, error) {
+	f := &ItemFilter{expr: expr}
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+	for _, part := range strings.Split(expr, "&&") {
+		part = strings.TrimSpace(part)
+		var cl filterClause
+		field, value, ok := strings.Cut(part, "!=")
+		if ok {
+			cl.negate = true
+		} else if field, value, ok = strings.Cut(part, "=="); !ok {
+			return nil, fmt.Errorf("invalid filter clause %q, expected field==value or field!=value", part)
+		}
+		cl.field = strings.ToLower(strings.TrimSpace(field))
+		switch cl.field {
+		case "type", "name", "path", "owner", "id":
+		default:
+			return nil, fmt.Errorf("invalid filter field %q, expected type, name, path, owner or id", cl.field)
+		}
+		cl.value = strings.Trim(strings.TrimSpace(value), `'"`)
+		if _, err := path.Match(cl.value, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %q: %w", cl.value, err)
+		}
+		f.clauses = append(f.clauses, cl)
+	}
+	return f, nil
+}
+
+func (f *ItemFilter) String() string {
+	return f.expr
+}
+
+// Match reports whether item satisfies every clause. Types match in singular or plural,
+// so type==workbook selects workbooks.
+func (f *ItemFilter) Match(item CatalogItem) bool {
+	for _, cl := range f.clauses {
+		var v string
+		pattern := cl.value
+		switch cl.field {
+		case "type":
+			v, pattern = strings.TrimSuffix(strings.ToLower(item.Type), "s"), strings.TrimSuffix(strings.ToLower(pattern), "s")
+		case "name":
+			v = item.Name
+		case "path":
+			v = item.FullPath()
+		case "owner":
+			v = item.Owner
+		case "id":
+			v = item.ID
+		}
+		ok, _ := path.Match(pattern, v)
+		if ok == cl.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportManifest describes the objects written by ExportAll
+type ExportManifest struct {
+	Instance   string         `json:"instance"`
+	Root       string         `json:"root"`
+	Filter     string         `json:"filter,omitempty"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Items      []ManifestItem `json:"items"`
+
+	// dir is the directory of the manifest file, which archive paths are relative to
+	dir string
+}
+
+// ManifestItem is one exported object of an ExportManifest
+type ManifestItem struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+	// Archive is the archive file, relative to the manifest
+	Archive      string    `json:"archive,omitempty"`
+	SHA256       string    `json:"sha256,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	ExportedAt   time.Time `json:"exportedAt"`
+	Error        string    `json:"error,omitempty"`
+	// Absent records a backed up item that did not exist yet, see WithBackup
+	Absent bool `json:"absent,omitempty"`
+}
+
+// ArchiveFile returns the path of the archive of item, resolved against the manifest
+func (m *ExportManifest) ArchiveFile(item ManifestItem) string {
+	if item.Archive == "" || filepath.IsAbs(item.Archive) {
+		return item.Archive
+	}
+	return filepath.Join(m.dir, filepath.FromSlash(item.Archive))
+}
+
+// Failed returns the items that could not be exported
+func (m *ExportManifest) Failed() []ManifestItem {
+	var failed []ManifestItem
+	for _, item := range m.Items {
+		if item.Error != "" {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// LoadExportManifest reads a manifest written by WriteExportManifest
+func LoadExportManifest(file string) (*ExportManifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	m := &ExportManifest{dir: filepath.Dir(file)}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", file, err)
+	}
+	return m, nil
+}
+
+// WriteExportManifest writes m to file, with archive paths relative to it
+func WriteExportManifest(file string, m *ExportManifest) error {
+	dir := filepath.Dir(file)
+	out := *m
+	out.Items = make([]ManifestItem, len(m.Items))
+	for i, item := range m.Items {
+		if item.Archive != "" {
+			abs := m.ArchiveFile(item)
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				item.Archive = filepath.ToSlash(rel)
+			} else if a, err := filepath.Abs(abs); err == nil {
+				item.Archive = a
+			}
+		}
+		out.Items[i] = item
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", file, err)
+	}
+	m.dir = dir
+	m.Items = out.Items
+	return nil
+}
+
+// ExportAllOptions controls ExportAll
+type ExportAllOptions struct {
+	// Root is the folder searched, /shared when empty
+	Root   string
+	Filter *ItemFilter
+	// OutDir receives the archives in the layout of ExportCatalog
+	OutDir      string
+	Concurrency int
+	// Report is called with each item as it completes
+	Report func(ManifestItem)
+}
+
+// ExportAll exports the items below Root matching Filter with Concurrency workers,
+// writing them like ExportCatalog so that catalog import can restore them. Every item is
+// attempted; failures are recorded in the manifest. The error is only set when the
+// catalog could not be listed or ctx was cancelled.
+func (c *OacClient) ExportAll(ctx context.Context, opts ExportAllOptions) (*ExportManifest, error) {
+	root := "/" + strings.Trim(opts.Root, "/")
+	if opts.Root == "" {
+		root = "/shared"
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	filter := opts.Filter
+	if filter == nil {
+		filter = &ItemFilter{}
+	}
+	m := &ExportManifest{
+		Instance:   c.Instance(),
+		Root:       root,
+		Filter:     filter.String(),
+		ExportedAt: time.Now().UTC(),
+		dir:        ".",
+	}
+
+	var items []CatalogItem
+	err := c.WalkCatalog(root, func(item CatalogItem, depth int) error {
+		if !item.IsFolder() && filter.Match(item) {
+			items = append(items, item)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// obtain the token up front so workers don't race to refresh it
+	if len(items) > 0 {
+		if _, err := c.GetToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Items = make([]ManifestItem, len(items))
+	jobs := make(chan int)
+	var reportMu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item := items[i]
+				rel := strings.TrimPrefix(strings.TrimPrefix(item.FullPath(), root), "/")
+				target := filepath.Join(opts.OutDir, filepath.FromSlash(rel))
+				entry := ManifestItem{ID: item.ID, Type: item.Type, Path: item.FullPath(), LastModified: item.LastModified}
+				sum, err := c.archiveItem(item, target)
+				entry.ExportedAt = time.Now().UTC()
+				if err != nil {
+					entry.Error = err.Error()
+				} else {
+					entry.Archive, entry.SHA256 = target+archiveExt, sum
+				}
+				m.Items[i] = entry
+				if opts.Report != nil {
+					reportMu.Lock()
+					opts.Report(entry)
+					reportMu.Unlock()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// keep only the items attempted before a cancellation
+	done := m.Items[:0]
+	for _, item := range m.Items {
+		if item.Path != "" {
+			done = append(done, item)
+		}
+	}
+	m.Items = done
+	sort.Slice(m.Items, func(i, j int) bool { return m.Items[i].Path < m.Items[j].Path })
+	return m, err
+}
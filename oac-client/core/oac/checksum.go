@@ -0,0 +1,168 @@
+package oac
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumExt is the extension of the sidecar file holding an artifact's SHA-256
+const ChecksumExt = ".sha256"
+
+// ChecksumError reports an artifact whose SHA-256 differs from the expected one
+type ChecksumError struct {
+	File string
+	Want string
+	Got  string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.File, e.Want, e.Got)
+}
+
+// FileChecksum returns the hex SHA-256 of a file
+func FileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteChecksum writes the sidecar of file in the format of sha256sum
+func WriteChecksum(file, sum string) error {
+	line := sum + "  " + filepath.Base(file) + "\n"
+	if err := os.WriteFile(file+ChecksumExt, []byte(line), 0o644); err != nil {
+		return fmt.Errorf("failed to write checksum of %s: %w", file, err)
+	}
+	return nil
+}
+
+// ReadChecksum returns the SHA-256 recorded in the sidecar of file
+func ReadChecksum(file string) (string, error) {
+	f, err := os.Open(file + ChecksumExt)
+	if err != nil {
+		return "", fmt.Errorf("no checksum for %s: %w", file, err)
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	sum, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum file %s", file+ChecksumExt)
+	}
+	return strings.ToLower(sum), nil
+}
+
+// VerifyChecksum compares a file with its sidecar, failing when the sidecar is missing
+// or the contents differ
+func VerifyChecksum(file string) error {
+	want, err := ReadChecksum(file)
+	if err != nil {
+		return err
+	}
+	got, err := FileChecksum(file)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return &ChecksumError{File: file, Want: want, Got: got}
+	}
+	return nil
+}
+
+// ResponseDigest returns the hex SHA-256 of a response body reported in its headers
+// (Content-Digest, Digest or opc-content-sha256), or "" when the instance sent none
+func ResponseDigest(header http.Header) string {
+	for _, name := range []string{"Content-Digest", "Digest"} {
+		for _, part := range strings.Split(header.Get(name), ",") {
+			algo, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok || !strings.EqualFold(algo, "sha-256") {
+				continue
+			}
+			// Content-Digest wraps the value as a structured field byte sequence
+			value = strings.Trim(value, ":")
+			if b, err := base64.StdEncoding.DecodeString(value); err == nil && len(b) == sha256.Size {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+	if v := header.Get("opc-content-sha256"); v != "" {
+		if b, err := hex.DecodeString(v); err == nil && len(b) == sha256.Size {
+			return strings.ToLower(v)
+		}
+		if b, err := base64.StdEncoding.DecodeString(v); err == nil && len(b) == sha256.Size {
+			return hex.EncodeToString(b)
+		}
+	}
+	return ""
+}
+
+// verifyDigest checks a body held in memory against the digest of its response headers
+func verifyDigest(name string, header http.Header, body []byte) error {
+	want := ResponseDigest(header)
+	if want == "" {
+		return nil
+	}
+	sum := sha256.Sum256(body)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return &ChecksumError{File: name, Want: want, Got: got}
+	}
+	return nil
+}
+
+// Download is a streamed response body whose SHA-256 is computed as it is read
+type Download struct {
+	body io.ReadCloser
+	h    hash.Hash
+	// Expected is the digest reported by the instance, "" when it sent none
+	Expected string
+}
+
+func (d *Download) Read(p []byte) (int, error) {
+	n, err := d.body.Read(p)
+	d.h.Write(p[:n])
+	return n, err
+}
+
+func (d *Download) Close() error {
+	return d.body.Close()
+}
+
+// Sum returns the hex SHA-256 of the bytes read so far
+func (d *Download) Sum() string {
+	return hex.EncodeToString(d.h.Sum(nil))
+}
+
+// Verify returns a *ChecksumError naming file when the body read differs from the
+// digest reported by the instance
+func (d *Download) Verify(file string) error {
+	if d.Expected != "" && d.Sum() != d.Expected {
+		return &ChecksumError{File: file, Want: d.Expected, Got: d.Sum()}
+	}
+	return nil
+}
+
+// download performs a request and returns its body unread, hashed as it is consumed
+func (c *OacClient) download(method, path string) (*Download, error) {
+	resp, err := c.open(&Request{Method: method, Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return &Download{body: resp.Body, h: sha256.New(), Expected: ResponseDigest(resp.Header)}, nil
+}
@@ -0,0 +1,171 @@
+package oac
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultUploadChunkSize is the chunk size of UploadChunked
+const DefaultUploadChunkSize = 8 << 20
+
+// UploadIDHeader identifies the upload session a chunk belongs to
+const UploadIDHeader = "Upload-Id"
+
+// ChunkedUploadOptions controls UploadChunked
+type ChunkedUploadOptions struct {
+	// ChunkSize is the size of each request, DefaultUploadChunkSize when zero
+	ChunkSize int64
+	// ContentType defaults to application/octet-stream
+	ContentType string
+	// Header values are sent with every chunk
+	Header http.Header
+	// Restart ignores the manifest of an interrupted upload and starts from the first byte
+	Restart bool
+	// OnProgress, when not nil, is called with the bytes confirmed so far, including
+	// those skipped on resume
+	OnProgress func(confirmed, total int64)
+}
+
+// uploadManifest records the progress of a chunked upload; it is only reused for the
+// same file contents, request and chunk size
+type uploadManifest struct {
+	File      string    `json:"file"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Modified  time.Time `json:"modified"`
+	ChunkSize int64     `json:"chunkSize"`
+	UploadID  string    `json:"uploadId"`
+	Confirmed int64     `json:"confirmed"`
+}
+
+// UploadManifestDir returns the directory holding the manifests of interrupted uploads
+func UploadManifestDir() string {
+	return filepath.Join(StateDir(), "uploads")
+}
+
+// uploadManifestFile returns the manifest of uploading file with method and path
+func uploadManifestFile(method, path, file string) string {
+	sum := sha256.Sum256([]byte(method + " " + path + " " + file))
+	return filepath.Join(UploadManifestDir(), hex.EncodeToString(sum[:8])+".json")
+}
+
+// UploadChunked sends a file in chunks of opts.ChunkSize, each a request carrying a
+// Content-Range header and the Upload-Id of the session, and returns the response to the
+// last chunk. Every confirmed chunk is recorded in a manifest under UploadManifestDir, so
+// calling UploadChunked again after a failure resumes after the last confirmed chunk.
+func (c *OacClient) UploadChunked(method, path, file string, opts ChunkedUploadOptions) (*Response, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultUploadChunkSize
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/octet-stream"
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFile := uploadManifestFile(method, path, abs)
+	m := uploadManifest{File: abs, Method: method, Path: path, Size: info.Size(), Modified: info.ModTime().UTC(), ChunkSize: opts.ChunkSize}
+	if saved, ok := loadUploadManifest(manifestFile); ok && !opts.Restart && saved.UploadID != "" {
+		session := saved
+		session.UploadID, session.Confirmed = "", 0
+		if session == m {
+			m = saved
+		}
+	}
+	if m.UploadID == "" {
+		m.UploadID = newUploadID()
+	}
+	if opts.OnProgress != nil {
+		opts.OnProgress(m.Confirmed, m.Size)
+	}
+
+	for {
+		start := m.Confirmed
+		n := min(opts.ChunkSize, m.Size-start)
+		header := opts.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set(UploadIDHeader, m.UploadID)
+		header.Set("Content-Range", contentRange(start, n, m.Size))
+
+		resp, err := c.exchangeRequest(&Request{
+			Method: method, Path: path, Header: header, ContentType: opts.ContentType, ContentLength: n,
+			GetBody: func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(f, start, n)), nil
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("upload of %s interrupted at %s of %s: %w", file, FormatSize(start), FormatSize(m.Size), err)
+		}
+
+		m.Confirmed = start + n
+		if opts.OnProgress != nil {
+			opts.OnProgress(m.Confirmed, m.Size)
+		}
+		if m.Confirmed >= m.Size {
+			os.Remove(manifestFile)
+			return resp, nil
+		}
+		if err := saveUploadManifest(manifestFile, m); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// contentRange returns the Content-Range of n bytes from start of a body of size total
+func contentRange(start, n, total int64) string {
+	if n == 0 {
+		return fmt.Sprintf("bytes */%d", total)
+	}
+	return fmt.Sprintf("bytes %d-%d/%d", start, start+n-1, total)
+}
+
+func newUploadID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func loadUploadManifest(file string) (uploadManifest, bool) {
+	var m uploadManifest
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return m, false
+	}
+	return m, json.Unmarshal(data, &m) == nil
+}
+
+func saveUploadManifest(file string, m uploadManifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("failed to record upload progress: %w", err)
+	}
+	return nil
+}
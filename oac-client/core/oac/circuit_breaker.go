@@ -0,0 +1,169 @@
+package oac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the circuit breaker, unless set by WithCircuitBreaker or OAC_CIRCUIT_BREAKER
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// CircuitOpenError is returned without sending a request while the circuit breaker is
+// open, i.e. the instance failed too many requests in a row
+type CircuitOpenError struct {
+	Host     string
+	Failures int
+	// LastError describes the failure that opened the circuit
+	LastError string
+	// RetryAt is when the next request is let through to probe the instance
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open: %s failed %d requests in a row (last: %s), not sending requests until %s",
+		e.Host, e.Failures, e.LastError, e.RetryAt.Format(time.TimeOnly))
+}
+
+// CircuitBreaker stops sending requests to an instance that keeps failing, e.g. while
+// it is being patched and answers 503. After threshold consecutive server errors or
+// connection failures it opens and fails requests at once for the cooldown; then one
+// request is let through as a probe, closing the circuit if it succeeds and opening it
+// for another cooldown if not. It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	lastError string
+	host      string
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures for cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: max(threshold, 1), cooldown: cooldown}
+}
+
+// allow returns a *CircuitOpenError while the circuit is open; after the cooldown it
+// lets a single probe through
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return &CircuitOpenError{Host: b.host, Failures: b.failures, LastError: b.lastError, RetryAt: b.openUntil}
+	}
+	b.probing = true
+	slog.Debug("circuit breaker half-open, probing", "host", b.host)
+	return nil
+}
+
+// record counts the outcome of a request let through by allow
+func (b *CircuitBreaker) record(req *http.Request, resp *http.Response, err error) {
+	failure := ""
+	switch {
+	case err != nil:
+		if errors.Is(err, context.Canceled) {
+			// the caller gave up, the instance did not fail
+			b.release()
+			return
+		}
+		failure = err.Error()
+	case resp.StatusCode >= 500:
+		failure = resp.Status
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.failures >= b.threshold
+	b.probing = false
+	if failure == "" {
+		if wasOpen {
+			slog.Info("circuit breaker closed, instance is responding again", "host", b.host)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = failure
+	b.host = req.URL.Host
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		if !wasOpen {
+			slog.Warn("circuit breaker opened, failing requests without sending them",
+				"host", b.host, "failures", b.failures, "last", failure, "cooldown", b.cooldown)
+		}
+	}
+}
+
+// release ends a probe without counting it
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// WithCircuitBreaker fails requests at once after threshold consecutive server errors
+// or connection failures, for cooldown; a threshold of 0 turns the breaker off
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *OacClient) {
+		c.breaker = nil
+		c.breakerSet = true
+		if threshold > 0 {
+			c.breaker = NewCircuitBreaker(threshold, cooldown)
+		}
+	}
+}
+
+// ParseCircuitBreaker parses values like "5/30s" (open after 5 failures in a row for 30
+// seconds), "5" (with the default cooldown) or "off"; a threshold of 0 means off
+func ParseCircuitBreaker(value string) (int, time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return 0, 0, nil
+	}
+	count, wait, hasWait := strings.Cut(value, "/")
+	threshold, err := strconv.Atoi(count)
+	if err != nil || threshold < 0 {
+		return 0, 0, fmt.Errorf("invalid circuit breaker %q, expected failures/cooldown such as 5/30s, or off", value)
+	}
+	cooldown := DefaultBreakerCooldown
+	if hasWait {
+		if cooldown, err = time.ParseDuration(wait); err != nil || cooldown <= 0 {
+			return 0, 0, fmt.Errorf("invalid circuit breaker cooldown %q", wait)
+		}
+	}
+	return threshold, cooldown, nil
+}
+
+// breakerFromEnv applies OAC_CIRCUIT_BREAKER unless WithCircuitBreaker was given
+func (c *OacClient) breakerFromEnv() error {
+	if c.breakerSet {
+		return nil
+	}
+	threshold, cooldown := DefaultBreakerThreshold, DefaultBreakerCooldown
+	if v := c.setting("OAC_CIRCUIT_BREAKER"); v != "" {
+		var err error
+		if threshold, cooldown, err = ParseCircuitBreaker(v); err != nil {
+			return fmt.Errorf("invalid OAC_CIRCUIT_BREAKER: %w", err)
+		}
+	}
+	WithCircuitBreaker(threshold, cooldown)(c)
+	return nil
+}
@@ -0,0 +1,154 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressMinSize is the smallest request body gzipped by WithRequestCompression(0)
+const DefaultCompressMinSize = 64 << 10
+
+// WithRequestCompression gzips request bodies of at least minSize bytes (DefaultCompressMinSize
+// when 0). Endpoints that reject compressed bodies with 415 are retried uncompressed.
+func WithRequestCompression(minSize int64) Option {
+	return func(c *OacClient) {
+		if minSize <= 0 {
+			minSize = DefaultCompressMinSize
+		}
+		c.compressMin = minSize
+	}
+}
+
+// compressRequest returns a copy of r with a gzipped body, or nil when r should be sent as is
+func (c *OacClient) compressRequest(r *Request) *Request {
+	if c.compressMin == 0 || r.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	br, ok := r.Body.(*bytes.Reader)
+	if !ok || br.Size() < c.compressMin {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, io.NewSectionReader(br, 0, br.Size())); err != nil {
+		return nil
+	}
+	if err := zw.Close(); err != nil {
+		return nil
+	}
+
+	compressed := *r
+	compressed.Header = r.Header.Clone()
+	if compressed.Header == nil {
+		compressed.Header = http.Header{}
+	}
+	compressed.Header.Set("Content-Encoding", "gzip")
+	compressed.Body = bytes.NewReader(buf.Bytes())
+	return &compressed
+}
+
+// openCompressed sends r with a gzipped body when compression applies, falling back to
+// the plain body when the endpoint does not accept it
+func (c *OacClient) openCompressed(r *Request) (*http.Response, error) {
+	compressed := c.compressRequest(r)
+	if compressed == nil {
+		return c.openRequest(r)
+	}
+
+	resp, err := c.openRequest(compressed)
+	if StatusCode(err) == http.StatusUnsupportedMediaType {
+		slog.Debug("endpoint rejected a compressed body, retrying uncompressed", "path", r.Path)
+		return c.openRequest(r)
+	}
+	return resp, err
+}
+
+// decompressingTransport asks for gzip or deflate responses and decodes them, so callers
+// always see plain bodies. Requests that set their own Accept-Encoding are left alone.
+type decompressingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *decompressingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Method == http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			// empty bodies (204, 304) carry the header without any gzip data
+			if err == io.EOF {
+				return resp, nil
+			}
+			resp.Body.Close()
+			return nil, err
+		}
+		body = &decodedBody{Reader: zr, raw: resp.Body}
+	case "deflate":
+		zr, err := deflateReader(resp.Body)
+		if err != nil {
+			if err == io.EOF {
+				return resp, nil
+			}
+			resp.Body.Close()
+			return nil, err
+		}
+		body = &decodedBody{Reader: zr, raw: resp.Body}
+	default:
+		return resp, nil
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// deflateReader decodes a deflate body: zlib-wrapped as HTTP defines it, or the raw
+// deflate data some servers send instead
+func deflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if len(header) == 0 {
+		return nil, err
+	}
+	// a zlib header names the deflate method and is a multiple of 31
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decodedBody closes the underlying response body along with the decoder
+type decodedBody struct {
+	io.Reader
+	raw io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return b.raw.Close()
+}
@@ -0,0 +1,44 @@
+package oac
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func TestDeflateReader(t *testing.T) {
+	const text = `{"items":[{"name":"Revenue"}]}`
+	var zlibbed, raw bytes.Buffer
+	zw := zlib.NewWriter(&zlibbed)
+	zw.Write([]byte(text))
+	zw.Close()
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write([]byte(text))
+	fw.Close()
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"zlib", zlibbed.Bytes()},
+		{"raw deflate", raw.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := deflateReader(bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != text {
+				t.Errorf("decoded %q, %v", got, err)
+			}
+		})
+	}
+
+	if _, err := deflateReader(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("empty body: %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,54 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// Condition is a JMESPath expression evaluated against JSON responses, e.g.
+// status=='SUCCEEDED' or length(items[?status=='FAILED']) > `0`
+type Condition struct {
+	expr  string
+	query *jmespath.JMESPath
+}
+
+// CompileCondition parses a JMESPath condition
+func CompileCondition(expr string) (*Condition, error) {
+	query, err := jmespath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JMESPath expression %q: %w", expr, err)
+	}
+	return &Condition{expr: expr, query: query}, nil
+}
+
+func (c *Condition) String() string {
+	return c.expr
+}
+
+// Match reports whether the condition holds for a JSON body. As in JMESPath filters,
+// false, null and empty strings, arrays and objects do not hold; anything else does.
+func (c *Condition) Match(body []byte) (bool, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false, fmt.Errorf("response is not JSON: %w", err)
+	}
+	v, err := c.query.Search(doc)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate %q: %w", c.expr, err)
+	}
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		return v != "", nil
+	case []any:
+		return len(v) > 0, nil
+	case map[string]any:
+		return len(v) > 0, nil
+	}
+	return true, nil
+}
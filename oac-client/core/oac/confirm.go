@@ -0,0 +1,97 @@
+package oac
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrNotConfirmed is returned when a protected request was not confirmed
+var ErrNotConfirmed = errors.New("request not confirmed")
+
+// ConfirmFunc is asked before a protected request is sent and returns nil to send it
+type ConfirmFunc func(profile, method, path string) error
+
+// WithConfirmation guards the requests protected by the client's profile with confirm
+func WithConfirmation(confirm ConfirmFunc) Option {
+	return func(c *OacClient) {
+		c.confirm = confirm
+	}
+}
+
+// Protects reports whether a request requires confirmation. Confirm protects every
+// DELETE; each Protected entry is "[METHOD] pattern", where the method defaults to
+// DELETE and the pattern matches the path without its query, "*" matching one path
+// segment and "**" any number of them, e.g. "DELETE /api/**/snapshots/*".
+func (p *Profile) Protects(method, requestPath string) bool {
+	if p == nil {
+		return false
+	}
+	method = strings.ToUpper(method)
+	if p.Confirm && method == "DELETE" {
+		return true
+	}
+
+	requestPath, _, _ = strings.Cut(requestPath, "?")
+	// match what the instance sees, not its encoding: /api/%73napshots is /api/snapshots
+	if decoded, err := url.PathUnescape(requestPath); err == nil {
+		requestPath = decoded
+	}
+	requestPath = path.Clean("/" + requestPath)
+	for _, entry := range p.Protected {
+		want, pattern := "DELETE", strings.TrimSpace(entry)
+		if m, rest, ok := strings.Cut(pattern, " "); ok && !strings.HasPrefix(m, "/") {
+			want, pattern = strings.ToUpper(m), strings.TrimSpace(rest)
+		}
+		if (want == method || want == "*") && matchPathPattern(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPattern matches a slash separated path against a pattern with "*" and "**" segments
+func matchPathPattern(pattern, p string) bool {
+	return matchSegments(splitPath(pattern), splitPath(p))
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// confirmRequest asks the confirmation function before a protected request is sent
+func (c *OacClient) confirmRequest(r *Request) error {
+	if !c.profile.Protects(r.Method, r.Path) {
+		return nil
+	}
+	if c.confirm == nil {
+		return ErrNotConfirmed
+	}
+	return c.confirm(c.ProfileName(), strings.ToUpper(r.Method), r.Path)
+}
@@ -0,0 +1,61 @@
+package oac
+
+import "testing"
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api/snapshots/*", "/api/snapshots/s1", true},
+		{"/api/snapshots/*", "/api/snapshots", false},
+		{"/api/snapshots/*", "/api/snapshots/s1/restore", false},
+		{"/api/**/snapshots/*", "/api/snapshots/s1", true},
+		{"/api/**/snapshots/*", "/api/20210901/snapshots/s1", true},
+		{"/api/**/snapshots/*", "/api/a/b/snapshots/s1", true},
+		{"/api/**", "/api", true},
+		{"/api/**", "/api/catalog/workbooks/x", true},
+		{"/**", "/anything/at/all", true},
+		{"/api/catalog/*s/*", "/api/catalog/workbooks/x", true},
+		{"/api/catalog/*s/*", "/api/catalog/folder/x", false},
+		{"/api/[", "/api/[", false},
+		{"api/snapshots/", "/api/snapshots", true},
+	}
+	for _, tt := range tests {
+		if got := matchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestProfileProtects(t *testing.T) {
+	p := &Profile{Protected: []string{"/api/**/snapshots/*", "POST /api/**/restoreSnapshot", "* /api/system/**"}}
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"DELETE", "/api/20210901/snapshots/s1", true},
+		{"delete", "/api/20210901/snapshots/s1?force=true", true},
+		{"GET", "/api/20210901/snapshots/s1", false},
+		{"DELETE", "/api/20210901/catalog/workbooks/x", false},
+		{"POST", "/api/20210901/system/actions/restoreSnapshot", true},
+		{"PUT", "/api/system/settings", true},
+		{"DELETE", "/api/20210901/%73napshots/s1", true},
+		{"DELETE", "/api/20210901/catalog/../snapshots/s1", true},
+		{"DELETE", "//api/20210901/snapshots/s1", true},
+	}
+	for _, tt := range tests {
+		if got := p.Protects(tt.method, tt.path); got != tt.want {
+			t.Errorf("Protects(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	if !(&Profile{Confirm: true}).Protects("DELETE", "/api/anything") {
+		t.Error("Confirm does not protect DELETE")
+	}
+	if (*Profile)(nil).Protects("DELETE", "/api/anything") {
+		t.Error("nil profile protects a request")
+	}
+}
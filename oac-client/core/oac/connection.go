@@ -0,0 +1,40 @@
+package oac
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// connectionsPath is the catalog endpoint for data connections
+const connectionsPath = apiBase + "/catalog/connections"
+
+// ListConnections returns the data connections visible to the caller
+func (c *OacClient) ListConnections() ([]CatalogItem, error) {
+	return c.SearchCatalog("connections", "*")
+}
+
+// GetConnection returns the definition of a connection
+func (c *OacClient) GetConnection(id string) (json.RawMessage, error) {
+	return c.send("GET", connectionsPath+"/"+url.PathEscape(id), nil)
+}
+
+// CreateConnection creates a connection from a JSON definition
+func (c *OacClient) CreateConnection(spec json.RawMessage) (json.RawMessage, error) {
+	return c.send("POST", connectionsPath, spec)
+}
+
+// UpdateConnection replaces the definition of a connection
+func (c *OacClient) UpdateConnection(id string, spec json.RawMessage) (json.RawMessage, error) {
+	return c.send("PUT", connectionsPath+"/"+url.PathEscape(id), spec)
+}
+
+// DeleteConnection removes a connection
+func (c *OacClient) DeleteConnection(id string) error {
+	_, err := c.send("DELETE", connectionsPath+"/"+url.PathEscape(id), nil)
+	return err
+}
+
+// TestConnection asks OAC to connect to the target database with the stored credentials
+func (c *OacClient) TestConnection(id string) (json.RawMessage, error) {
+	return c.send("POST", connectionsPath+"/"+url.PathEscape(id)+"/actions/test", nil)
+}
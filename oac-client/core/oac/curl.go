@@ -0,0 +1,93 @@
+package oac
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// curlTokenPlaceholder replaces the bearer token in printed curl commands
+const curlTokenPlaceholder = "$OAC_TOKEN"
+
+// curlMaxInlineBody is the largest body inlined in a curl command; larger or binary
+// bodies are referenced as a file
+const curlMaxInlineBody = 64 << 10
+
+// CurlCommand returns a curl command sending req, with the bearer token replaced by
+// $OAC_TOKEN so it can be shared. Bodies that can be read again are inlined when they
+// are small text, otherwise referenced as @body.bin.
+func CurlCommand(req *http.Request) (string, error) {
+	var b strings.Builder
+	b.WriteString("curl")
+	if req.Method != http.MethodGet {
+		b.WriteString(" -X " + req.Method)
+	}
+	b.WriteString(" " + shellQuote(req.URL.String()))
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if name == "Authorization" && strings.HasPrefix(value, "Bearer ") {
+				b.WriteString(` \` + "\n  -H \"Authorization: Bearer " + curlTokenPlaceholder + `"`)
+				continue
+			}
+			b.WriteString(` \` + "\n  -H " + shellQuote(name+": "+value))
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := curlBody(req)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(` \` + "\n  " + body)
+	}
+	return b.String(), nil
+}
+
+// curlBody returns the curl option sending the body of req
+func curlBody(req *http.Request) (string, error) {
+	if req.GetBody == nil {
+		return "--data-binary @body.bin", nil
+	}
+	r, err := req.GetBody()
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, curlMaxInlineBody+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(data) > curlMaxInlineBody || !utf8.Valid(data) || req.Header.Get("Content-Encoding") != "" {
+		return "--data-binary @body.bin", nil
+	}
+	return "--data-raw " + shellQuote(string(data)), nil
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// PrintCurl returns middleware writing every request to w as a curl command before it
+// is sent, see CurlCommand
+func PrintCurl(w io.Writer) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			cmd, err := CurlCommand(req)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(w, cmd)
+			return next(req)
+		}
+	}
+}
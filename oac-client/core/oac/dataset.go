@@ -0,0 +1,144 @@
+package oac
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DatasetSQL returns the logical SQL selecting every column of a dataset. The dataset is
+// given by its API id, or as owner.name or 'owner'.'name'.
+func DatasetSQL(id string) (string, error) {
+	ref := id
+	if decoded, err := DecodeCatalogID(id); err == nil && strings.Contains(decoded, "'.'") {
+		ref = decoded
+	}
+	if !strings.HasPrefix(ref, "'") {
+		owner, name, ok := strings.Cut(ref, ".")
+		if !ok || owner == "" || name == "" {
+			return "", fmt.Errorf("invalid dataset %q: expected its id or owner.name", id)
+		}
+		ref = "'" + strings.ReplaceAll(owner, "'", "''") + "'.'" + strings.ReplaceAll(name, "'", "''") + "'"
+	}
+	return "SELECT * FROM XSA(" + ref + ")", nil
+}
+
+// PreviewDataset returns the columns and first rows of a dataset
+func (c *OacClient) PreviewDataset(id string, rows int) (*QueryPage, error) {
+	sql, err := DatasetSQL(id)
+	if err != nil {
+		return nil, err
+	}
+	preview := &QueryPage{Rows: [][]any{}}
+	err = c.Query(sql, QueryOptions{PageSize: rows, MaxRows: rows}, func(p *QueryPage) error {
+		if preview.Columns == nil {
+			preview.Columns = p.Columns
+		}
+		preview.Rows = append(preview.Rows, p.Rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview dataset %s: %w", id, err)
+	}
+	return preview, nil
+}
+
+// ColumnProfile summarizes the values of a dataset column
+type ColumnProfile struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Rows     int    `json:"rows"`
+	Nulls    int    `json:"nulls"`
+	Distinct int    `json:"distinct"`
+	// Min and Max are numbers for numeric columns and strings otherwise
+	Min any `json:"min,omitempty"`
+	Max any `json:"max,omitempty"`
+	// Mean is set for numeric columns
+	Mean *float64 `json:"mean,omitempty"`
+
+	distinct map[string]struct{}
+	numbers  int
+	sum      float64
+}
+
+// add accounts for one value of the column
+func (p *ColumnProfile) add(v any) {
+	p.Rows++
+	if v == nil || v == "" {
+		p.Nulls++
+		return
+	}
+	if p.distinct == nil {
+		p.distinct = map[string]struct{}{}
+	}
+	p.distinct[fmt.Sprint(v)] = struct{}{}
+
+	switch v := v.(type) {
+	case float64:
+		p.numbers++
+		p.sum += v
+		if min, ok := p.Min.(float64); !ok || v < min {
+			p.Min = v
+		}
+		if max, ok := p.Max.(float64); !ok || v > max {
+			p.Max = v
+		}
+	default:
+		if p.numbers > 0 {
+			return
+		}
+		s := fmt.Sprint(v)
+		if min, ok := p.Min.(string); !ok || s < min {
+			p.Min = s
+		}
+		if max, ok := p.Max.(string); !ok || s > max {
+			p.Max = s
+		}
+	}
+}
+
+// finish computes the figures derived from the accumulated values
+func (p *ColumnProfile) finish() {
+	p.Distinct = len(p.distinct)
+	if p.numbers > 0 {
+		mean := math.Round(p.sum/float64(p.numbers)*1e6) / 1e6
+		p.Mean = &mean
+	}
+}
+
+// ProfileDataset reads a dataset, at most maxRows rows when positive, and returns the
+// profile of every column together with the number of rows read
+func (c *OacClient) ProfileDataset(id string, maxRows int) ([]ColumnProfile, int, error) {
+	sql, err := DatasetSQL(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	var profiles []ColumnProfile
+	rows := 0
+	err = c.Query(sql, QueryOptions{MaxRows: maxRows}, func(p *QueryPage) error {
+		if profiles == nil {
+			profiles = make([]ColumnProfile, len(p.Columns))
+			for i, col := range p.Columns {
+				profiles[i] = ColumnProfile{Name: col.Name, Type: col.Type}
+			}
+		}
+		for _, row := range p.Rows {
+			rows++
+			for i := range profiles {
+				var v any
+				if i < len(row) {
+					v = row[i]
+				}
+				profiles[i].add(v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to profile dataset %s: %w", id, err)
+	}
+	for i := range profiles {
+		profiles[i].finish()
+	}
+	return profiles, rows, nil
+}
@@ -0,0 +1,317 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// DatasetPushModes are the accepted values of PushOptions.Mode
+var DatasetPushModes = []string{"replace", "append"}
+
+// DefaultPushChunkSize is the size of the CSV chunks uploaded by PushDataset
+const DefaultPushChunkSize = 8 << 20
+
+// PushOptions controls PushDataset
+type PushOptions struct {
+	// Name is the dataset to load
+	Name string
+	// Mode is replace, to overwrite the dataset's data, or append
+	Mode string
+	// ChunkSize is the approximate size of each uploaded chunk, DefaultPushChunkSize when zero
+	ChunkSize int64
+	// Delimiter separates the fields of a CSV file, ',' when zero
+	Delimiter rune
+	// Restart ignores the state of an interrupted push and starts from the first chunk
+	Restart bool
+	// OnChunk, when not nil, is called after every chunk uploaded or skipped on resume
+	OnChunk func(chunk int, rows int, size int64, skipped bool)
+}
+
+// PushResult reports the outcome of PushDataset
+type PushResult struct {
+	Rows    int
+	Chunks  int
+	Resumed int
+	// WorkRequestID tracks the reload, when the instance returned one
+	WorkRequestID string
+}
+
+// pushState records the progress of a push so an interrupted one can resume; it is
+// only reused for the same file contents, dataset, mode and chunk size
+type pushState struct {
+	Size      int64     `json:"size"`
+	Modified  time.Time `json:"modified"`
+	Name      string    `json:"name"`
+	Mode      string    `json:"mode"`
+	ChunkSize int64     `json:"chunkSize"`
+	Done      int       `json:"done"`
+}
+
+// PushStateFile returns the file recording the progress of pushing file
+func PushStateFile(file string) string {
+	return filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".push")
+}
+
+// PushDataset converts a local CSV or Parquet file to CSV, uploads it in chunks and
+// reloads the dataset. The first chunk is uploaded with opts.Mode and later ones are
+// appended. Progress is recorded next to the file, so running the push again after a
+// failure skips the chunks already uploaded.
+func (c *OacClient) PushDataset(file string, opts PushOptions) (*PushResult, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("a dataset name is required")
+	}
+	if !slices.Contains(DatasetPushModes, opts.Mode) {
+		return nil, fmt.Errorf("unsupported mode %q, expected %s", opts.Mode, strings.Join(DatasetPushModes, " or "))
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultPushChunkSize
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	want := pushState{Size: info.Size(), Modified: info.ModTime().UTC(), Name: opts.Name, Mode: opts.Mode, ChunkSize: opts.ChunkSize}
+	stateFile := PushStateFile(file)
+	done := 0
+	if !opts.Restart {
+		var state pushState
+		if data, err := os.ReadFile(stateFile); err == nil && json.Unmarshal(data, &state) == nil {
+			done, state.Done = state.Done, 0
+			if state != want {
+				done = 0
+			}
+		}
+	}
+
+	rows, err := openDatasetRows(file, opts.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &PushResult{Resumed: done}
+	endpoint := apiBase + "/datasets/" + url.PathEscape(opts.Name)
+	var buf bytes.Buffer
+	for chunk := 0; ; chunk++ {
+		n, err := rows.chunk(&buf, opts.ChunkSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if n == 0 && chunk > 0 {
+			break
+		}
+		result.Rows += n
+		result.Chunks++
+
+		skip := chunk < done
+		if !skip {
+			mode := opts.Mode
+			if chunk > 0 {
+				mode = "append"
+			}
+			query := url.Values{"mode": {mode}, "chunk": {strconv.Itoa(chunk)}}
+			_, err := c.exchangeRequest(&Request{Method: "POST", Path: endpoint + "/actions/uploadData", Query: query,
+				ContentType: "text/csv", Body: bytes.NewReader(buf.Bytes())})
+			if err != nil {
+				return result, fmt.Errorf("failed to upload chunk %d of %s: %w", chunk+1, file, err)
+			}
+			want.Done = chunk + 1
+			if err := savePushState(stateFile, want); err != nil {
+				return result, err
+			}
+		}
+		if opts.OnChunk != nil {
+			opts.OnChunk(chunk, n, int64(buf.Len()), skip)
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	resp, err := c.exchange("POST", endpoint+"/actions/reload", "application/json", nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to reload dataset %s: %w", opts.Name, err)
+	}
+	result.WorkRequestID = resp.Header.Get("oa-work-request-id")
+	os.Remove(stateFile)
+	return result, nil
+}
+
+// savePushState writes the progress of a push
+func savePushState(file string, state pushState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("failed to record push progress: %w", err)
+	}
+	return nil
+}
+
+// datasetRows reads the records of a CSV or Parquet file as strings
+type datasetRows struct {
+	header []string
+	next   func() ([]string, error)
+	close  func() error
+}
+
+func (r *datasetRows) Close() error {
+	return r.close()
+}
+
+// chunk writes the header and the next records to buf as CSV, until buf holds at least
+// size bytes, and returns the number of records written
+func (r *datasetRows) chunk(buf *bytes.Buffer, size int64) (int, error) {
+	buf.Reset()
+	w := csv.NewWriter(buf)
+	if err := w.Write(r.header); err != nil {
+		return 0, err
+	}
+	n := 0
+	for int64(buf.Len()) < size {
+		record, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := w.Write(record); err != nil {
+			return n, err
+		}
+		n++
+		// flush regularly so buf.Len reflects the records written
+		if n%256 == 0 {
+			w.Flush()
+		}
+	}
+	w.Flush()
+	return n, w.Error()
+}
+
+// openDatasetRows opens a .parquet file, or any other file as CSV
+func openDatasetRows(file string, delimiter rune) (*datasetRows, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	var rows *datasetRows
+	if strings.EqualFold(filepath.Ext(file), ".parquet") {
+		rows, err = parquetRows(f)
+	} else {
+		rows, err = csvRows(f, delimiter)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return rows, nil
+}
+
+// csvRows reads a CSV file whose first record is the header
+func csvRows(f *os.File, delimiter rune) (*datasetRows, error) {
+	r := csv.NewReader(f)
+	if delimiter != 0 {
+		r.Comma = delimiter
+	}
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Excel writes UTF-8 CSV with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	return &datasetRows{header: header, next: r.Read, close: f.Close}, nil
+}
+
+// parquetRows reads a Parquet file with flat columns
+func parquetRows(f *os.File) (*datasetRows, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	fields := pf.Schema().Fields()
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		if !field.Leaf() {
+			return nil, fmt.Errorf("nested column %s is not supported", field.Name())
+		}
+		header[i] = field.Name()
+	}
+
+	reader := parquet.NewReader(pf)
+	next := func() ([]string, error) {
+		row := map[string]any{}
+		if err := reader.Read(&row); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		record := make([]string, len(fields))
+		for i, field := range fields {
+			record[i] = parquetValue(row[field.Name()], field.Type().LogicalType())
+		}
+		return record, nil
+	}
+	return &datasetRows{header: header, next: next, close: func() error {
+		reader.Close()
+		return f.Close()
+	}}, nil
+}
+
+// parquetValue formats a Parquet value for a CSV cell, dates and timestamps as ISO 8601
+func parquetValue(v any, logical *format.LogicalType) string {
+	if v == nil {
+		return ""
+	}
+	if logical != nil {
+		switch t := logical.Value.(type) {
+		case *format.DateType:
+			if days, ok := v.(int32); ok {
+				return time.Unix(int64(days)*86400, 0).UTC().Format(time.DateOnly)
+			}
+		case *format.TimestampType:
+			if n, ok := v.(int64); ok && t.Unit.Value != nil {
+				unit := t.Unit.Value.Duration()
+				return time.Unix(0, 0).Add(time.Duration(n) * unit).UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
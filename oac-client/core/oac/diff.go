@@ -0,0 +1,110 @@
+package oac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Diff states reported by CompareCatalog
+const (
+	DiffSame      = "same"
+	DiffMissing   = "missing"
+	DiffExtra     = "extra"
+	DiffDifferent = "different"
+)
+
+// DiffEntry compares one catalog item between two instances
+type DiffEntry struct {
+	Path   string
+	Type   string
+	Status string
+	From   *CatalogItem
+	To     *CatalogItem
+}
+
+// CompareCatalog compares the items under root on src and dst. Items are matched by
+// catalog path and compared by modified timestamp, or by a hash of their exported
+// definition when byHash is set. Entries are sorted by path.
+func CompareCatalog(src, dst *OacClient, root string, byHash bool) ([]DiffEntry, error) {
+	root = "/" + strings.Trim(root, "/")
+
+	from, err := catalogIndex(src, root)
+	if err != nil {
+		return nil, err
+	}
+	to, err := catalogIndex(dst, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DiffEntry
+	for p, item := range from {
+		entry := DiffEntry{Path: p, Type: item.Type, From: item, Status: DiffMissing}
+		if other, ok := to[p]; ok {
+			entry.To = other
+			entry.Status, err = compareItems(src, dst, item, other, byHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	for p, item := range to {
+		if _, ok := from[p]; !ok {
+			entries = append(entries, DiffEntry{Path: p, Type: item.Type, To: item, Status: DiffExtra})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// catalogIndex maps every non-folder item under root by its catalog path
+func catalogIndex(c *OacClient, root string) (map[string]*CatalogItem, error) {
+	index := map[string]*CatalogItem{}
+	err := c.WalkCatalog(root, func(item CatalogItem, depth int) error {
+		if !item.IsFolder() {
+			index[item.FullPath()] = &item
+		}
+		return nil
+	})
+	return index, err
+}
+
+func compareItems(src, dst *OacClient, a, b *CatalogItem, byHash bool) (string, error) {
+	if a.Type != b.Type {
+		return DiffDifferent, nil
+	}
+	if !byHash {
+		if a.LastModified == b.LastModified {
+			return DiffSame, nil
+		}
+		return DiffDifferent, nil
+	}
+
+	ha, err := itemHash(src, a)
+	if err != nil {
+		return "", err
+	}
+	hb, err := itemHash(dst, b)
+	if err != nil {
+		return "", err
+	}
+	if ha == hb {
+		return DiffSame, nil
+	}
+	return DiffDifferent, nil
+}
+
+// itemHash returns the SHA-256 of an item's exported definition
+func itemHash(c *OacClient, item *CatalogItem) (string, error) {
+	archive, err := c.ExportItem(item.Type, item.FullPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", item.FullPath(), err)
+	}
+	sum := sha256.Sum256(archive)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,46 @@
+package oac
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// goos is the operating system the directories are laid out for
+var goos = runtime.GOOS
+
+// CacheDir returns the directory of the token, ETag, response and completion caches:
+// %LocalAppData%\oac-client on Windows, ~/.cache/oac-client elsewhere
+func CacheDir() string {
+	return userDir(os.UserCacheDir, ".cache")
+}
+
+// ConfigDir returns the directory of the config file: %AppData%\oac-client on Windows,
+// ~/.config/oac-client elsewhere
+func ConfigDir() string {
+	return userDir(os.UserConfigDir, ".config")
+}
+
+// StateDir returns the directory of the request history: %LocalAppData%\oac-client on
+// Windows, ~/.local/state/oac-client elsewhere
+func StateDir() string {
+	return userDir(os.UserCacheDir, filepath.Join(".local", "state"))
+}
+
+// userDir resolves an oac-client directory. Windows has no HOME, so its per-user
+// directories are used there; other systems keep the XDG layout under the home directory,
+// also on macOS where the os package would pick ~/Library.
+func userDir(windowsDir func() (string, error), homeRelative string) string {
+	if goos == "windows" {
+		if dir, err := windowsDir(); err == nil {
+			return filepath.Join(dir, "oac-client")
+		}
+	}
+	return filepath.Join(homeDir(), homeRelative, "oac-client")
+}
+
+// homeDir returns the user's home directory, or "" when it cannot be determined
+func homeDir() string {
+	home, _ := os.UserHomeDir()
+	return home
+}
@@ -0,0 +1,111 @@
+package oac
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	appData := filepath.Join(home, "AppData", "Local")
+
+	tests := []struct {
+		name         string
+		goos         string
+		windowsDir   func() (string, error)
+		homeRelative string
+		want         string
+	}{
+		{
+			name:         "linux",
+			goos:         "linux",
+			windowsDir:   func() (string, error) { return appData, nil },
+			homeRelative: ".cache",
+			want:         filepath.Join(home, ".cache", "oac-client"),
+		},
+		{
+			name:         "darwin keeps the XDG layout",
+			goos:         "darwin",
+			windowsDir:   func() (string, error) { return appData, nil },
+			homeRelative: ".config",
+			want:         filepath.Join(home, ".config", "oac-client"),
+		},
+		{
+			name:         "nested home directory",
+			goos:         "linux",
+			windowsDir:   func() (string, error) { return appData, nil },
+			homeRelative: filepath.Join(".local", "state"),
+			want:         filepath.Join(home, ".local", "state", "oac-client"),
+		},
+		{
+			name:         "windows",
+			goos:         "windows",
+			windowsDir:   func() (string, error) { return appData, nil },
+			homeRelative: ".cache",
+			want:         filepath.Join(appData, "oac-client"),
+		},
+		{
+			name:         "windows without a user directory",
+			goos:         "windows",
+			windowsDir:   func() (string, error) { return "", errors.New("%LocalAppData% is not defined") },
+			homeRelative: ".cache",
+			want:         filepath.Join(home, ".cache", "oac-client"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGOOS(t, tt.goos)
+			if got := userDir(tt.windowsDir, tt.homeRelative); got != tt.want {
+				t.Errorf("userDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "xdg-cache"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		goos string
+		dir  func() string
+		want string
+	}{
+		{"CacheDir", "linux", CacheDir, filepath.Join(home, ".cache", "oac-client")},
+		{"ConfigDir", "linux", ConfigDir, filepath.Join(home, ".config", "oac-client")},
+		{"StateDir", "linux", StateDir, filepath.Join(home, ".local", "state", "oac-client")},
+		{"CacheDir", "windows", CacheDir, filepath.Join(cacheDir, "oac-client")},
+		{"ConfigDir", "windows", ConfigDir, filepath.Join(configDir, "oac-client")},
+		{"StateDir", "windows", StateDir, filepath.Join(cacheDir, "oac-client")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.goos, func(t *testing.T) {
+			setGOOS(t, tt.goos)
+			if got := tt.dir(); got != tt.want {
+				t.Errorf("%s() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// setGOOS lays directories out for another operating system until the test ends
+func setGOOS(t *testing.T, name string) {
+	t.Helper()
+	saved := goos
+	goos = name
+	t.Cleanup(func() { goos = saved })
+}
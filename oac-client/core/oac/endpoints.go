@@ -0,0 +1,67 @@
+package oac
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//go:embed endpoints.yaml
+var endpointsYAML []byte
+
+// KnownEndpoints returns the catalog of OAC REST endpoints built into the client, for
+// discovery when no OpenAPI spec is available. Its operations have no parameters.
+func KnownEndpoints() (*OpenAPISpec, error) {
+	raw, err := SpecToJSON(endpointsYAML)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOpenAPI(raw)
+}
+
+// FetchOpenAPI downloads an OpenAPI 3 document in YAML or JSON with client, see HTTPClient
+func FetchOpenAPI(ctx context.Context, client *http.Client, url string) (*OpenAPISpec, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI spec: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI spec %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI spec: %w", err)
+	}
+	raw, err := SpecToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseOpenAPI(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec %s: %w", url, err)
+	}
+	return spec, nil
+}
+
+// Matches reports whether the path, operationId, summary or a tag of an operation
+// contains filter, ignoring case
+func (op *Operation) Matches(filter string) bool {
+	filter = strings.ToLower(filter)
+	for _, s := range append([]string{op.Path, op.ID, op.Summary}, op.Tags...) {
+		if strings.Contains(strings.ToLower(s), filter) {
+			return true
+		}
+	}
+	return false
+}
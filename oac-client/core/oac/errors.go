@@ -0,0 +1,96 @@
+package oac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when OAC answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	// Code and Message are taken from the JSON error body when present
+	Code    string
+	Message string
+	// OCIRequestID is the opc-request-id header, needed for Oracle support tickets
+	OCIRequestID string
+	Header       http.Header
+	Body         []byte
+}
+
+// newAPIError builds an APIError from a failed response and its body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode:   resp.StatusCode,
+		OCIRequestID: resp.Header.Get("opc-request-id"),
+		Header:       resp.Header,
+		Body:         body,
+	}
+
+	var payload struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		apiErr.Code = payload.Code
+		apiErr.Message = payload.Message
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "request failed: %d", e.StatusCode)
+	switch {
+	case e.Message != "":
+		if e.Code != "" {
+			fmt.Fprintf(&b, " %s:", e.Code)
+		}
+		fmt.Fprintf(&b, " %s", e.Message)
+	case len(e.Body) > 0:
+		fmt.Fprintf(&b, " %s", e.Body)
+	}
+	if e.OCIRequestID != "" {
+		fmt.Fprintf(&b, " (opc-request-id: %s)", e.OCIRequestID)
+	}
+	return b.String()
+}
+
+// AuthError is returned when an access token cannot be obtained
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string { return "authentication failed: " + e.Err.Error() }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// TimeoutError is returned when a request or wait exceeds its deadline
+type TimeoutError struct {
+	Op  string
+	Err error
+}
+
+func (e *TimeoutError) Error() string { return e.Op + " timed out: " + e.Err.Error() }
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// asTimeout wraps err in a *TimeoutError when it represents a deadline or network timeout
+func asTimeout(op string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &TimeoutError{Op: op, Err: err}
+	}
+	return err
+}
+
+// StatusCode returns the HTTP status of an *APIError in err's chain, or 0
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package oac
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxETags caps the number of resources remembered in the ETag cache
+const maxETags = 1000
+
+// etagEntry is a remembered entity tag
+type etagEntry struct {
+	ETag string    `json:"etag"`
+	Seen time.Time `json:"seen"`
+}
+
+// etagCache remembers the ETags of GET responses in a JSON file
+type etagCache struct {
+	mu      sync.Mutex
+	file    string
+	entries map[string]etagEntry
+}
+
+// WithETagCache remembers ETags of GET responses in file and sends them as If-Match
+// on later PUT and PATCH requests to the same resource, preventing lost updates.
+// An empty file selects the per-profile cache below ~/.cache/oac-client.
+func WithETagCache(file string) Option {
+	return func(c *OacClient) {
+		c.etags = &etagCache{file: file}
+	}
+}
+
+// etagFile is the default ETag cache location, one file per profile
+func (c *OacClient) etagFile() string {
+	if name := c.ProfileName(); name != "" {
+		return filepath.Join(cacheDir, "etags_"+name+".json")
+	}
+	return filepath.Join(cacheDir, "etags.json")
+}
+
+// etagKey identifies a resource by instance and path, ignoring the query string
+func (c *OacClient) etagKey(resourcePath string) string {
+	if u, err := url.Parse(resourcePath); err == nil {
+		resourcePath = u.Path
+	}
+	return strings.TrimRight(c.setting("OAC_INSTANCE"), "/") + path.Clean("/"+resourcePath)
+}
+
+// CachedETag returns the ETag last seen for a resource path, if any
+func (c *OacClient) CachedETag(resourcePath string) string {
+	if c.etags == nil {
+		return ""
+	}
+	return c.etags.get(c.etagKey(resourcePath))
+}
+
+// applyETag returns r with If-Match set from the cache for PUT and PATCH requests
+// that do not set it themselves
+func (c *OacClient) applyETag(r *Request) *Request {
+	method := strings.ToUpper(r.Method)
+	if c.etags == nil || r.NoETag || (method != http.MethodPut && method != http.MethodPatch) || r.Header.Get("If-Match") != "" {
+		return r
+	}
+	etag := c.CachedETag(r.Path)
+	if etag == "" {
+		return r
+	}
+
+	withETag := *r
+	withETag.Header = r.Header.Clone()
+	if withETag.Header == nil {
+		withETag.Header = http.Header{}
+	}
+	withETag.Header.Set("If-Match", etag)
+	return &withETag
+}
+
+// storeETag remembers the ETag of a successful GET, and replaces or forgets it after a write
+func (c *OacClient) storeETag(r *Request, resp *http.Response) {
+	if c.etags == nil {
+		return
+	}
+	key := c.etagKey(r.Path)
+	switch strings.ToUpper(r.Method) {
+	case http.MethodGet:
+		if etag := resp.Header.Get("ETag"); etag != "" && resp.StatusCode == http.StatusOK {
+			c.etags.set(key, etag)
+		}
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		// the resource changed; only some endpoints return its new ETag
+		c.etags.set(key, resp.Header.Get("ETag"))
+	}
+}
+
+func (e *etagCache) load() {
+	if e.entries != nil {
+		return
+	}
+	e.entries = map[string]etagEntry{}
+	if data, err := os.ReadFile(e.file); err == nil {
+		_ = json.Unmarshal(data, &e.entries)
+	}
+}
+
+func (e *etagCache) get(key string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.load()
+	return e.entries[key].ETag
+}
+
+// set stores or, with an empty etag, removes an entry and saves the cache
+func (e *etagCache) set(key, etag string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.load()
+
+	if etag == "" {
+		if _, ok := e.entries[key]; !ok {
+			return
+		}
+		delete(e.entries, key)
+	} else {
+		e.entries[key] = etagEntry{ETag: etag, Seen: time.Now()}
+	}
+
+	if len(e.entries) > maxETags {
+		keys := make([]string, 0, len(e.entries))
+		for k := range e.entries {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return e.entries[keys[i]].Seen.Before(e.entries[keys[j]].Seen) })
+		for _, k := range keys[:len(keys)-maxETags] {
+			delete(e.entries, k)
+		}
+	}
+
+	data, err := json.Marshal(e.entries)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.file), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(e.file, data, 0o600)
+}
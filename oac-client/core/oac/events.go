@@ -0,0 +1,84 @@
+package oac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Event is an OCI Events notification in CloudEvents format, as delivered by an
+// OCI Notifications HTTPS subscription
+type Event struct {
+	EventType   string          `json:"eventType"`
+	EventID     string          `json:"eventID"`
+	Source      string          `json:"source"`
+	EventTime   time.Time       `json:"eventTime"`
+	ContentType string          `json:"contentType,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	// Raw is the notification body as received
+	Raw json.RawMessage `json:"-"`
+}
+
+// ResourceName returns data.resourceName, e.g. the snapshot or data flow concerned
+func (e *Event) ResourceName() string {
+	var data struct {
+		ResourceName string `json:"resourceName"`
+	}
+	_ = json.Unmarshal(e.Data, &data)
+	return data.ResourceName
+}
+
+// Matches reports whether the event type matches a glob such as
+// "com.oraclecloud.analytics.*"
+func (e *Event) Matches(pattern string) bool {
+	ok, _ := path.Match(pattern, e.EventType)
+	return ok
+}
+
+// ParseEvent decodes a notification body. Bodies that are not CloudEvents, such as
+// plain Notifications messages, yield an event with only Raw set.
+func ParseEvent(body []byte) (*Event, error) {
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("event body is not JSON")
+	}
+	var e Event
+	_ = json.Unmarshal(body, &e)
+	e.Raw = json.RawMessage(body)
+	return &e, nil
+}
+
+// ConfirmationURLHeader carries the URL to visit to confirm a new Notifications subscription
+const ConfirmationURLHeader = "X-OCI-NS-ConfirmationURL"
+
+// ConfirmSubscription visits the confirmation URL sent with the first message of an
+// OCI Notifications HTTPS subscription with client. Only https URLs on oraclecloud.com
+// hosts are visited, as the header comes from whoever sends the request.
+func ConfirmSubscription(ctx context.Context, client *http.Client, confirmationURL string) error {
+	u, err := url.Parse(confirmationURL)
+	if err != nil {
+		return fmt.Errorf("invalid confirmation URL: %w", err)
+	}
+	if u.Scheme != "https" || !strings.HasSuffix(strings.ToLower(u.Hostname()), ".oraclecloud.com") {
+		return fmt.Errorf("refusing confirmation URL %s: expected https on an oraclecloud.com host", u.Redacted())
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("invalid confirmation URL: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp, body)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SelectFields prunes the objects of a JSON document to the given fields, in the order
+// given, like a GraphQL selection. Arrays are pruned element by element, and dotted
+// fields such as owner.name select inside nested objects. Objects holding none of the
+// fields, such as the {"items": [...]} envelope of list responses, are kept with their
+// objects and arrays pruned, so paging attributes survive.
+func SelectFields(data []byte, fields []string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+	return json.Marshal(selectFields(doc, parseFieldSelection(fields)))
+}
+
+// fieldSelection is a tree of selected fields; a nil subtree selects the whole value
+type fieldSelection struct {
+	names []string
+	sub   map[string]*fieldSelection
+}
+
+func parseFieldSelection(fields []string) *fieldSelection {
+	root := &fieldSelection{sub: map[string]*fieldSelection{}}
+	for _, field := range fields {
+		s := root
+		for _, name := range strings.Split(strings.TrimSpace(field), ".") {
+			if name == "" {
+				break
+			}
+			next, ok := s.sub[name]
+			if !ok {
+				next = &fieldSelection{sub: map[string]*fieldSelection{}}
+				s.sub[name] = next
+				s.names = append(s.names, name)
+			}
+			s = next
+		}
+	}
+	return root
+}
+
+func selectFields(v any, sel *fieldSelection) any {
+	if len(sel.names) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = selectFields(e, sel)
+		}
+		return out
+	case *orderedObject:
+		out := &orderedObject{values: map[string]any{}}
+		for _, name := range sel.names {
+			if value, ok := v.values[name]; ok {
+				out.set(name, selectFields(value, sel.sub[name]))
+			}
+		}
+		if len(out.keys) > 0 {
+			return out
+		}
+		// an envelope: prune what it wraps
+		for _, k := range v.keys {
+			out.set(k, selectFields(v.values[k], sel))
+		}
+		return out
+	}
+	return v
+}
+
+// orderedObject is a JSON object that keeps the order of its keys
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *orderedObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered decodes the next JSON value, objects as *orderedObject
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &orderedObject{values: map[string]any{}}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.(string), value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,382 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ResponseSchema infers the shape of JSON responses. Add every sample, e.g. several
+// pages or objects of the same kind; fields missing from some samples become optional
+// and values seen as null become nullable. The zero value is ready to use.
+type ResponseSchema struct {
+	root    schemaNode
+	samples int
+}
+
+// schemaNode is the inferred schema of one value
+type schemaNode struct {
+	// kind is object, array, string, integer, number or boolean, "" while only nulls
+	// were seen and "any" for mixed kinds
+	kind     string
+	nullable bool
+	// seen counts the objects merged into an object node
+	seen  int
+	props []*schemaProp
+	items *schemaNode
+}
+
+type schemaProp struct {
+	name string
+	seen int
+	node schemaNode
+}
+
+// Add merges one JSON document into the schema
+func (s *ResponseSchema) Add(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return fmt.Errorf("invalid JSON sample: %w", err)
+	}
+	s.root.add(doc)
+	s.samples++
+	return nil
+}
+
+func (n *schemaNode) add(v any) {
+	switch v := v.(type) {
+	case nil:
+		n.nullable = true
+	case *orderedObject:
+		n.merge("object")
+		n.seen++
+		for _, k := range v.keys {
+			p := n.prop(k)
+			p.seen++
+			p.node.add(v.values[k])
+		}
+	case []any:
+		n.merge("array")
+		if n.items == nil {
+			n.items = &schemaNode{}
+		}
+		for _, e := range v {
+			n.items.add(e)
+		}
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			n.merge("number")
+		} else {
+			n.merge("integer")
+		}
+	case string:
+		n.merge("string")
+	case bool:
+		n.merge("boolean")
+	}
+}
+
+func (n *schemaNode) merge(kind string) {
+	switch {
+	case n.kind == "" || n.kind == kind:
+		n.kind = kind
+	case n.kind == "integer" && kind == "number", n.kind == "number" && kind == "integer":
+		n.kind = "number"
+	default:
+		n.kind = "any"
+	}
+}
+
+func (n *schemaNode) prop(name string) *schemaProp {
+	for _, p := range n.props {
+		if p.name == name {
+			return p
+		}
+	}
+	p := &schemaProp{name: name}
+	n.props = append(n.props, p)
+	return p
+}
+
+// required reports whether every object merged into the parent had the property
+func (p *schemaProp) required(parent *schemaNode) bool {
+	return p.seen == parent.seen
+}
+
+// JSONSchema returns the inferred schema as a JSON Schema document titled name
+func (s *ResponseSchema) JSONSchema(name string) ([]byte, error) {
+	if s.samples == 0 {
+		return nil, fmt.Errorf("no samples to infer a schema from")
+	}
+	doc := s.root.jsonSchema()
+	out := &orderedObject{values: map[string]any{}}
+	out.set("$schema", "https://json-schema.org/draft/2020-12/schema")
+	out.set("title", name)
+	for _, k := range doc.keys {
+		out.set(k, doc.values[k])
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+func (n *schemaNode) jsonSchema() *orderedObject {
+	out := &orderedObject{values: map[string]any{}}
+	switch n.kind {
+	case "", "any":
+		return out
+	}
+	if n.nullable {
+		out.set("type", []string{n.kind, "null"})
+	} else {
+		out.set("type", n.kind)
+	}
+	switch n.kind {
+	case "object":
+		props := &orderedObject{values: map[string]any{}}
+		required := []string{}
+		for _, p := range n.props {
+			props.set(p.name, p.node.jsonSchema())
+			if p.required(n) {
+				required = append(required, p.name)
+			}
+		}
+		out.set("properties", props)
+		if len(required) > 0 {
+			out.set("required", required)
+		}
+	case "array":
+		if n.items != nil {
+			out.set("items", n.items.jsonSchema())
+		}
+	}
+	return out
+}
+
+// typeGenerator emits named types for the objects of a schema, root first
+type typeGenerator struct {
+	buf   bytes.Buffer
+	used  map[string]bool
+	queue []namedNode
+}
+
+type namedNode struct {
+	name, doc string
+	node      *schemaNode
+}
+
+// typeName reserves a unique type name for an object and queues its definition
+func (g *typeGenerator) typeName(name, doc string, n *schemaNode) string {
+	unique := name
+	for i := 2; g.used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.used[unique] = true
+	g.queue = append(g.queue, namedNode{name: unique, doc: doc, node: n})
+	return unique
+}
+
+// GoTypes returns Go source declaring a struct named name for the inferred schema,
+// and one struct per nested object, in package pkg. source, e.g. "GET /api/...",
+// is recorded in the generated header.
+func (s *ResponseSchema) GoTypes(pkg, name, source string) ([]byte, error) {
+	if s.samples == 0 {
+		return nil, fmt.Errorf("no samples to infer a schema from")
+	}
+	g := &typeGenerator{used: map[string]bool{}}
+	fmt.Fprintf(&g.buf, "// Code generated by oac-client gen-types from %s; DO NOT EDIT.\n\npackage %s\n", source, pkg)
+
+	root := &s.root
+	if root.kind != "object" {
+		// a list or scalar response gets a named type for the whole document
+		fmt.Fprintf(&g.buf, "\n// %s is the response of %s\ntype %s %s\n", name, source, name, g.goType(root, name, "the response of "+source, false))
+	} else {
+		g.typeName(name, "the response of "+source, root)
+	}
+	for len(g.queue) > 0 {
+		t := g.queue[0]
+		g.queue = g.queue[1:]
+		fmt.Fprintf(&g.buf, "\n// %s is %s\ntype %s struct {\n", t.name, t.doc, t.name)
+		fields := map[string]bool{}
+		for _, p := range t.node.props {
+			field := goFieldName(p.name)
+			for i := 2; fields[field]; i++ {
+				field = goFieldName(p.name) + strconv.Itoa(i)
+			}
+			fields[field] = true
+			typ := g.goType(&p.node, t.name+field, fieldDoc(p.name, t.name), true)
+			tag := p.name
+			if !p.required(t.node) {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&g.buf, "\t%s %s `json:%s`\n", field, typ, strconv.Quote(tag))
+		}
+		g.buf.WriteString("}\n")
+	}
+	return format.Source(g.buf.Bytes())
+}
+
+// goType returns the Go type of a value, declaring objects as structs named name
+func (g *typeGenerator) goType(n *schemaNode, name, doc string, pointer bool) string {
+	var typ string
+	switch n.kind {
+	case "object":
+		typ = g.typeName(name, doc, n)
+	case "array":
+		if n.items == nil {
+			return "[]any"
+		}
+		return "[]" + g.goType(n.items, itemTypeName(name), "an element of "+doc, false)
+	case "string":
+		typ = "string"
+	case "integer":
+		typ = "int64"
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	default:
+		return "any"
+	}
+	if pointer && n.nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// itemTypeName names the elements of an array type, DatasetsItem for DatasetsItems
+func itemTypeName(name string) string {
+	return strings.TrimSuffix(name, "Items") + "Item"
+}
+
+// fieldDoc describes the value of a field for the doc comment of its type
+func fieldDoc(field, parent string) string {
+	return fmt.Sprintf("the %s field of %s", field, parent)
+}
+
+// goInitialisms are words written in capitals in Go identifiers
+var goInitialisms = map[string]bool{
+	"API": true, "CSV": true, "DB": true, "DN": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "OCI": true, "SQL": true, "TTL": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goFieldName returns the Go field name of a JSON key, see GoIdentifier
+func goFieldName(key string) string {
+	if name := GoIdentifier(key); name != "" {
+		return name
+	}
+	return "Field"
+}
+
+// GoIdentifier converts a name such as "datasetId" or "created-by" to an exported Go
+// identifier, DatasetID or CreatedBy, or "" if it holds no letters or digits
+func GoIdentifier(key string) string {
+	var b strings.Builder
+	for _, word := range splitWords(key) {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// splitWords splits an identifier at separators and lower to upper case changes
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	var prev rune
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(prev):
+			words = append(words, string(word))
+			word = []rune{r}
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// TypeScript returns TypeScript interfaces for the inferred schema, the root named name
+func (s *ResponseSchema) TypeScript(name, source string) ([]byte, error) {
+	if s.samples == 0 {
+		return nil, fmt.Errorf("no samples to infer a schema from")
+	}
+	g := &typeGenerator{used: map[string]bool{}}
+	fmt.Fprintf(&g.buf, "// Code generated by oac-client gen-types from %s; DO NOT EDIT.\n", source)
+
+	root := &s.root
+	if root.kind != "object" {
+		fmt.Fprintf(&g.buf, "\n/** The response of %s */\nexport type %s = %s;\n", source, name, g.tsType(root, name, "the response of "+source))
+	} else {
+		g.typeName(name, "the response of "+source, root)
+	}
+	for len(g.queue) > 0 {
+		t := g.queue[0]
+		g.queue = g.queue[1:]
+		fmt.Fprintf(&g.buf, "\n/** %s */\nexport interface %s {\n", strings.ToUpper(t.doc[:1])+t.doc[1:], t.name)
+		for _, p := range t.node.props {
+			key := p.name
+			if !identifier.MatchString(key) || strings.Contains(key, "-") {
+				key = strconv.Quote(key)
+			}
+			if !p.required(t.node) {
+				key += "?"
+			}
+			fmt.Fprintf(&g.buf, "  %s: %s;\n", key, g.tsType(&p.node, t.name+goFieldName(p.name), fieldDoc(p.name, t.name)))
+		}
+		g.buf.WriteString("}\n")
+	}
+	return g.buf.Bytes(), nil
+}
+
+// tsType returns the TypeScript type of a value, declaring objects as interfaces
+func (g *typeGenerator) tsType(n *schemaNode, name, doc string) string {
+	var typ string
+	switch n.kind {
+	case "object":
+		typ = g.typeName(name, doc, n)
+	case "array":
+		typ = "unknown[]"
+		if n.items != nil {
+			typ = g.tsType(n.items, itemTypeName(name), "an element of "+doc)
+			if strings.Contains(typ, " ") {
+				typ = "(" + typ + ")"
+			}
+			typ += "[]"
+		}
+	case "string":
+		typ = "string"
+	case "integer", "number":
+		typ = "number"
+	case "boolean":
+		typ = "boolean"
+	default:
+		return "unknown"
+	}
+	if n.nullable {
+		return typ + " | null"
+	}
+	return typ
+}
@@ -0,0 +1,136 @@
+package oac
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+)
+
+// Get performs a GET and decodes the JSON response into a T, e.g.
+//
+//	snap, err := oac.Get[oac.Snapshot](ctx, client, "/api/20210901/snapshots/"+id)
+func Get[T any](ctx context.Context, c *OacClient, path string) (T, error) {
+	return Call[T](ctx, c, "GET", path, nil)
+}
+
+// Post sends body as JSON, a []byte or json.RawMessage as-is and nil as no body, and
+// decodes the JSON response into a T
+func Post[T any](ctx context.Context, c *OacClient, path string, body any) (T, error) {
+	return Call[T](ctx, c, "POST", path, body)
+}
+
+// Call is Get and Post for any method. An empty response leaves T at its zero value.
+func Call[T any](ctx context.Context, c *OacClient, method, path string, body any) (T, error) {
+	var out T
+	data, err := marshalBody(body)
+	if err != nil {
+		return out, err
+	}
+	resp, err := c.exchangeRequest(&Request{Method: method, Path: path, Body: bytes.NewReader(data), Context: ctx})
+	if err != nil {
+		return out, err
+	}
+	if len(bytes.TrimSpace(resp.Bytes())) == 0 {
+		return out, nil
+	}
+	if err := resp.DecodeInto(&out); err != nil {
+		return out, fmt.Errorf("invalid response from %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// Pager iterates over the items of a paged list, following the oa-next-page header
+// until the instance reports no next page. Check Err after the loop, as with
+// bufio.Scanner:
+//
+//	pages := oac.Paginate[oac.Snapshot](ctx, client, "/api/20210901/snapshots")
+//	for snap := range pages.All() {
+//		fmt.Println(snap.Name)
+//	}
+//	if err := pages.Err(); err != nil {
+//		return err
+//	}
+type Pager[T any] struct {
+	ctx    context.Context
+	client *OacClient
+	req    Request
+	// body is the request body, read once and sent with every page
+	body []byte
+	err  error
+}
+
+// Paginate returns a Pager over the items of the list at path, which are read from the
+// "items" field of every page
+func Paginate[T any](ctx context.Context, c *OacClient, path string) *Pager[T] {
+	return PaginateRequest[T](ctx, c, Request{Method: "GET", Path: path})
+}
+
+// PaginateRequest is Paginate for a list request with its own query parameters and headers
+func PaginateRequest[T any](ctx context.Context, c *OacClient, r Request) *Pager[T] {
+	if r.Method == "" {
+		r.Method = "GET"
+	}
+	return &Pager[T]{ctx: ctx, client: c, req: r}
+}
+
+// All returns an iterator over the items of every page. Pages are requested as the
+// loop reaches them, so breaking out early skips the rest.
+func (p *Pager[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		p.err = nil
+		if p.req.Body != nil && p.body == nil {
+			body, err := io.ReadAll(p.req.Body)
+			if err != nil {
+				p.err = fmt.Errorf("failed to read request body: %w", err)
+				return
+			}
+			p.body = body
+		}
+
+		page := ""
+		for {
+			req := p.req
+			req.Context = p.ctx
+			if p.body != nil {
+				req.Body = bytes.NewReader(p.body)
+			}
+			if page != "" {
+				req.Query = url.Values{}
+				for key, values := range p.req.Query {
+					req.Query[key] = values
+				}
+				req.Query.Set("page", page)
+			}
+			resp, err := p.client.exchangeRequest(&req)
+			if err != nil {
+				p.err = err
+				return
+			}
+			var list struct {
+				Items []T `json:"items"`
+			}
+			if err := resp.DecodeInto(&list); err != nil {
+				p.err = fmt.Errorf("invalid response from %s: %w", p.req.Path, err)
+				return
+			}
+			for _, item := range list.Items {
+				if !yield(item) {
+					return
+				}
+			}
+
+			page = resp.Header.Get("oa-next-page")
+			if page == "" || len(list.Items) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that ended the last iteration early, if any
+func (p *Pager[T]) Err() error {
+	return p.err
+}
@@ -0,0 +1,66 @@
+package oac_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"oac-client/core/oac"
+)
+
+func TestPagerSendsBodyWithEveryPage(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "t", "token_type": "Bearer", "expires_in": 3600})
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			w.Header().Set("oa-next-page", strconv.Itoa(page+1))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": []string{"item" + strconv.Itoa(page)}})
+	}))
+	defer srv.Close()
+	profile := &oac.Profile{Name: "pager", Env: map[string]string{
+		"OAC_INSTANCE": srv.URL, "IDCS_TOKEN_URL": srv.URL + "/token", "IDCS_GRANT_TYPE": "client_credentials",
+		"IDCS_OAC_CLIENT_ID": "id", "IDCS_OAC_CLIENT_SECRET": "secret", "IDCS_OAC_SCOPE": "scope",
+	}}
+	for key := range profile.Env {
+		t.Setenv(key, "")
+	}
+	client, err := oac.NewOacClient(oac.WithProfile(profile), oac.WithTokenStore(oac.NewMemoryTokenStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := oac.PaginateRequest[string](context.Background(), client, oac.Request{
+		Method: "POST", Path: "/api/20210901/search", Body: strings.NewReader(`{"q":"revenue"}`),
+	})
+	for range 2 {
+		bodies = nil
+		var items []string
+		for item := range pages.All() {
+			items = append(items, item)
+		}
+		if err := pages.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Errorf("items = %v, want 3", items)
+		}
+		for i, body := range bodies {
+			if body != `{"q":"revenue"}` {
+				t.Errorf("page %d sent body %q", i, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package oac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultHealthPath is the authenticated endpoint Health calls, a listing of /shared
+var DefaultHealthPath = apiBase + "/catalog/folders/" + EncodeCatalogID("/shared") + "/items"
+
+// HealthOptions controls Health
+type HealthOptions struct {
+	// APIPath is the authenticated endpoint called, DefaultHealthPath when empty
+	APIPath string
+	// Timeout bounds every check, 10s when zero
+	Timeout time.Duration
+}
+
+// HealthCheck is the outcome of one check of Health
+type HealthCheck struct {
+	// Name is instance, idcs or api
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	OK   bool   `json:"ok"`
+	// Status is the HTTP status received, 0 when there was no response
+	Status    int           `json:"status,omitempty"`
+	Latency   time.Duration `json:"-"`
+	LatencyMs int64         `json:"latencyMs"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// HealthReport is the outcome of Health; OK is set when every check passed
+type HealthReport struct {
+	Instance string        `json:"instance"`
+	OK       bool          `json:"ok"`
+	Checks   []HealthCheck `json:"checks"`
+}
+
+// Failed returns the names of the checks that did not pass
+func (r *HealthReport) Failed() []string {
+	var names []string
+	for _, ch := range r.Checks {
+		if !ch.OK {
+			names = append(names, ch.Name)
+		}
+	}
+	return names
+}
+
+// Health checks the instance and its identity domain, measuring the latency of each:
+//   - instance: the instance answers an unauthenticated GET of its root with a status
+//     below 500 (a redirect to the login page counts as alive)
+//   - idcs: the token endpoint issues a new token; for browser and device logins without
+//     a refresh token, which would need the user, it only has to answer below 500
+//   - api: an authenticated GET of APIPath succeeds
+func (c *OacClient) Health(ctx context.Context, opts HealthOptions) *HealthReport {
+	if opts.APIPath == "" {
+		opts.APIPath = DefaultHealthPath
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	instance := strings.TrimRight(c.setting("OAC_INSTANCE"), "/")
+	report := &HealthReport{Instance: instance}
+
+	run := func(name, url string, check func(ctx context.Context) (int, error)) bool {
+		ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		start := time.Now()
+		status, err := check(ctx)
+		ch := HealthCheck{Name: name, URL: url, OK: err == nil, Status: status, Latency: time.Since(start)}
+		ch.LatencyMs = ch.Latency.Milliseconds()
+		if err != nil {
+			ch.Error = err.Error()
+		}
+		report.Checks = append(report.Checks, ch)
+		return ch.OK
+	}
+
+	run("instance", instance+"/", func(ctx context.Context) (int, error) {
+		return c.probe(ctx, instance+"/")
+	})
+
+	tokenURL := c.setting("IDCS_TOKEN_URL")
+	tokenOK := run("idcs", tokenURL, func(ctx context.Context) (int, error) {
+		grant := c.setting("IDCS_GRANT_TYPE")
+		if (grant == "authorization_code" || grant == "device_code") && c.RefreshToken == "" {
+			return c.probe(ctx, tokenURL)
+		}
+		c.tokenMu.Lock()
+		defer c.tokenMu.Unlock()
+		unlock := c.lockTokenCache(ctx)
+		defer unlock()
+		if err := c.obtainToken(ctx); err != nil {
+			return 0, err
+		}
+		return http.StatusOK, nil
+	})
+
+	apiURL, _ := requestURL(instance, c.resolvePath(opts.APIPath), nil)
+	run("api", apiURL, func(ctx context.Context) (int, error) {
+		if !tokenOK {
+			return 0, fmt.Errorf("skipped, no token")
+		}
+		resp, err := c.exchangeRequest(&Request{Method: "GET", Path: opts.APIPath, Context: ctx})
+		if err != nil {
+			return StatusCode(err), err
+		}
+		return resp.StatusCode, nil
+	})
+
+	report.OK = len(report.Failed()) == 0
+	return report
+}
+
+// probe sends an unauthenticated GET without following redirects and fails on
+// connection errors and 5xx statuses
+func (c *OacClient) probe(ctx context.Context, url string) (int, error) {
+	if url == "" || url == "/" {
+		return 0, errors.New("URL not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := *c.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return resp.StatusCode, fmt.Errorf("status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
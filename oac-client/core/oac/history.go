@@ -0,0 +1,242 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxHistoryBody is the largest request body kept in the history for replay
+const maxHistoryBody = 64 << 10
+
+// HistoryEntry is one executed request in the audit log
+type HistoryEntry struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Profile    string    `json:"profile,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Caller     string    `json:"caller,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Body       string    `json:"body,omitempty"`
+	// Redacted is set when credentials were removed from Body, which then cannot be replayed
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// redactedValue replaces credentials in recorded request bodies
+const redactedValue = "REDACTED"
+
+// secretFieldWords mark the JSON fields whose values are credentials, compared with
+// field names lowercased and stripped of - and _
+var secretFieldWords = []string{"password", "passphrase", "secret", "privatekey", "apikey", "token", "credential"}
+
+// DefaultHistoryPath returns OAC_HISTORY_FILE or history.jsonl in StateDir,
+// and "" when OAC_HISTORY_FILE is "off"
+func DefaultHistoryPath() string {
+	switch p := os.Getenv("OAC_HISTORY_FILE"); p {
+	case "off":
+		return ""
+	case "":
+		return filepath.Join(StateDir(), "history.jsonl")
+	default:
+		return p
+	}
+}
+
+// WithHistory appends every executed request to the JSONL audit log at file
+func WithHistory(file string) Option {
+	return func(c *OacClient) {
+		c.historyFile = file
+	}
+}
+
+// historyBody returns the request body for the audit log when it can be read without
+// consuming it and is small text, with the values of credential fields such as the
+// password of a snapshot replaced, and whether any were
+func historyBody(r *Request) (string, bool) {
+	br, ok := r.Body.(*bytes.Reader)
+	if !ok || br.Size() == 0 || br.Size() > maxHistoryBody {
+		return "", false
+	}
+	buf := make([]byte, br.Size())
+	if _, err := br.ReadAt(buf, 0); err != nil || !utf8.Valid(buf) {
+		return "", false
+	}
+	return redactBody(buf)
+}
+
+// redactBody replaces the values of credential fields in a JSON body; other bodies are
+// returned unchanged
+func redactBody(body []byte) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil || !redactSecrets(doc) {
+		return string(body), false
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return "", true
+	}
+	return string(redacted), true
+}
+
+// redactSecrets replaces credential values in a decoded JSON value, reporting whether
+// it found any
+func redactSecrets(v any) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if secretField(k) {
+				if field != nil && field != "" {
+					v[k] = redactedValue
+					found = true
+				}
+				continue
+			}
+			found = redactSecrets(field) || found
+		}
+	case []any:
+		for _, item := range v {
+			found = redactSecrets(item) || found
+		}
+	}
+	return found
+}
+
+func secretField(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, word := range secretFieldWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordHistory appends a request outcome to the audit log. Failures to write the log
+// are reported but never fail the request itself.
+func (c *OacClient) recordHistory(r *Request, body string, redacted bool, start time.Time, resp *http.Response, err error) {
+	if c.historyFile == "" {
+		return
+	}
+
+	entry := HistoryEntry{
+		ID:         newHistoryID(),
+		Time:       start.UTC(),
+		Profile:    c.ProfileName(),
+		Method:     r.Method,
+		Path:       r.Path,
+		Query:      r.Query.Encode(),
+		DurationMs: time.Since(start).Milliseconds(),
+		Body:       body,
+		Redacted:   redacted,
+	}
+	if u, uerr := user.Current(); uerr == nil {
+		entry.Caller = u.Username
+	}
+	if token, _ := c.CachedToken(); token != "" {
+		if claims, cerr := DecodeTokenClaims(token); cerr == nil {
+			entry.Subject = claims.Subject
+		}
+	}
+	if c.actAs != "" {
+		entry.Actor = c.Actor()
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+	}
+	if err != nil {
+		entry.Status = StatusCode(err)
+		entry.Error = err.Error()
+	}
+
+	if werr := appendHistory(c.historyFile, entry); werr != nil {
+		slog.Warn("failed to write history", "file", c.historyFile, "error", werr)
+	}
+}
+
+func appendHistory(file string, entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func newHistoryID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ReadHistory returns the entries of an audit log, oldest first
+func ReadHistory(file string) ([]HistoryEntry, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// lines have no fixed limit: escaping can make a body six times longer, and errors
+	// are kept whole
+	var entries []HistoryEntry
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		var entry HistoryEntry
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &entry) == nil {
+			entries = append(entries, entry)
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+	}
+}
+
+// FindHistory returns the entry with the given id
+func FindHistory(file, id string) (*HistoryEntry, error) {
+	entries, err := ReadHistory(file)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no history entry %s", id)
+}
@@ -0,0 +1,51 @@
+package oac
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     string
+		redacted bool
+	}{
+		{"no credentials", `{"name":"nightly"}`, `{"name":"nightly"}`, false},
+		{"snapshot password", `{"type":"CREATE","name":"b","password":"hunter2"}`, `{"name":"b","password":"REDACTED","type":"CREATE"}`, true},
+		{"nested and spelled differently", `{"connectionParams":{"username":"SALES","client_secret":"s","privateKey":"k"}}`,
+			`{"connectionParams":{"client_secret":"REDACTED","privateKey":"REDACTED","username":"SALES"}}`, true},
+		{"in arrays", `[{"apiKey":"k"},{"n":1.50}]`, `[{"apiKey":"REDACTED"},{"n":1.50}]`, true},
+		{"empty password", `{"password":""}`, `{"password":""}`, false},
+		{"not JSON", `SELECT 1 FROM dual`, `SELECT 1 FROM dual`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, redacted := redactBody([]byte(tt.body))
+			if got != tt.want || redacted != tt.redacted {
+				t.Errorf("redactBody() = %s, %v, want %s, %v", got, redacted, tt.want, tt.redacted)
+			}
+		})
+	}
+}
+
+func TestReadHistoryLongLines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	// control characters are escaped to six bytes each
+	long := strings.Repeat("\x01", maxHistoryBody)
+	for _, entry := range []HistoryEntry{{ID: "a", Body: long}, {ID: "b"}} {
+		if err := appendHistory(file, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := ReadHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Body != long || entries[1].ID != "b" {
+		t.Errorf("read %d entries", len(entries))
+	}
+}
@@ -0,0 +1,249 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// curlValueFlags are curl options whose value is not part of the imported request
+var curlValueFlags = map[string]bool{
+	"-A": true, "--user-agent": true, "-b": true, "--cookie": true, "-c": true, "--cookie-jar": true,
+	"-e": true, "--referer": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-o": true, "--output": true, "-u": true, "--user": true, "-w": true, "--write-out": true,
+	"-x": true, "--proxy": true, "--cacert": true, "--cert": true, "--key": true, "--retry": true,
+}
+
+// ParseCurl converts a curl command line, e.g. one copied from the browser's developer
+// tools, into a batch request. The method, URL and body are kept; headers are dropped,
+// as the client adds its own authentication. @file bodies become body file references.
+func ParseCurl(command string) (BatchRequest, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return BatchRequest{}, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return BatchRequest{}, fmt.Errorf("not a curl command")
+	}
+
+	var method, rawURL string
+	var data []string
+	var dataFile string
+	get := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		inline, hasInline := "", false
+		if strings.HasPrefix(arg, "--") {
+			arg, inline, hasInline = strings.Cut(arg, "=")
+		}
+		value := func() (string, error) {
+			if hasInline {
+				return inline, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("curl option %s needs a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch arg {
+		case "-X", "--request":
+			if method, err = value(); err != nil {
+				return BatchRequest{}, err
+			}
+		case "--url":
+			if rawURL, err = value(); err != nil {
+				return BatchRequest{}, err
+			}
+		case "-H", "--header":
+			if _, err = value(); err != nil {
+				return BatchRequest{}, err
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii", "--data-urlencode", "--json":
+			v, err := value()
+			if err != nil {
+				return BatchRequest{}, err
+			}
+			if strings.HasPrefix(v, "@") && arg != "--data-raw" {
+				dataFile = v[1:]
+				continue
+			}
+			data = append(data, v)
+		case "-G", "--get":
+			get = true
+		default:
+			switch {
+			case curlValueFlags[arg]:
+				if _, err = value(); err != nil {
+					return BatchRequest{}, err
+				}
+			case strings.HasPrefix(arg, "-"):
+				// flags such as -s, -k, -L and --compressed
+			case rawURL == "":
+				rawURL = arg
+			default:
+				return BatchRequest{}, fmt.Errorf("unexpected argument %q", arg)
+			}
+		}
+	}
+	if rawURL == "" {
+		return BatchRequest{}, fmt.Errorf("curl command has no URL")
+	}
+
+	body := strings.Join(data, "&")
+	if get && body != "" {
+		if strings.Contains(rawURL, "?") {
+			rawURL += "&" + body
+		} else {
+			rawURL += "?" + body
+		}
+		body = ""
+	}
+	if method == "" {
+		method = "GET"
+		if body != "" || dataFile != "" {
+			method = "POST"
+		}
+	}
+
+	req := BatchRequest{Method: strings.ToUpper(method)}
+	if req.Path, err = importPath(rawURL); err != nil {
+		return BatchRequest{}, err
+	}
+	switch {
+	case dataFile != "":
+		req.Body, _ = json.Marshal(dataFile)
+	case body != "":
+		req.Body = importBody(body)
+	}
+	return req, nil
+}
+
+// splitShellWords splits a command line like a POSIX shell, handling quotes, escapes and
+// line continuations
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] != '\n' {
+				word.WriteByte(s[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// importPath returns the path and query of a captured URL, relative to the instance
+func importPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
+// importBody keeps a JSON body as-is and sends anything else as a literal string
+func importBody(body string) json.RawMessage {
+	if json.Valid([]byte(body)) {
+		return json.RawMessage(body)
+	}
+	data, _ := json.Marshal(body)
+	return data
+}
+
+// harLog is the part of a HAR file needed to import its requests
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method   string `json:"method"`
+				URL      string `json:"url"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// ReadHAR converts the requests of a HAR file, as saved by the browser's network tab,
+// into batch requests. Unless all is set only calls to the REST API, paths under /api/,
+// are kept, skipping pages, scripts and images.
+func ReadHAR(r io.Reader, all bool) ([]BatchRequest, error) {
+	var har harLog
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+
+	var reqs []BatchRequest
+	for i, entry := range har.Log.Entries {
+		path, err := importPath(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if !all && !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		req := BatchRequest{
+			ID:     fmt.Sprintf("%d", len(reqs)+1),
+			Method: strings.ToUpper(entry.Request.Method),
+			Path:   path,
+		}
+		if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
+			req.Body = importBody(entry.Request.PostData.Text)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
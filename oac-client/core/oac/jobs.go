@@ -0,0 +1,60 @@
+package oac
+
+import (
+	"fmt"
+	"time"
+)
+
+// Job is a recurring task of a jobs file, run as an oac-client command line:
+//
+//	jobs:
+//	  - name: nightly-backup
+//	    schedule: "0 2 * * *"
+//	    args: [snapshot, schedule, --keep, "7", --target-dir, /backups/oac]
+//	  - name: reload-sales
+//	    schedule: "*/30 6-20 * * 1-5"
+//	    profile: prod
+//	    args: [POST, /api/20210901/datasets/sales/reload]
+type Job struct {
+	Name     string        `yaml:"name"`
+	Schedule string        `yaml:"schedule"`
+	Profile  string        `yaml:"profile"`
+	Args     []string      `yaml:"args"`
+	Timeout  time.Duration `yaml:"timeout"`
+
+	Cron *CronSchedule `yaml:"-"`
+}
+
+// LoadJobs reads and validates a jobs file
+func LoadJobs(file string) ([]*Job, error) {
+	var doc struct {
+		Jobs []*Job `yaml:"jobs"`
+	}
+	if err := loadYAML(file, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Jobs) == 0 {
+		return nil, fmt.Errorf("no jobs defined in %s", file)
+	}
+
+	seen := map[string]bool{}
+	for i, job := range doc.Jobs {
+		if job.Name == "" {
+			job.Name = fmt.Sprintf("job-%d", i+1)
+		}
+		if seen[job.Name] {
+			return nil, fmt.Errorf("job %q defined twice in %s", job.Name, file)
+		}
+		seen[job.Name] = true
+
+		if len(job.Args) == 0 {
+			return nil, fmt.Errorf("job %q has no args", job.Name)
+		}
+		cron, err := ParseCron(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		job.Cron = cron
+	}
+	return doc.Jobs, nil
+}
@@ -0,0 +1,143 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// JSONChange is one difference between two JSON documents
+type JSONChange struct {
+	// Path locates the value, e.g. items[2].name; "" is the document itself
+	Path string `json:"path"`
+	// Op is "+" for an added value, "-" for a removed one and "~" for a changed one
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+func (ch JSONChange) String() string {
+	path := ch.Path
+	if path == "" {
+		path = "."
+	}
+	switch ch.Op {
+	case "+":
+		return fmt.Sprintf("+ %s: %s", path, compactJSON(ch.To))
+	case "-":
+		return fmt.Sprintf("- %s: %s", path, compactJSON(ch.From))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", path, compactJSON(ch.From), compactJSON(ch.To))
+}
+
+// DiffJSON compares two JSON documents structurally and returns the keys added, removed
+// and changed from from to to, in document order with object keys sorted. Arrays are
+// compared element by element by position.
+func DiffJSON(from, to []byte) ([]JSONChange, error) {
+	a, err := decodeJSONValue(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid first document: %w", err)
+	}
+	b, err := decodeJSONValue(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid second document: %w", err)
+	}
+	var changes []JSONChange
+	diffJSONValue("", a, b, &changes)
+	return changes, nil
+}
+
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffJSONValue(path string, a, b any, changes *[]JSONChange) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for k := range a {
+				keys = append(keys, k)
+			}
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				av, inA := a[k]
+				bv, inB := b[k]
+				p := jsonKeyPath(path, k)
+				switch {
+				case !inB:
+					*changes = append(*changes, JSONChange{Path: p, Op: "-", From: av})
+				case !inA:
+					*changes = append(*changes, JSONChange{Path: p, Op: "+", To: bv})
+				default:
+					diffJSONValue(p, av, bv, changes)
+				}
+			}
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < max(len(a), len(b)); i++ {
+				p := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(b):
+					*changes = append(*changes, JSONChange{Path: p, Op: "-", From: a[i]})
+				case i >= len(a):
+					*changes = append(*changes, JSONChange{Path: p, Op: "+", To: b[i]})
+				default:
+					diffJSONValue(p, a[i], b[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if an, ok := a.(json.Number); ok {
+		// 1 and 1.0 are the same number
+		if bn, ok := b.(json.Number); ok {
+			af, aerr := an.Float64()
+			bf, berr := bn.Float64()
+			if aerr == nil && berr == nil && af == bf {
+				return
+			}
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, JSONChange{Path: path, Op: "~", From: a, To: b})
+	}
+}
+
+// identifier matches object keys printed without quotes in paths
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func jsonKeyPath(path, key string) string {
+	if !identifier.MatchString(key) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
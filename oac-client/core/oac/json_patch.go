@@ -0,0 +1,339 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of PATCH request bodies
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to doc: objects are merged key by key,
+// null removes a key and any other value, arrays included, replaces the target
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeJSONValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	p, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// JSONPatchOp is one operation of an RFC 6902 JSON patch
+type JSONPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch applies an RFC 6902 JSON patch, a list of add, remove, replace, move, copy
+// and test operations, to doc. The first operation that fails fails the whole patch.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	root, err := decodeJSONValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	var ops []JSONPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch, expected an array of operations: %w", err)
+	}
+	for i, op := range ops {
+		if root, err = applyPatchOp(root, op); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i+1, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyPatchOp(root any, op JSONPatchOp) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+		if value, err = decodeJSONValue(op.Value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		if op.Op == "move" && len(from) == 0 {
+			return nil, fmt.Errorf("cannot move the whole document")
+		}
+		if value, err = pointerGet(root, from); err != nil {
+			return nil, fmt.Errorf("from %s: %w", op.From, err)
+		}
+		if op.Op == "copy" {
+			// the copy must not share maps and slices with the original
+			if value, err = roundTripJSON(value); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if op.Path == op.From {
+			return root, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into itself", op.From)
+		}
+		if root, _, err = pointerUpdate(root, from, removeAt); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		if len(path) == 0 {
+			return value, nil
+		}
+		root, _, err = pointerUpdate(root, path, func(parent any, key string) (any, any, error) {
+			return addAt(parent, key, value)
+		})
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		root, _, err = pointerUpdate(root, path, func(parent any, key string) (any, any, error) {
+			return replaceAt(parent, key, value)
+		})
+	case "remove":
+		if len(path) == 0 {
+			return nil, fmt.Errorf("cannot remove the whole document")
+		}
+		root, _, err = pointerUpdate(root, path, removeAt)
+	case "test":
+		var current any
+		if current, err = pointerGet(root, path); err == nil && !jsonEqual(current, value) {
+			err = fmt.Errorf("test failed: value is %s", compactJSON(current))
+		}
+	}
+	return root, err
+}
+
+// parsePointer splits an RFC 6901 JSON pointer such as /items/0/name into its tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q, expected it to start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// pointerGet returns the value at path
+func pointerGet(node any, path []string) (any, error) {
+	for _, key := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[key]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", key)
+			}
+			node = v
+		case []any:
+			i, err := arrayIndex(key, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("cannot look up %q in a %s", key, jsonKind(node))
+		}
+	}
+	return node, nil
+}
+
+// pointerUpdate calls op with the parent of the value at path and its last token, and
+// stores the parent op returns in place of the old one, which arrays need when they grow
+// or shrink. It returns the new root and the value op returns.
+func pointerUpdate(node any, path []string, op func(parent any, key string) (any, any, error)) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("the whole document has no parent")
+	}
+	if len(path) == 1 {
+		return op(node, path[0])
+	}
+	child, err := pointerGet(node, path[:1])
+	if err != nil {
+		return nil, nil, err
+	}
+	child, result, err := pointerUpdate(child, path[1:], op)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		n[path[0]] = child
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		n[i] = child
+	}
+	return node, result, nil
+}
+
+func addAt(parent any, key string, value any) (any, any, error) {
+	switch n := parent.(type) {
+	case map[string]any:
+		n[key] = value
+		return n, nil, nil
+	case []any:
+		i := len(n)
+		if key != "-" {
+			var err error
+			if i, err = arrayIndex(key, len(n)); err != nil {
+				return nil, nil, err
+			}
+		}
+		n = append(n, nil)
+		copy(n[i+1:], n[i:])
+		n[i] = value
+		return n, nil, nil
+	}
+	return nil, nil, fmt.Errorf("cannot add %q to a %s", key, jsonKind(parent))
+}
+
+func replaceAt(parent any, key string, value any) (any, any, error) {
+	if _, err := pointerGet(parent, []string{key}); err != nil {
+		return nil, nil, err
+	}
+	switch n := parent.(type) {
+	case map[string]any:
+		n[key] = value
+	case []any:
+		i, _ := strconv.Atoi(key)
+		n[i] = value
+	}
+	return parent, nil, nil
+}
+
+func removeAt(parent any, key string) (any, any, error) {
+	old, err := pointerGet(parent, []string{key})
+	if err != nil {
+		return nil, nil, err
+	}
+	switch n := parent.(type) {
+	case map[string]any:
+		delete(n, key)
+		return n, old, nil
+	case []any:
+		i, _ := strconv.Atoi(key)
+		return append(n[:i:i], n[i+1:]...), old, nil
+	}
+	return parent, old, nil
+}
+
+// arrayIndex parses an array index token, which must not exceed max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "number"
+}
+
+// jsonEqual compares decoded JSON values, numbers by value
+func jsonEqual(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func roundTripJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(data)
+}
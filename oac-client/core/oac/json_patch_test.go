@@ -0,0 +1,78 @@
+package oac
+
+import "testing"
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"name":"sales","tags":["a","b"],"owner":{"id":1}}`
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{"add member", `[{"op":"add","path":"/desc","value":"x"}]`, `{"desc":"x","name":"sales","owner":{"id":1},"tags":["a","b"]}`, false},
+		{"add inserts", `[{"op":"add","path":"/tags/1","value":"z"}]`, `{"name":"sales","owner":{"id":1},"tags":["a","z","b"]}`, false},
+		{"add appends", `[{"op":"add","path":"/tags/-","value":"z"}]`, `{"name":"sales","owner":{"id":1},"tags":["a","b","z"]}`, false},
+		{"add past the end", `[{"op":"add","path":"/tags/3","value":"z"}]`, "", true},
+		{"add to a missing parent", `[{"op":"add","path":"/x/y","value":1}]`, "", true},
+		{"add root", `[{"op":"add","path":"","value":[1]}]`, `[1]`, false},
+		{"remove", `[{"op":"remove","path":"/tags/0"}]`, `{"name":"sales","owner":{"id":1},"tags":["b"]}`, false},
+		{"remove missing", `[{"op":"remove","path":"/desc"}]`, "", true},
+		{"remove root", `[{"op":"remove","path":""}]`, "", true},
+		{"replace", `[{"op":"replace","path":"/owner/id","value":2}]`, `{"name":"sales","owner":{"id":2},"tags":["a","b"]}`, false},
+		{"replace missing", `[{"op":"replace","path":"/desc","value":"x"}]`, "", true},
+		{"move", `[{"op":"move","from":"/name","path":"/title"}]`, `{"owner":{"id":1},"tags":["a","b"],"title":"sales"}`, false},
+		{"move to itself", `[{"op":"move","from":"/name","path":"/name"}]`, `{"name":"sales","owner":{"id":1},"tags":["a","b"]}`, false},
+		{"move into itself", `[{"op":"move","from":"/owner","path":"/owner/self"}]`, "", true},
+		{"move root", `[{"op":"move","from":"","path":"/copy"}]`, "", true},
+		{"copy", `[{"op":"copy","from":"/owner","path":"/creator"},{"op":"replace","path":"/creator/id","value":3}]`, `{"creator":{"id":3},"name":"sales","owner":{"id":1},"tags":["a","b"]}`, false},
+		{"test passes", `[{"op":"test","path":"/owner/id","value":1.0}]`, `{"name":"sales","owner":{"id":1},"tags":["a","b"]}`, false},
+		{"test fails", `[{"op":"test","path":"/name","value":"hr"}]`, "", true},
+		{"escaped pointer", `[{"op":"add","path":"/a~1b~0c","value":1}]`, `{"a/b~c":1,"name":"sales","owner":{"id":1},"tags":["a","b"]}`, false},
+		{"leading zero index", `[{"op":"remove","path":"/tags/01"}]`, "", true},
+		{"pointer without slash", `[{"op":"remove","path":"name"}]`, "", true},
+		{"missing value", `[{"op":"add","path":"/desc"}]`, "", true},
+		{"unknown op", `[{"op":"merge","path":"/name"}]`, "", true},
+		{"failure discards earlier ops", `[{"op":"remove","path":"/name"},{"op":"remove","path":"/name"}]`, "", true},
+		{"not an array", `{"op":"remove","path":"/name"}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(doc), []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JSONPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("JSONPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"set", `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`},
+		{"null removes", `{"a":1,"b":2}`, `{"a":null}`, `{"b":2}`},
+		{"nested", `{"a":{"x":1,"y":2}}`, `{"a":{"y":null,"z":3}}`, `{"a":{"x":1,"z":3}}`},
+		{"arrays replace", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"object over scalar", `{"a":1}`, `{"a":{"b":null,"c":1}}`, `{"a":{"c":1}}`},
+		{"non-object patch", `{"a":1}`, `[1]`, `[1]`},
+		{"large numbers", `{"id":12345678901234567890}`, `{}`, `{"id":12345678901234567890}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MergePatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package oac
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric is a Prometheus metric family
+type Metric struct {
+	Name string
+	Help string
+	// Type is gauge or counter
+	Type    string
+	Samples []Sample
+}
+
+// Sample is one value of a Metric; Labels holds label names and values in turn
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// add appends a sample with the given label names and values
+func (m *Metric) add(value float64, labels ...string) {
+	m.Samples = append(m.Samples, Sample{Labels: labels, Value: value})
+}
+
+// WriteMetrics writes metrics in the Prometheus text exposition format
+func WriteMetrics(w io.Writer, metrics []Metric) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		bw.WriteString("# HELP " + m.Name + " " + m.Help + "\n")
+		bw.WriteString("# TYPE " + m.Name + " " + m.Type + "\n")
+		for _, s := range m.Samples {
+			bw.WriteString(m.Name)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i := 0; i+1 < len(s.Labels); i += 2 {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(s.Labels[i] + `="` + escapeLabel(s.Labels[i+1]) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatMetricValue(s.Value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// formatMetricValue prints whole numbers such as timestamps without an exponent
+func formatMetricValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+// metricSource collects the metrics of one API
+type metricSource struct {
+	name    string
+	collect func() ([]Metric, error)
+}
+
+// CollectMetrics scrapes operational metrics of the instance: sessions, query cache
+// usage, agents and snapshots. A source that fails is reported through
+// oac_scrape_success instead of failing the whole scrape.
+func (c *OacClient) CollectMetrics() []Metric {
+	success := Metric{Name: "oac_scrape_success", Help: "Whether the last scrape of the source succeeded.", Type: "gauge"}
+	duration := Metric{Name: "oac_scrape_duration_seconds", Help: "How long the last scrape of the source took.", Type: "gauge"}
+
+	var metrics []Metric
+	for _, src := range []metricSource{
+		{"sessions", c.sessionMetrics},
+		{"cache", c.cacheMetrics},
+		{"agents", c.agentMetrics},
+		{"snapshots", c.snapshotMetrics},
+	} {
+		start := time.Now()
+		m, err := src.collect()
+		duration.add(time.Since(start).Seconds(), "source", src.name)
+		if err != nil {
+			slog.Warn("failed to scrape metrics", "source", src.name, "error", err)
+			success.add(0, "source", src.name)
+			continue
+		}
+		success.add(1, "source", src.name)
+		metrics = append(metrics, m...)
+	}
+	return append(metrics, success, duration)
+}
+
+func (c *OacClient) sessionMetrics() ([]Metric, error) {
+	sessions, err := c.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	active := Metric{Name: "oac_sessions_active", Help: "Open user sessions.", Type: "gauge"}
+	queries := Metric{Name: "oac_sessions_running_queries", Help: "Queries running in open sessions.", Type: "gauge"}
+	running := 0
+	for _, s := range sessions {
+		running += s.RunningQueries
+	}
+	active.add(float64(len(sessions)))
+	queries.add(float64(running))
+	return []Metric{active, queries}, nil
+}
+
+func (c *OacClient) cacheMetrics() ([]Metric, error) {
+	stats, err := c.GetCacheStats()
+	if err != nil {
+		return nil, err
+	}
+	entries := Metric{Name: "oac_cache_entries", Help: "Entries in the query cache.", Type: "gauge"}
+	hits := Metric{Name: "oac_cache_hits_total", Help: "Queries answered from the query cache.", Type: "counter"}
+	misses := Metric{Name: "oac_cache_misses_total", Help: "Queries not found in the query cache.", Type: "counter"}
+	ratio := Metric{Name: "oac_cache_hit_ratio", Help: "Share of queries answered from the query cache.", Type: "gauge"}
+	entries.add(float64(stats.Entries))
+	hits.add(float64(stats.Hits))
+	misses.add(float64(stats.Misses))
+	ratio.add(stats.HitRatio())
+	return []Metric{entries, hits, misses, ratio}, nil
+}
+
+func (c *OacClient) agentMetrics() ([]Metric, error) {
+	agents, err := c.ListAgents()
+	if err != nil {
+		return nil, err
+	}
+	count := Metric{Name: "oac_agents", Help: "Agents by schedule state.", Type: "gauge"}
+	failed := Metric{Name: "oac_agent_last_run_failed", Help: "Whether the last delivery of the agent failed.", Type: "gauge"}
+	enabled, failures := 0, 0
+	for _, a := range agents {
+		if a.Enabled {
+			enabled++
+		}
+		if strings.EqualFold(a.LastRunStatus, "FAILED") {
+			failures++
+			failed.add(1, "agent", a.Name, "path", a.Path)
+		}
+	}
+	count.add(float64(enabled), "state", "enabled")
+	count.add(float64(len(agents)-enabled), "state", "disabled")
+	failedCount := Metric{Name: "oac_agents_failed", Help: "Agents whose last delivery failed.", Type: "gauge"}
+	failedCount.add(float64(failures))
+	return []Metric{count, failedCount, failed}, nil
+}
+
+func (c *OacClient) snapshotMetrics() ([]Metric, error) {
+	snaps, err := c.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	count := Metric{Name: "oac_snapshots", Help: "Snapshots on the instance.", Type: "gauge"}
+	size := Metric{Name: "oac_snapshot_size_bytes", Help: "Size of the snapshot.", Type: "gauge"}
+	created := Metric{Name: "oac_snapshot_created_timestamp_seconds", Help: "When the snapshot was created.", Type: "gauge"}
+	count.add(float64(len(snaps)))
+	for _, s := range snaps {
+		if s.Size > 0 {
+			size.add(float64(s.Size), "snapshot", s.Name, "id", s.ID)
+		}
+		if t := s.Created(); !t.IsZero() {
+			created.add(float64(t.Unix()), "snapshot", s.Name, "id", s.ID)
+		}
+	}
+	return []Metric{count, size, created}, nil
+}
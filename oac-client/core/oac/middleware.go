@@ -0,0 +1,121 @@
+package oac
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// RoundTripFunc sends one attempt of a request to the OAC instance
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of requests to the OAC instance, e.g. to add headers,
+// log or measure. It sees every API request of the client, including uploads, downloads
+// and batch calls, once per attempt and with the Authorization header already set.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// DefaultAuthRetries is how often a request rejected with 401 is sent again with fresh
+// credentials, unless set by WithAuthRetries or OAC_AUTH_RETRIES
+const DefaultAuthRetries = 1
+
+// WithMiddleware adds middleware around the client's requests; the first one given is
+// the outermost
+func WithMiddleware(m ...Middleware) Option {
+	return func(c *OacClient) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+// WithAuthRetries sets how often a request rejected with 401 is replayed with a fresh
+// token; 0 fails on the first 401
+func WithAuthRetries(n int) Option {
+	return func(c *OacClient) {
+		c.authRetries = max(n, 0)
+	}
+}
+
+// authRetriesFromEnv applies OAC_AUTH_RETRIES unless WithAuthRetries was given
+func (c *OacClient) authRetriesFromEnv() error {
+	if c.authRetries >= 0 {
+		return nil
+	}
+	c.authRetries = DefaultAuthRetries
+	if v := c.setting("OAC_AUTH_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid OAC_AUTH_RETRIES %q", v)
+		}
+		c.authRetries = n
+	}
+	return nil
+}
+
+// pipeline returns the chain a request goes through: authentication, the client's
+// middleware, its request hooks, retries, then rate limiting and the HTTP client
+func (c *OacClient) pipeline() RoundTripFunc {
+	next := c.do
+	if c.retries > 0 {
+		next = c.retry(next)
+	}
+	if c.responses != nil {
+		next = c.responses.wrap(c, next)
+	}
+	if len(c.hooks) > 0 {
+		next = c.applyHooks(next)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	return c.authenticate(next)
+}
+
+// authenticate sets the bearer token and, when the instance rejects it with 401,
+// invalidates it and replays the request with a fresh token and a rebuilt body. Streamed
+// bodies without GetBody cannot be replayed and their 401 is returned as is.
+func (c *OacClient) authenticate(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		for attempt := 0; ; attempt++ {
+			token, err := c.GetToken()
+			if err != nil {
+				return nil, err
+			}
+			if attempt > 0 {
+				if req, err = rewind(req); err != nil {
+					return nil, err
+				}
+				req = markRetry(req)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := next(req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || attempt >= c.authRetries || !rewindable(req) {
+				return resp, err
+			}
+			resp.Body.Close()
+
+			slog.Debug("token rejected, retrying with a fresh one", "url", req.URL.Redacted(), "attempt", attempt+1)
+			c.invalidateToken(token)
+		}
+	}
+}
+
+// rewindable reports whether req can be sent again: it has no body or can rebuild it
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req with a fresh body for another attempt, as the previous
+// attempt consumed it
+func rewind(req *http.Request) (*http.Request, error) {
+	req = req.Clone(req.Context())
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild request body: %w", err)
+	}
+	req.Body = body
+	return req, nil
+}
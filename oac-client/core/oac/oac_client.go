@@ -3,13 +3,14 @@ package oac
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -17,58 +18,260 @@ import (
 )
 
 type OacClient struct {
-	AccessToken string
-	TokenExpiry time.Time
+	AccessToken  string
+	TokenExpiry  time.Time
+	RefreshToken string
+	// tokenMu guards the token fields, so concurrent callers share one token request
+	tokenMu sync.Mutex
+	// rejectedToken is the last access token dropped, which is not taken from the cache again
+	rejectedToken string
+
+	profile    *Profile
+	instance   string
+	transport  TransportConfig
+	httpClient *http.Client
+	limiter    *RateLimiter
+	breaker    *CircuitBreaker
+	// breakerSet is true once WithCircuitBreaker chose the breaker, overriding the env
+	breakerSet bool
+	// sharedClient is httpClient without the cassette, for requests outside the API
+	sharedClient *http.Client
+
+	historyFile string
+	compressMin int64
+	etags       *etagCache
+	cacheTTL    time.Duration
+	confirm     ConfirmFunc
+	cassette    *cassette
+	backup      *backup
+	responses   *responseArchive
+	stats       StatsFunc
+	ctx         context.Context
+
+	// actAs is the user tokens are exchanged for, actor the identity exchanging them
+	actAs string
+	actor string
+	// scopeOverride replaces the configured scopes, see WithScopes
+	scopeOverride []string
+	// cachedTokenOnly fails instead of obtaining a token, see WithCachedTokenOnly
+	cachedTokenOnly bool
+
+	tokens      TokenStore
+	middleware  []Middleware
+	hooks       []RequestHook
+	authRetries int
+	retries     int
+	maxResponse int64
+	apiVersion  string
 }
 
-var cacheDir = filepath.Join(os.Getenv("HOME"), ".cache", "oac-client")
-var tokenFile = filepath.Join(cacheDir, "oac_token.json")
+// Option configures an OacClient
+type Option func(*OacClient)
+
+// WithTransport sets proxy, CA bundle and client certificate options
+func WithTransport(cfg TransportConfig) Option {
+	return func(c *OacClient) {
+		c.transport = cfg
+	}
+}
+
+// WithRateLimit throttles requests to perSecond, shared by all goroutines using the client
+func WithRateLimit(perSecond float64) Option {
+	return func(c *OacClient) {
+		if perSecond > 0 {
+			c.limiter = NewRateLimiter(perSecond, 1)
+		}
+	}
+}
 
 // NewOacClient loads config from dotenv
-func NewOacClient() (*OacClient, error) {
-	client := &OacClient{}
-	client.loadTokenFromFile()
+func NewOacClient(opts ...Option) (*OacClient, error) {
+	client := &OacClient{authRetries: -1}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	transport, err := transportConfigFromEnv(client.transport, client.setting)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := sharedHTTPClient(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	client.sharedClient = httpClient
+
+	if client.cassette != nil {
+		if client.cassette.replay {
+			if err := client.cassette.load(); err != nil {
+				return nil, err
+			}
+		}
+		// the shared client must not record or replay for other clients
+		client.cassette.base = httpClient.Transport
+		httpClient = &http.Client{Transport: client.cassette}
+	}
+	client.httpClient = httpClient
+
+	if client.compressMin == 0 {
+		if size := client.setting("OAC_COMPRESS_MIN_SIZE"); size != "" {
+			minSize, err := strconv.ParseInt(size, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OAC_COMPRESS_MIN_SIZE: %w", err)
+			}
+			client.compressMin = minSize
+		}
+	}
+
+	if client.limiter == nil {
+		if rate := client.setting("OAC_RATE_LIMIT"); rate != "" {
+			perSecond, err := ParseRate(rate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OAC_RATE_LIMIT: %w", err)
+			}
+			client.limiter = NewRateLimiter(perSecond, 1)
+		}
+	}
+
+	if err := client.authRetriesFromEnv(); err != nil {
+		return nil, err
+	}
+	if err := client.breakerFromEnv(); err != nil {
+		return nil, err
+	}
+	if err := client.maxResponseFromEnv(); err != nil {
+		return nil, err
+	}
+	if err := client.validateAPIVersion(); err != nil {
+		return nil, err
+	}
+
+	if client.etags != nil && client.etags.file == "" {
+		client.etags.file = client.etagFile()
+	}
+
+	if err := client.hooksFromProfile(); err != nil {
+		return nil, err
+	}
+	if err := client.tokenStoreFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if client.cassette != nil && client.cassette.replay {
+		// recorded calls are answered without checking the token
+		client.AccessToken = "replay"
+		client.TokenExpiry = time.Now().Add(24 * time.Hour)
+		return client, nil
+	}
+
+	client.loadTokenFromCache()
 	return client, nil
 }
 
-// GetToken returns a valid access token, obtaining a new one if expired
+// GetToken returns a valid access token, obtaining a new one if expired. Concurrent
+// callers wait for a single token request instead of each sending their own.
 func (oacClient *OacClient) GetToken() (string, error) {
-	if oacClient.AccessToken != "" && time.Now().Before(oacClient.TokenExpiry) {
+	return oacClient.validToken(0)
+}
+
+// validToken returns a token valid for at least minValidity, from the client, the
+// cache or IDCS
+func (oacClient *OacClient) validToken(minValidity time.Duration) (string, error) {
+	oacClient.tokenMu.Lock()
+	defer oacClient.tokenMu.Unlock()
+
+	if oacClient.AccessToken != "" && time.Until(oacClient.TokenExpiry) > minValidity {
 		return oacClient.AccessToken, nil
 	}
 
-	if err := oacClient.obtainToken(); err != nil {
-		return "", err
+	// another process may have refreshed the shared cache while we waited for its lock
+	unlock := oacClient.lockTokenCache(oacClient.context())
+	defer unlock()
+	oacClient.loadTokenFromCache()
+	if oacClient.AccessToken != "" && time.Until(oacClient.TokenExpiry) > minValidity {
+		return oacClient.AccessToken, nil
+	}
+	if oacClient.cachedTokenOnly {
+		return "", &AuthError{Err: errNoCachedToken}
+	}
+
+	ctx, span := startTokenSpan(oacClient.context(), oacClient.setting("IDCS_GRANT_TYPE"))
+	err := oacClient.obtainToken(ctx)
+	endSpan(span, nil, err)
+	if err != nil {
+		return "", &AuthError{Err: err}
 	}
 
 	return oacClient.AccessToken, nil
 }
 
-// obtainToken performs Resource Owner Password flow to get a new token
-func (oacClient *OacClient) obtainToken() error {
-	idcsURL := strings.TrimRight(os.Getenv("IDCS_TOKEN_URL"), "/")
-	clientID := os.Getenv("IDCS_OAC_CLIENT_ID")
-	clientSecret := os.Getenv("IDCS_OAC_CLIENT_SECRET")
-	scope := os.Getenv("IDCS_OAC_SCOPE")
-	username := os.Getenv("OAC_USERNAME")
-	password := os.Getenv("OAC_PASSWORD")
-	grantType := os.Getenv("IDCS_GRANT_TYPE")
-
-	if clientID == "" || clientSecret == "" || scope == "" || grantType == "" {
+// invalidateToken drops the access token after OAC rejected it, unless another
+// goroutine has already replaced it
+func (oacClient *OacClient) invalidateToken(rejected string) {
+	oacClient.tokenMu.Lock()
+	defer oacClient.tokenMu.Unlock()
+	if oacClient.AccessToken == rejected {
+		oacClient.AccessToken = ""
+	}
+	oacClient.rejectedToken = rejected
+}
+
+// obtainToken gets a new token using the grant selected by IDCS_GRANT_TYPE,
+// preferring a cached refresh token when one is available
+func (oacClient *OacClient) obtainToken(ctx context.Context) error {
+	idcsURL := strings.TrimRight(oacClient.setting("IDCS_TOKEN_URL"), "/")
+	clientID := oacClient.setting("IDCS_OAC_CLIENT_ID")
+	clientSecret := oacClient.setting("IDCS_OAC_CLIENT_SECRET")
+	scopes := oacClient.Scopes()
+	username := oacClient.setting("OAC_USERNAME")
+	password := oacClient.setting("OAC_PASSWORD")
+	grantType := oacClient.setting("IDCS_GRANT_TYPE")
+
+	if clientID == "" || len(scopes) == 0 || grantType == "" {
+		return fmt.Errorf("missing required environment variables")
+	}
+	// browser and device logins may use public clients without a secret
+	if clientSecret == "" && (grantType == "client_credentials" || grantType == "resource_owner") {
 		return fmt.Errorf("missing required environment variables")
 	}
 
-	ctx := context.Background()
+	// token requests go through the same proxy/TLS settings as API calls
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, oacClient.httpClient)
 	var token *oauth2.Token
 	var err error
 
+	// credentials may reference OCI Vault or HashiCorp Vault secrets
+	if clientSecret, err = oacClient.resolveSecret(ctx, clientSecret); err != nil {
+		return err
+	}
+	if password, err = oacClient.resolveSecret(ctx, password); err != nil {
+		return err
+	}
+
+	userCfg := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+		Endpoint:     oacClient.idcsEndpoint(idcsURL),
+	}
+
+	if oacClient.RefreshToken != "" && grantType != "client_credentials" {
+		token, err = userCfg.TokenSource(ctx, &oauth2.Token{RefreshToken: oacClient.RefreshToken}).Token()
+		if err == nil {
+			return oacClient.acceptToken(ctx, userCfg, token)
+		}
+		// fall back to a full login when the refresh token is no longer valid
+		oacClient.RefreshToken = ""
+	}
+
 	switch grantType {
 	case "client_credentials":
 		cfg := clientcredentials.Config{
 			ClientID:     clientID,
 			ClientSecret: clientSecret,
 			TokenURL:     idcsURL,
-			Scopes:       []string{scope},
+			Scopes:       scopes,
 		}
 		token, err = cfg.Token(ctx)
 
@@ -76,15 +279,13 @@ func (oacClient *OacClient) obtainToken() error {
 		if username == "" || password == "" {
 			return fmt.Errorf("username/password must be set for password grant")
 		}
-		cfg := &oauth2.Config{
-			ClientID:     clientID,
-			ClientSecret: clientSecret,
-			Scopes:       []string{scope},
-			Endpoint: oauth2.Endpoint{
-				TokenURL: idcsURL,
-			},
-		}
-		token, err = cfg.PasswordCredentialsToken(ctx, username, password)
+		token, err = userCfg.PasswordCredentialsToken(ctx, username, password)
+
+	case "authorization_code":
+		token, err = authCodeToken(ctx, userCfg, oacClient.setting("IDCS_REDIRECT_PORT"))
+
+	case "device_code":
+		token, err = deviceCodeToken(ctx, userCfg)
 
 	default:
 		return fmt.Errorf("unsupported grant type: %s", grantType)
@@ -93,141 +294,64 @@ func (oacClient *OacClient) obtainToken() error {
 	if err != nil {
 		return fmt.Errorf("failed to obtain token: %w", err)
 	}
+	slog.Debug("obtained access token", "grant", grantType, "expiry", token.Expiry)
+
+	return oacClient.acceptToken(ctx, userCfg, token)
+}
 
+// setToken stores a new token in the client and the on-disk cache
+func (oacClient *OacClient) setToken(token *oauth2.Token) error {
 	oacClient.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		oacClient.RefreshToken = token.RefreshToken
+	}
 	// fallback if expiry is not set
 	if token.Expiry.IsZero() {
 		oacClient.TokenExpiry = time.Now().Add(time.Hour - time.Minute)
 	} else {
 		oacClient.TokenExpiry = token.Expiry.Add(-time.Minute)
 	}
-	oacClient.saveTokenToFile()
+	oacClient.saveTokenToCache()
 
 	return nil
 }
 
-// RestCall executes a REST API call against the OAC instance
-func (c *OacClient) RestCall(method, path, bodyFile string) (string, error) {
-	token, err := c.GetToken()
-	if err != nil {
-		return "", err
-	}
-
-	var bodyBytes []byte
-	if bodyFile != "" {
-		if _, err := os.Stat(bodyFile); err == nil {
-			bodyBytes, err = os.ReadFile(bodyFile)
-			if err != nil {
-				return "", err
-			}
-		} else {
-			bodyBytes = []byte(bodyFile)
-		}
-	}
-
-
-	instanceUrl := os.Getenv("OAC_INSTANCE")
-	url := strings.TrimRight(instanceUrl, "/") + "/" + strings.TrimLeft(path, "/")
-	req, err := http.NewRequest(strings.ToUpper(method), url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 401 {
-		// retry once with fresh token
-		c.AccessToken = ""
-		token, err = c.GetToken()
-		if err != nil {
-			return "", err
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-		resp, err = http.DefaultClient.Do(req)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("request failed: %d %s", resp.StatusCode, body)
-	}
-
-	resBody, err := io.ReadAll(resp.Body)
+// RestCall executes a REST API call against the OAC instance and returns the response;
+// use its Pretty method for the formatted body
+func (c *OacClient) RestCall(method, path, bodyFile string) (*Response, error) {
+	bodyBytes, err := ReadBody(bodyFile)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return prettyPrintJSON(resBody)
-}
-
-// saveTokenToFile caches token on disk
-func (oacClient *OacClient) saveTokenToFile() {
-	os.MkdirAll(cacheDir, os.ModePerm)
-	data := map[string]any{
-		"access_token": oacClient.AccessToken,
-		"expires_at":   oacClient.TokenExpiry.Unix(),
-	}
-	b, _ := json.Marshal(data)
-	_ = os.WriteFile(tokenFile, b, 0600)
+	return c.RestCallWithBody(method, path, bodyBytes)
 }
 
-// loadTokenFromFile loads token cache if present
-func (oacClient *OacClient) loadTokenFromFile() {
-	file, err := os.ReadFile(tokenFile)
-	if err != nil {
-		return
+// ReadBody returns standard input for "-", the contents of bodyFile if it exists,
+// otherwise bodyFile itself
+func ReadBody(bodyFile string) ([]byte, error) {
+	if bodyFile == "" {
+		return nil, nil
 	}
-
-	var data map[string]any
-	if err := json.Unmarshal(file, &data); err != nil {
-		return
+	if bodyFile == "-" {
+		return io.ReadAll(os.Stdin)
 	}
-
-	token, tokenError := data["access_token"].(string)
-	exp, expError := data["expires_at"].(float64)
-	if !tokenError || !expError {
-		return
+	if _, err := os.Stat(bodyFile); err == nil {
+		return os.ReadFile(bodyFile)
 	}
+	return []byte(bodyFile), nil
+}
 
-	oacClient.AccessToken = token
-	oacClient.TokenExpiry = time.Unix(int64(exp), 0)
-	if time.Now().After(oacClient.TokenExpiry) {
-		oacClient.AccessToken = ""
-	}
+// RestCallWithBody is RestCall with an already loaded request body
+func (c *OacClient) RestCallWithBody(method, path string, bodyBytes []byte) (*Response, error) {
+	return c.exchange(method, path, "application/json", bodyBytes)
 }
 
 // prettyPrintJSON formats JSON response for readability
 func prettyPrintJSON(data []byte) (string, error) {
-	dataStr := strings.TrimSpace(string(data))
-	if len(dataStr) == 0 {
-		return "Request succeeded (no content).", nil
-	}
-
-	if strings.HasPrefix(dataStr, "{") {
-		var obj map[string]any
-		if err := json.Unmarshal(data, &obj); err != nil {
-			return "", err
-		}
-		b, _ := json.MarshalIndent(obj, "", "  ")
-		return string(b), nil
-	} else if strings.HasPrefix(dataStr, "[") {
-		var arr []interface{}
-		if err := json.Unmarshal(data, &arr); err != nil {
-			return "", err
-		}
-		b, _ := json.MarshalIndent(arr, "", "  ")
-		return string(b), nil
-	} else {
-		return dataStr, nil
+	var out strings.Builder
+	if err := PrettyPrint(&out, bytes.NewReader(data)); err != nil {
+		return "", err
 	}
+	return strings.TrimRight(out.String(), "\n"), nil
 }
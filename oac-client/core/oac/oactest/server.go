@@ -0,0 +1,472 @@
+// Package oactest provides an in-process fake of an OAC instance and its IDCS token
+// endpoint, for testing programs built on package oac without a live tenancy.
+//
+//	srv := oactest.NewServer()
+//	defer srv.Close()
+//	srv.AddItem(oac.CatalogItem{Type: "workbooks", Name: "Revenue", Path: "/shared/Finance/Revenue"})
+//	client, err := srv.Client()
+package oactest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"oac-client/core/oac"
+)
+
+// API paths served by the fake
+const (
+	TokenPath    = "/oauth2/v1/token"
+	UserInfoPath = "/oauth2/v1/userinfo"
+	apiBase      = "/api/20210901"
+)
+
+// Server is a fake OAC instance with an IDCS token endpoint. The exported fields may
+// be changed before the first request.
+type Server struct {
+	*httptest.Server
+
+	// ClientID and ClientSecret are the credentials accepted by the token endpoint
+	ClientID     string
+	ClientSecret string
+	// User is the subject of issued tokens
+	User string
+	// TokenTTL is the lifetime of issued tokens
+	TokenTTL time.Duration
+	// PageSize is the default page size of paginated listings
+	PageSize int
+
+	mu        sync.Mutex
+	tokens    map[string]time.Time
+	issued    int
+	items     []oac.CatalogItem
+	archives  map[string][]byte
+	snapshots []oac.Snapshot
+	failures  []failure
+	requests  []string
+}
+
+// failure is a canned error response returned instead of handling a request
+type failure struct {
+	status int
+	match  string
+}
+
+// NewServer starts a fake server with default credentials and an empty catalog
+func NewServer() *Server {
+	s := &Server{
+		ClientID:     "oactest-client",
+		ClientSecret: "oactest-secret",
+		User:         "oactest.user@example.com",
+		TokenTTL:     time.Hour,
+		PageSize:     50,
+		tokens:       map[string]time.Time{},
+		archives:     map[string][]byte{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Profile returns a client profile pointing at the fake with client_credentials settings
+func (s *Server) Profile() *oac.Profile {
+	return &oac.Profile{
+		Name: "oactest-" + strings.TrimPrefix(s.URL, "http://127.0.0.1:"),
+		Env: map[string]string{
+			"OAC_INSTANCE":           s.URL,
+			"IDCS_TOKEN_URL":         s.URL + TokenPath,
+			"IDCS_GRANT_TYPE":        "client_credentials",
+			"IDCS_OAC_CLIENT_ID":     s.ClientID,
+			"IDCS_OAC_CLIENT_SECRET": s.ClientSecret,
+			"IDCS_OAC_SCOPE":         "urn:opc:resource:consumer::all",
+		},
+	}
+}
+
+// Client returns a client using Profile, with any further options
+func (s *Server) Client(opts ...oac.Option) (*oac.OacClient, error) {
+	return oac.NewOacClient(append([]oac.Option{oac.WithProfile(s.Profile())}, opts...)...)
+}
+
+// AddItem adds a catalog item, deriving its id from its path
+func (s *Server) AddItem(item oac.CatalogItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item.ID == "" {
+		item.ID = oac.EncodeCatalogID(item.Path)
+	}
+	s.items = append(s.items, item)
+}
+
+// Archive returns the archive a catalog item exports, set by the last import of it
+func (s *Server) Archive(catalogPath string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.archive(catalogPath)
+}
+
+func (s *Server) archive(catalogPath string) []byte {
+	if a, ok := s.archives[catalogPath]; ok {
+		return append([]byte(nil), a...)
+	}
+	return []byte("archive of " + catalogPath)
+}
+
+// AddSnapshot adds a snapshot, generating its id when empty
+func (s *Server) AddSnapshot(snap oac.Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addSnapshot(snap)
+}
+
+func (s *Server) addSnapshot(snap oac.Snapshot) oac.Snapshot {
+	if snap.ID == "" {
+		snap.ID = fmt.Sprintf("snapshot-%d", len(s.snapshots)+1)
+	}
+	if snap.CreatedAt == "" {
+		snap.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if snap.Status == "" {
+		snap.Status = "ACTIVE"
+	}
+	s.snapshots = append(s.snapshots, snap)
+	return snap
+}
+
+// Snapshots returns the snapshots currently held by the fake
+func (s *Server) Snapshots() []oac.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]oac.Snapshot(nil), s.snapshots...)
+}
+
+// Fail answers the next n requests whose path contains match ("" for any path) with
+// status. 429 and 503 responses carry Retry-After: 1.
+func (s *Server) Fail(status, n int, match string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, failure{status: status, match: match})
+	}
+}
+
+// ExpireTokens invalidates every issued token, so the next API call gets a 401
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]time.Time{}
+}
+
+// TokensIssued returns how many tokens the token endpoint has issued
+func (s *Server) TokensIssued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// Requests returns the "METHOD path" of every request received, in order
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	for i, f := range s.failures {
+		if strings.Contains(r.URL.Path, f.match) {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			if f.status == http.StatusTooManyRequests || f.status == http.StatusServiceUnavailable {
+				w.Header().Set("Retry-After", "1")
+			}
+			writeError(w, f.status, http.StatusText(f.status))
+			return
+		}
+	}
+
+	if r.URL.Path == TokenPath {
+		s.serveToken(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired access token")
+		return
+	}
+
+	p := r.URL.Path
+	switch {
+	case p == UserInfoPath:
+		writeJSON(w, http.StatusOK, map[string]any{"sub": s.User, "preferred_username": s.User, "name": "OAC Test User"})
+	case r.Method == "GET" && strings.HasPrefix(p, apiBase+"/catalog/folders/") && strings.HasSuffix(p, "/items"):
+		s.serveFolder(w, strings.TrimSuffix(strings.TrimPrefix(p, apiBase+"/catalog/folders/"), "/items"))
+	case r.Method == "GET" && strings.HasPrefix(p, apiBase+"/catalog"):
+		s.serveSearch(w, r, strings.Trim(strings.TrimPrefix(p, apiBase+"/catalog"), "/"))
+	case r.Method == "POST" && strings.HasPrefix(p, apiBase+"/catalog/") && strings.Contains(p, "/actions/"):
+		s.serveItemAction(w, r, strings.TrimPrefix(p, apiBase+"/catalog/"))
+	case p == apiBase+"/snapshots" && r.Method == "GET":
+		s.serveSnapshots(w, r)
+	case p == apiBase+"/snapshots" && r.Method == "POST":
+		s.createSnapshot(w, r)
+	case p == apiBase+"/system/actions/restoreSnapshot" && r.Method == "POST":
+		s.restoreSnapshot(w, r)
+	case strings.HasPrefix(p, apiBase+"/snapshots/"):
+		s.serveSnapshot(w, r, strings.TrimPrefix(p, apiBase+"/snapshots/"))
+	case p == apiBase+"/workRequests" && r.Method == "GET":
+		s.serveWorkRequests(w)
+	case strings.HasPrefix(p, apiBase+"/workRequests/") && r.Method == "GET":
+		id := strings.TrimPrefix(p, apiBase+"/workRequests/")
+		writeJSON(w, http.StatusOK, map[string]any{"id": id, "status": "SUCCEEDED", "percentComplete": 100})
+	default:
+		writeError(w, http.StatusNotFound, "no such resource in oactest: "+r.Method+" "+p)
+	}
+}
+
+// serveToken implements the client_credentials and password grants
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	subject := s.User
+	claims := map[string]any{}
+	switch grant := r.PostForm.Get("grant_type"); grant {
+	case "client_credentials":
+		subject = s.ClientID
+	case "password", "refresh_token":
+	case "urn:ietf:params:oauth:grant-type:token-exchange":
+		actor := r.PostForm.Get("subject_token")
+		if _, ok := s.tokens[actor]; !ok || r.PostForm.Get("requested_subject") == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		subject = r.PostForm.Get("requested_subject")
+		claims["act"] = map[string]string{"sub": s.ClientID}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	s.issued++
+	expiry := time.Now().Add(s.TokenTTL)
+	maps.Copy(claims, map[string]any{
+		"sub":       subject,
+		"client_id": s.ClientID,
+		"iss":       s.URL,
+		"scope":     r.PostForm.Get("scope"),
+		"iat":       time.Now().Unix(),
+		"exp":       expiry.Unix(),
+		"jti":       strconv.Itoa(s.issued),
+	})
+	token := fakeJWT(claims)
+	s.tokens[token] = expiry
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.TokenTTL.Seconds()),
+	})
+}
+
+// authorized reports whether r carries a token issued by the fake that has not expired
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	expiry, ok := s.tokens[token]
+	return ok && time.Now().Before(expiry)
+}
+
+func (s *Server) serveFolder(w http.ResponseWriter, id string) {
+	folder, err := oac.DecodeCatalogID(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	folder = "/" + strings.Trim(folder, "/")
+
+	items := []oac.CatalogItem{}
+	for _, item := range s.items {
+		if path.Dir(item.Path) == folder {
+			items = append(items, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// serveItemAction implements the export, import, getACL and updateACL actions of
+// catalog items, given "<type>/<id>/actions/<action>"
+func (s *Server) serveItemAction(w http.ResponseWriter, r *http.Request, rest string) {
+	item, action, _ := strings.Cut(rest, "/actions/")
+	_, id, _ := strings.Cut(item, "/")
+	catalogPath, err := oac.DecodeCatalogID(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	catalogPath = "/" + strings.Trim(catalogPath, "/")
+
+	switch action {
+	case "export":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(s.archive(catalogPath))
+	case "import":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.archives[catalogPath] = data
+		w.WriteHeader(http.StatusNoContent)
+	case "getACL":
+		writeJSON(w, http.StatusOK, map[string]any{"accessControlEntries": []any{}})
+	case "updateACL":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "no such action in oactest: "+action)
+	}
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request, itemType string) {
+	search := r.URL.Query().Get("search")
+	if search == "" {
+		search = "*"
+	}
+
+	items := []oac.CatalogItem{}
+	for _, item := range s.items {
+		if itemType != "" && item.Type != itemType {
+			continue
+		}
+		if ok, _ := path.Match(search, item.Name); ok {
+			items = append(items, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// serveSnapshots lists snapshots a page at a time: limit sets the page size and page
+// the zero-based page, and the oa-next-page header names the next page while more remain
+func (s *Server) serveSnapshots(w http.ResponseWriter, r *http.Request) {
+	snaps := append([]oac.Snapshot(nil), s.snapshots...)
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].CreatedAt > snaps[j].CreatedAt })
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = s.PageSize
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	start := min(page*limit, len(snaps))
+	end := min(start+limit, len(snaps))
+	if end < len(snaps) {
+		w.Header().Set("oa-next-page", strconv.Itoa(page+1))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": snaps[start:end]})
+}
+
+func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		writeError(w, http.StatusBadRequest, "a snapshot name is required")
+		return
+	}
+
+	snap := s.addSnapshot(oac.Snapshot{Name: body.Name, Description: body.Description})
+	w.Header().Set("oa-work-request-id", "wr-"+snap.ID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// restoreSnapshot accepts the restore of a known snapshot without changing the fake's state
+func (s *Server) restoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Snapshot struct {
+			ID string `json:"id"`
+		} `json:"snapshot"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, snap := range s.snapshots {
+		if snap.ID == body.Snapshot.ID {
+			w.Header().Set("oa-work-request-id", "wr-restore-"+snap.ID)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "snapshot "+body.Snapshot.ID+" not found")
+}
+
+// serveWorkRequests lists a finished CREATE_SNAPSHOT work request per snapshot
+func (s *Server) serveWorkRequests(w http.ResponseWriter) {
+	items := make([]map[string]any, len(s.snapshots))
+	for i, snap := range s.snapshots {
+		items[i] = map[string]any{"id": "wr-" + snap.ID, "operationType": "CREATE_SNAPSHOT", "status": "SUCCEEDED", "percentComplete": 100}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) serveSnapshot(w http.ResponseWriter, r *http.Request, id string) {
+	for i, snap := range s.snapshots {
+		if snap.ID != id {
+			continue
+		}
+		switch r.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, snap)
+		case "DELETE":
+			s.snapshots = append(s.snapshots[:i], s.snapshots[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, r.Method+" not supported")
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "snapshot "+id+" not found")
+}
+
+// fakeJWT builds an unsigned JWT that oac.DecodeTokenClaims can read
+func fakeJWT(claims map[string]any) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".oactest"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error body in the shape OAC uses, with an opc-request-id
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("opc-request-id", fmt.Sprintf("oactest-%d", time.Now().UnixNano()))
+	writeJSON(w, status, map[string]string{"code": strings.ReplaceAll(http.StatusText(status), " ", ""), "message": message})
+}
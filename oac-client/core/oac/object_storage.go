@@ -0,0 +1,120 @@
+package oac
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage/transfer"
+)
+
+// ObjectStorage reads and writes artifacts in an OCI Object Storage bucket
+type ObjectStorage struct {
+	Namespace string
+	Bucket    string
+
+	client objectstorage.ObjectStorageClient
+}
+
+// NewObjectStorage connects to a bucket using the OCI credentials from OCI_CLI_AUTH / ~/.oci/config.
+// The tenancy namespace is looked up when namespace is empty.
+func NewObjectStorage(ctx context.Context, namespace, bucket string) (*ObjectStorage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket name is required")
+	}
+
+	provider, err := ociConfigProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+
+	if namespace == "" {
+		resp, err := client.GetNamespace(ctx, objectstorage.GetNamespaceRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up object storage namespace: %w", err)
+		}
+		namespace = *resp.Value
+	}
+
+	return &ObjectStorage{Namespace: namespace, Bucket: bucket, client: client}, nil
+}
+
+// String returns the oci:// URI of the bucket
+func (s *ObjectStorage) String() string {
+	return fmt.Sprintf("oci://%s@%s", s.Bucket, s.Namespace)
+}
+
+// Upload streams r into an object, using multipart uploads for large content
+func (s *ObjectStorage) Upload(ctx context.Context, name string, r io.Reader) error {
+	manager := transfer.NewUploadManager()
+	_, err := manager.UploadStream(ctx, transfer.UploadStreamRequest{
+		UploadRequest: transfer.UploadRequest{
+			NamespaceName:       common.String(s.Namespace),
+			BucketName:          common.String(s.Bucket),
+			ObjectName:          common.String(name),
+			ObjectStorageClient: &s.client,
+		},
+		StreamReader: r,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", name, s, err)
+	}
+	return nil
+}
+
+// Download opens an object for reading; the caller must close it
+func (s *ObjectStorage) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.client.GetObject(ctx, objectstorage.GetObjectRequest{
+		NamespaceName: common.String(s.Namespace),
+		BucketName:    common.String(s.Bucket),
+		ObjectName:    common.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s from %s: %w", name, s, err)
+	}
+	return resp.Content, nil
+}
+
+// List returns the names of objects starting with prefix
+func (s *ObjectStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	req := objectstorage.ListObjectsRequest{
+		NamespaceName: common.String(s.Namespace),
+		BucketName:    common.String(s.Bucket),
+		Prefix:        common.String(prefix),
+	}
+
+	for {
+		resp, err := s.client.ListObjects(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", s, err)
+		}
+		for _, obj := range resp.Objects {
+			names = append(names, *obj.Name)
+		}
+		if resp.NextStartWith == nil {
+			return names, nil
+		}
+		req.Start = resp.NextStartWith
+	}
+}
+
+// Delete removes an object
+func (s *ObjectStorage) Delete(ctx context.Context, name string) error {
+	_, err := s.client.DeleteObject(ctx, objectstorage.DeleteObjectRequest{
+		NamespaceName: common.String(s.Namespace),
+		BucketName:    common.String(s.Bucket),
+		ObjectName:    common.String(name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from %s: %w", name, s, err)
+	}
+	return nil
+}
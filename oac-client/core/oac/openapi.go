@@ -0,0 +1,297 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// OpenAPISpec is the subset of an OpenAPI 3 document needed to call its operations
+type OpenAPISpec struct {
+	Title      string
+	Version    string
+	Operations []*Operation
+
+	doc map[string]any
+}
+
+// Operation is one method on one path of an OpenAPI document
+type Operation struct {
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []Parameter
+	// Body is the JSON schema of the application/json request body, nil when there is none
+	Body         map[string]any
+	BodyRequired bool
+}
+
+// Parameter is a path, query or header parameter of an operation
+type Parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	// Type is the JSON schema type: string, integer, number, boolean or array
+	Type string
+	Enum []string
+}
+
+// openAPIMethods are the operation keys of a path item, in display order
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// OpenAPISpecPath returns OAC_OPENAPI_SPEC, or openapi.yaml / openapi.json next to the
+// config file at configPath when present, or "" when no spec is available
+func OpenAPISpecPath(configPath string) string {
+	if p := os.Getenv("OAC_OPENAPI_SPEC"); p != "" {
+		return p
+	}
+	dir := filepath.Dir(configPath)
+	for _, name := range []string{"openapi.yaml", "openapi.yml", "openapi.json"} {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// LoadOpenAPI reads an OpenAPI 3 document in YAML or JSON
+func LoadOpenAPI(file string) (*OpenAPISpec, error) {
+	raw, err := LoadSpecFile(file)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseOpenAPI(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec %s: %w", file, err)
+	}
+	return spec, nil
+}
+
+// ParseOpenAPI parses an OpenAPI 3 document given as JSON
+func ParseOpenAPI(data []byte) (*OpenAPISpec, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	paths, ok := doc["paths"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("no paths defined")
+	}
+
+	spec := &OpenAPISpec{doc: doc}
+	if info, ok := doc["info"].(map[string]any); ok {
+		spec.Title, _ = info["title"].(string)
+		spec.Version, _ = info["version"].(string)
+	}
+
+	pathNames := make([]string, 0, len(paths))
+	for p := range paths {
+		pathNames = append(pathNames, p)
+	}
+	sort.Strings(pathNames)
+
+	seen := map[string]bool{}
+	for _, p := range pathNames {
+		item, _ := spec.resolve(paths[p]).(map[string]any)
+		shared := spec.parameters(item["parameters"])
+		for _, method := range openAPIMethods {
+			raw, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			op := spec.operation(strings.ToUpper(method), p, raw, shared)
+			if seen[op.ID] {
+				return nil, fmt.Errorf("duplicate operationId %q", op.ID)
+			}
+			seen[op.ID] = true
+			spec.Operations = append(spec.Operations, op)
+		}
+	}
+	return spec, nil
+}
+
+// operation builds an Operation, overriding path-level parameters with its own
+func (s *OpenAPISpec) operation(method, path string, raw map[string]any, shared []Parameter) *Operation {
+	op := &Operation{Method: method, Path: path}
+	op.ID, _ = raw["operationId"].(string)
+	if op.ID == "" {
+		op.ID = strings.ToLower(method) + strings.NewReplacer("/", "-", "{", "", "}", "").Replace(path)
+	}
+	op.Summary, _ = raw["summary"].(string)
+	op.Description, _ = raw["description"].(string)
+	for _, t := range asSlice(raw["tags"]) {
+		if tag, ok := t.(string); ok {
+			op.Tags = append(op.Tags, tag)
+		}
+	}
+
+	own := s.parameters(raw["parameters"])
+	for _, p := range shared {
+		if !slices.ContainsFunc(own, func(o Parameter) bool { return o.Name == p.Name && o.In == p.In }) {
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+	op.Parameters = append(op.Parameters, own...)
+
+	if body, ok := s.resolve(raw["requestBody"]).(map[string]any); ok {
+		op.BodyRequired, _ = body["required"].(bool)
+		content, _ := body["content"].(map[string]any)
+		for mediaType, m := range content {
+			if strings.Contains(mediaType, "json") {
+				media, _ := m.(map[string]any)
+				op.Body, _ = s.resolve(media["schema"]).(map[string]any)
+				break
+			}
+		}
+		if op.Body == nil {
+			op.Body = map[string]any{}
+		}
+	}
+	return op
+}
+
+// parameters decodes a parameters list, skipping cookie parameters
+func (s *OpenAPISpec) parameters(v any) []Parameter {
+	var params []Parameter
+	for _, item := range asSlice(v) {
+		raw, ok := s.resolve(item).(map[string]any)
+		if !ok {
+			continue
+		}
+		p := Parameter{}
+		p.Name, _ = raw["name"].(string)
+		p.In, _ = raw["in"].(string)
+		p.Description, _ = raw["description"].(string)
+		p.Required, _ = raw["required"].(bool)
+		if p.Name == "" || (p.In != "path" && p.In != "query" && p.In != "header") {
+			continue
+		}
+		if p.In == "path" {
+			p.Required = true
+		}
+
+		schema, _ := s.resolve(raw["schema"]).(map[string]any)
+		p.Type, _ = schema["type"].(string)
+		if p.Type == "" {
+			p.Type = "string"
+		}
+		for _, e := range asSlice(schema["enum"]) {
+			p.Enum = append(p.Enum, fmt.Sprint(e))
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// resolve follows a local "$ref" such as #/components/schemas/Snapshot
+func (s *OpenAPISpec) resolve(v any) any {
+	for range 32 {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var node any = s.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+			obj, _ := node.(map[string]any)
+			node = obj[part]
+		}
+		v = node
+	}
+	return v
+}
+
+// Operation returns the operation with the given id or command name
+func (s *OpenAPISpec) Operation(id string) *Operation {
+	for _, op := range s.Operations {
+		if op.ID == id || op.CommandName() == id {
+			return op
+		}
+	}
+	return nil
+}
+
+// CommandName returns the operation id in kebab case, e.g. listSnapshots becomes list-snapshots
+func (op *Operation) CommandName() string {
+	return KebabCase(op.ID)
+}
+
+// ExpandPath substitutes path parameters into the operation path
+func (op *Operation) ExpandPath(values map[string]string) (string, error) {
+	path := op.Path
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			continue
+		}
+		v, ok := values[p.Name]
+		if !ok {
+			return "", fmt.Errorf("missing path parameter %q", p.Name)
+		}
+		path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(v))
+	}
+	return path, nil
+}
+
+// Validate checks a parameter value against its type and enum
+func (p Parameter) Validate(value string) error {
+	switch p.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", p.Name, value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number, got %q", p.Name, value)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", p.Name, value)
+		}
+	}
+	if len(p.Enum) > 0 && !slices.Contains(p.Enum, value) {
+		return fmt.Errorf("%s must be one of %s, got %q", p.Name, strings.Join(p.Enum, ", "), value)
+	}
+	return nil
+}
+
+// KebabCase converts camelCase, snake_case and spaced names to kebab-case
+func KebabCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == ' ' || r == '.':
+			b.WriteByte('-')
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
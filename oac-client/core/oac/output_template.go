@@ -0,0 +1,64 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/template"
+)
+
+// OutputTemplate renders responses with a Go template, e.g. {{range .items}}{{.name}}{{end}}
+type OutputTemplate struct {
+	tmpl *template.Template
+}
+
+// outputFuncs are the functions of output templates; status, header and headers are
+// bound to the response at execution
+func outputFuncs(status int, header http.Header) template.FuncMap {
+	return template.FuncMap{
+		"status":  func() int { return status },
+		"header":  func(name string) string { return header.Get(name) },
+		"headers": func() http.Header { return header },
+		"env":     os.Getenv,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+// ParseOutputTemplate parses an output template. The response body is the data, decoded
+// from JSON; {{status}}, {{header "ETag"}} and {{headers}} give the status code and
+// headers, {{json .x}} encodes a value and {{env "NAME"}} reads the environment.
+func ParseOutputTemplate(text string) (*OutputTemplate, error) {
+	tmpl, err := template.New("output").Funcs(outputFuncs(0, nil)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return &OutputTemplate{tmpl: tmpl}, nil
+}
+
+// Execute renders a response body with its status and headers, 0 and nil when unknown.
+// Bodies that are not JSON are passed to the template as a string.
+func (t *OutputTemplate) Execute(w io.Writer, body []byte, status int, header http.Header) error {
+	var data any
+	if len(bytes.TrimSpace(body)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			data = string(body)
+		}
+	}
+
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Funcs(outputFuncs(status, header)).Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package oac
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ACE is one entry of a catalog item's access control list
+type ACE struct {
+	Principal string `json:"principalName" yaml:"principal"`
+	// Type is the kind of principal: user, role or group
+	Type string `json:"principalType" yaml:"type"`
+	// Permission is e.g. read, modify or full
+	Permission string `json:"permission" yaml:"permission"`
+}
+
+func (e ACE) key() string {
+	return strings.ToLower(e.Type) + ":" + e.Principal
+}
+
+func (e ACE) String() string {
+	return fmt.Sprintf("%s:%s %s", e.Type, e.Principal, e.Permission)
+}
+
+// aclBody is the request and response body of the getACL and updateACL actions
+type aclBody struct {
+	Entries []ACE `json:"accessControlEntries"`
+}
+
+// ACLFile maps catalog paths to the entries their ACL should hold
+type ACLFile map[string][]ACE
+
+// LoadACLFile reads an ACL file
+func LoadACLFile(file string) (ACLFile, error) {
+	acls := ACLFile{}
+	if err := loadYAML(file, &acls); err != nil {
+		return nil, err
+	}
+	normalized := make(ACLFile, len(acls))
+	for p, entries := range acls {
+		for _, e := range entries {
+			if e.Principal == "" || e.Type == "" || e.Permission == "" {
+				return nil, fmt.Errorf("invalid %s: entries of %s need principal, type and permission", file, p)
+			}
+		}
+		normalized["/"+strings.Trim(p, "/")] = entries
+	}
+	return normalized, nil
+}
+
+// LookupItem returns the catalog item at a path, listing its parent folder
+func (c *OacClient) LookupItem(catalogPath string) (CatalogItem, error) {
+	catalogPath = "/" + strings.Trim(catalogPath, "/")
+	items, err := c.ListFolder(path.Dir(catalogPath))
+	if err != nil {
+		return CatalogItem{}, fmt.Errorf("failed to look up %s: %w", catalogPath, err)
+	}
+	for _, item := range items {
+		if item.FullPath() == catalogPath {
+			return item, nil
+		}
+	}
+	return CatalogItem{}, fmt.Errorf("catalog item %s not found", catalogPath)
+}
+
+// GetACL returns the access control list of a catalog item
+func (c *OacClient) GetACL(item CatalogItem) ([]ACE, error) {
+	var acl aclBody
+	if err := c.callJSON("POST", itemEndpoint(item.Type, item.FullPath())+"/actions/getACL", nil, &acl); err != nil {
+		return nil, fmt.Errorf("failed to read ACL of %s: %w", item.FullPath(), err)
+	}
+	return acl.Entries, nil
+}
+
+// SetACL replaces the access control list of a catalog item
+func (c *OacClient) SetACL(item CatalogItem, entries []ACE) error {
+	if err := c.callJSON("POST", itemEndpoint(item.Type, item.FullPath())+"/actions/updateACL", aclBody{Entries: entries}, nil); err != nil {
+		return fmt.Errorf("failed to update ACL of %s: %w", item.FullPath(), err)
+	}
+	return nil
+}
+
+// ACLChange is one difference between a current and a desired ACL
+type ACLChange struct {
+	// Op is "+" for an added principal, "-" for a removed one and "~" for a changed permission
+	Op    string
+	Entry ACE
+	// Old is the replaced entry of a "~" change
+	Old ACE
+}
+
+func (ch ACLChange) String() string {
+	if ch.Op == "~" {
+		return fmt.Sprintf("~ %s:%s %s -> %s", ch.Entry.Type, ch.Entry.Principal, ch.Old.Permission, ch.Entry.Permission)
+	}
+	return ch.Op + " " + ch.Entry.String()
+}
+
+// DiffACL returns the changes turning current into desired, sorted by principal
+func DiffACL(current, desired []ACE) []ACLChange {
+	have := map[string]ACE{}
+	for _, e := range current {
+		have[e.key()] = e
+	}
+	want := map[string]ACE{}
+	for _, e := range desired {
+		want[e.key()] = e
+	}
+
+	var changes []ACLChange
+	for k, e := range want {
+		old, ok := have[k]
+		switch {
+		case !ok:
+			changes = append(changes, ACLChange{Op: "+", Entry: e})
+		case !strings.EqualFold(old.Permission, e.Permission):
+			changes = append(changes, ACLChange{Op: "~", Entry: e, Old: old})
+		}
+	}
+	for k, e := range have {
+		if _, ok := want[k]; !ok {
+			changes = append(changes, ACLChange{Op: "-", Entry: e})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Entry.key() < changes[j].Entry.key() })
+	return changes
+}
+
+// PermissionPlan is the ACL update planned for one catalog item
+type PermissionPlan struct {
+	Item    CatalogItem
+	Desired []ACE
+	Changes []ACLChange
+}
+
+// PlanPermissions compares the ACLs of an ACL file with the catalog. With recursive, the
+// entries of a folder also apply to everything below it, unless a deeper path of the file
+// lists its own. Items whose ACL already matches are left out.
+func (c *OacClient) PlanPermissions(acls ACLFile, recursive bool) ([]PermissionPlan, error) {
+	paths := make([]string, 0, len(acls))
+	for p := range acls {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	targets := map[string]CatalogItem{}
+	for _, p := range paths {
+		item, err := c.LookupItem(p)
+		if err != nil {
+			return nil, err
+		}
+		targets[p] = item
+		if !recursive || !item.IsFolder() {
+			continue
+		}
+		err = c.WalkCatalog(p, func(child CatalogItem, depth int) error {
+			targets[child.FullPath()] = child
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(targets))
+	for p := range targets {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	var plans []PermissionPlan
+	for _, p := range names {
+		desired := acls.lookup(p)
+		item := targets[p]
+		current, err := c.GetACL(item)
+		if err != nil {
+			return nil, err
+		}
+		if changes := DiffACL(current, desired); len(changes) > 0 {
+			plans = append(plans, PermissionPlan{Item: item, Desired: desired, Changes: changes})
+		}
+	}
+	return plans, nil
+}
+
+// lookup returns the entries of p, or of its closest listed ancestor
+func (acls ACLFile) lookup(p string) []ACE {
+	for {
+		if entries, ok := acls[p]; ok {
+			return entries
+		}
+		parent := path.Dir(p)
+		if parent == p {
+			return nil
+		}
+		p = parent
+	}
+}
+
+// ApplyPermissions performs planned ACL updates, stopping at the first failure
+func (c *OacClient) ApplyPermissions(plans []PermissionPlan) error {
+	for _, plan := range plans {
+		if err := c.SetACL(plan.Item, plan.Desired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
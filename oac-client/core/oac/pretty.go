@@ -0,0 +1,238 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// noContentMessage is printed for successful responses without a body
+const noContentMessage = "Request succeeded (no content)."
+
+// PrettyPrint indents a JSON stream from r into w token by token, so memory use does
+// not grow with the response size. Key order is preserved. Non-JSON content is copied
+// unchanged and an empty body prints a short confirmation.
+func PrettyPrint(w io.Writer, r io.Reader) error {
+	return prettyPrint(w, r, false)
+}
+
+// PrettyPrintColor is PrettyPrint with ANSI colors for keys, strings, numbers and literals
+func PrettyPrintColor(w io.Writer, r io.Reader) error {
+	return prettyPrint(w, r, true)
+}
+
+// ANSI colors used by PrettyPrintColor
+const (
+	colorKey     = "\x1b[34;1m"
+	colorString  = "\x1b[32m"
+	colorNumber  = "\x1b[36m"
+	colorLiteral = "\x1b[35m"
+	colorReset   = "\x1b[0m"
+)
+
+func prettyPrint(w io.Writer, r io.Reader, color bool) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	bw := bufio.NewWriterSize(w, 64*1024)
+
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		fmt.Fprintln(bw, noContentMessage)
+		return bw.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if first != '{' && first != '[' {
+		if _, err := io.Copy(bw, br); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	ind := newIndenter(bw)
+	ind.color = color
+	if err := ind.run(dec); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without consuming it
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// container tracks an open object or array while re-emitting tokens
+type container struct {
+	object    bool
+	count     int
+	wantValue bool
+}
+
+// indenter writes JSON tokens back out with two-space indentation
+type indenter struct {
+	w     *bufio.Writer
+	stack []*container
+	str   bytes.Buffer
+	enc   *json.Encoder
+	color bool
+}
+
+func newIndenter(w *bufio.Writer) *indenter {
+	ind := &indenter{w: w}
+	ind.enc = json.NewEncoder(&ind.str)
+	ind.enc.SetEscapeHTML(false)
+	return ind
+}
+
+// run re-emits every top-level value from dec, one per line
+func (ind *indenter) run(dec *json.Decoder) error {
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if len(ind.stack) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := ind.token(tok); err != nil {
+			return err
+		}
+		if len(ind.stack) == 0 {
+			ind.w.WriteByte('\n')
+		}
+	}
+}
+
+// token writes a single token with the separators and indentation it needs
+func (ind *indenter) token(tok json.Token) error {
+	top := ind.top()
+
+	if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+		ind.stack = ind.stack[:len(ind.stack)-1]
+		if top.count > 0 {
+			ind.newline()
+		}
+		ind.w.WriteByte(byte(d))
+		ind.valueDone()
+		return nil
+	}
+
+	// object keys
+	if top != nil && top.object && !top.wantValue {
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("invalid JSON object key %v", tok)
+		}
+		if top.count > 0 {
+			ind.w.WriteByte(',')
+		}
+		ind.newline()
+		ind.startColor(colorKey)
+		if err := ind.writeString(key); err != nil {
+			return err
+		}
+		ind.endColor()
+		ind.w.WriteString(": ")
+		top.wantValue = true
+		return nil
+	}
+
+	// array elements
+	if top != nil && !top.object {
+		if top.count > 0 {
+			ind.w.WriteByte(',')
+		}
+		ind.newline()
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		ind.w.WriteByte(byte(v))
+		ind.stack = append(ind.stack, &container{object: v == '{'})
+		return nil
+	case string:
+		ind.startColor(colorString)
+		if err := ind.writeString(v); err != nil {
+			return err
+		}
+		ind.endColor()
+	case json.Number:
+		ind.startColor(colorNumber)
+		ind.w.WriteString(v.String())
+		ind.endColor()
+	case bool:
+		ind.startColor(colorLiteral)
+		fmt.Fprint(ind.w, v)
+		ind.endColor()
+	case nil:
+		ind.startColor(colorLiteral)
+		ind.w.WriteString("null")
+		ind.endColor()
+	}
+	ind.valueDone()
+	return nil
+}
+
+// valueDone records that a complete value was written into the current container
+func (ind *indenter) valueDone() {
+	if top := ind.top(); top != nil {
+		top.count++
+		top.wantValue = false
+	}
+}
+
+func (ind *indenter) top() *container {
+	if len(ind.stack) == 0 {
+		return nil
+	}
+	return ind.stack[len(ind.stack)-1]
+}
+
+func (ind *indenter) startColor(code string) {
+	if ind.color {
+		ind.w.WriteString(code)
+	}
+}
+
+func (ind *indenter) endColor() {
+	if ind.color {
+		ind.w.WriteString(colorReset)
+	}
+}
+
+func (ind *indenter) newline() {
+	ind.w.WriteByte('\n')
+	for range ind.stack {
+		ind.w.WriteString("  ")
+	}
+}
+
+// writeString writes s as a JSON string literal without HTML escaping
+func (ind *indenter) writeString(s string) error {
+	ind.str.Reset()
+	if err := ind.enc.Encode(s); err != nil {
+		return err
+	}
+	ind.w.Write(bytes.TrimRight(ind.str.Bytes(), "\n"))
+	return nil
+}
@@ -0,0 +1,35 @@
+package oac
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"object", `{"b":1,"a":[true,null,"x<y"],"e":{},"f":[]}`, "{\n  \"b\": 1,\n  \"a\": [\n    true,\n    null,\n    \"x<y\"\n  ],\n  \"e\": {},\n  \"f\": []\n}\n"},
+		{"numbers kept", `[12345678901234567890,1.50]`, "[\n  12345678901234567890,\n  1.50\n]\n"},
+		{"stream", "{\"a\":1}\n{\"a\":2}", "{\n  \"a\": 1\n}\n{\n  \"a\": 2\n}\n"},
+		{"not JSON", "plain text", "plain text"},
+		{"empty", "  \n", noContentMessage + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := PrettyPrint(&out, strings.NewReader(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("PrettyPrint() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := PrettyPrint(&strings.Builder{}, strings.NewReader(`{"a":[1,`)); err == nil {
+		t.Error("truncated JSON printed without error")
+	}
+}
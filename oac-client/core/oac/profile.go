@@ -0,0 +1,166 @@
+package oac
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named set of settings for one OAC instance, read from the config file.
+// Env holds the same keys as the environment variables (OAC_INSTANCE, IDCS_TOKEN_URL, ...)
+// and takes precedence over them for clients created with the profile.
+type Profile struct {
+	Name string            `yaml:"-"`
+	Env  map[string]string `yaml:"env"`
+	// Confirm requires confirmation of every DELETE sent with the profile
+	Confirm bool `yaml:"confirm"`
+	// Protected lists further requests that require confirmation, see Profile.Protects
+	Protected []string `yaml:"protected"`
+	// Scopes requested for the profile's tokens, instead of IDCS_OAC_SCOPE
+	Scopes []string `yaml:"scopes"`
+	// Headers are sent with every request unless the request sets them itself
+	Headers map[string]string `yaml:"headers"`
+	// BasePath is prepended to relative request paths instead of the versioned API
+	// prefix, e.g. /ui turns "health" into /ui/health; paths starting with / are left alone
+	BasePath string `yaml:"base_path"`
+	// APIVersion selects the REST API version, see OacClient.APIVersion
+	APIVersion string `yaml:"api_version"`
+	// RequestHooks run on every request sent with the profile, see RequestHook
+	RequestHooks []HookSpec `yaml:"request_hooks"`
+	// Vars are template variables for request paths and bodies, e.g. folder GUIDs that
+	// differ between tenancies, see ExpandPath
+	Vars TemplateVars `yaml:"vars"`
+}
+
+// Config is the contents of the oac-client config file
+type Config struct {
+	// Path is the file the config was read from
+	Path           string `yaml:"-"`
+	DefaultProfile string `yaml:"default_profile"`
+	// Env holds defaults for all profiles, below the environment and .env files
+	Env      map[string]string   `yaml:"env"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+	// Aliases maps command names to requests, see ExpandAlias
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// DefaultConfigPath returns OAC_CONFIG or config.yaml in ConfigDir
+func DefaultConfigPath() string {
+	if p := os.Getenv("OAC_CONFIG"); p != "" {
+		return p
+	}
+	return filepath.Join(ConfigDir(), "config.yaml")
+}
+
+// LoadConfig reads a config file; a missing file yields an empty config
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Path: path, Profiles: map[string]*Profile{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	for name, p := range cfg.Profiles {
+		if p == nil {
+			p = &Profile{}
+			cfg.Profiles[name] = p
+		}
+		p.Name = name
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty.
+// It returns nil without error when no name is given and no default is configured.
+func (cfg *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in config", name)
+	}
+	return p, nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (cfg *Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile makes the client read its settings from p before the environment
+func WithProfile(p *Profile) Option {
+	return func(c *OacClient) {
+		c.profile = p
+	}
+}
+
+// ProfileName returns the name of the client's profile, or "" for the environment
+func (c *OacClient) ProfileName() string {
+	if c.profile == nil {
+		return ""
+	}
+	return c.profile.Name
+}
+
+// WithInstance sends requests to instance, overriding OAC_INSTANCE from the profile
+// or the environment
+func WithInstance(instance string) Option {
+	return func(c *OacClient) {
+		c.instance = instance
+	}
+}
+
+// Instance returns the OAC instance URL requests are sent to
+func (c *OacClient) Instance() string {
+	return strings.TrimRight(c.setting("OAC_INSTANCE"), "/")
+}
+
+// applyProfile returns r with its path resolved, see resolvePath, and the profile's
+// default headers applied
+func (c *OacClient) applyProfile(r *Request) *Request {
+	applied := *r
+	applied.Path = c.resolvePath(r.Path)
+	if p := c.profile; p != nil && len(p.Headers) > 0 {
+		applied.Header = r.Header.Clone()
+		if applied.Header == nil {
+			applied.Header = http.Header{}
+		}
+		for name, value := range c.profile.Headers {
+			if applied.Header.Get(name) == "" {
+				applied.Header.Set(name, value)
+			}
+		}
+	}
+	return &applied
+}
+
+// setting returns a configuration value from the client's flags, profile or environment,
+// see Setting
+func (c *OacClient) setting(key string) string {
+	return c.Setting(key).Value
+}
@@ -0,0 +1,222 @@
+package oac
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PromoteObjects lists catalog paths (or connection ids) to promote, keyed by catalog type:
+//
+//	connections: ["'admin'.'ADW Sales'"]
+//	datasets:    [/shared/Finance/Sales]
+//	workbooks:   [/shared/Finance/Revenue]
+type PromoteObjects map[string][]string
+
+// PromoteMapping rewrites references while promoting, e.g. development connection names
+// to their production counterparts
+type PromoteMapping struct {
+	Connections map[string]string `yaml:"connections"`
+}
+
+// PromoteResult is the outcome of promoting one object
+type PromoteResult struct {
+	Type string
+	Path string
+	Err  error
+}
+
+// promoteOrder imports dependencies before the content that uses them
+var promoteOrder = map[string]int{"connections": 0, "datasets": 1, "dataflows": 2, "workbooks": 3}
+
+// LoadPromoteObjects reads an objects file
+func LoadPromoteObjects(file string) (PromoteObjects, error) {
+	objs := PromoteObjects{}
+	if err := loadYAML(file, &objs); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// LoadPromoteMapping reads a reference mapping file
+func LoadPromoteMapping(file string) (*PromoteMapping, error) {
+	m := &PromoteMapping{}
+	if err := loadYAML(file, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func loadYAML(file string, out any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid %s: %w", file, err)
+	}
+	return nil
+}
+
+// types returns the object types in dependency order
+func (objs PromoteObjects) types() []string {
+	types := make([]string, 0, len(objs))
+	for t := range objs {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		oi, ok := promoteOrder[types[i]]
+		if !ok {
+			oi = len(promoteOrder)
+		}
+		oj, ok := promoteOrder[types[j]]
+		if !ok {
+			oj = len(promoteOrder)
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// Promote copies objects from src to dst, rewriting references with mapping.
+// Every object is attempted; report is called with each result as it completes.
+func Promote(src, dst *OacClient, objs PromoteObjects, mapping *PromoteMapping, dryRun bool, report func(PromoteResult)) []PromoteResult {
+	if mapping == nil {
+		mapping = &PromoteMapping{}
+	}
+
+	var results []PromoteResult
+	for _, itemType := range objs.types() {
+		for _, p := range objs[itemType] {
+			res := PromoteResult{Type: itemType, Path: p}
+			if !dryRun {
+				if itemType == "connections" {
+					res.Err = promoteConnection(src, dst, p, mapping)
+				} else {
+					res.Err = promoteItem(src, dst, itemType, p, mapping)
+				}
+			}
+			results = append(results, res)
+			if report != nil {
+				report(res)
+			}
+		}
+	}
+	return results
+}
+
+// promoteConnection copies a connection definition, creating or updating it in dst
+func promoteConnection(src, dst *OacClient, id string, mapping *PromoteMapping) error {
+	def, err := src.GetConnection(id)
+	if err != nil {
+		return fmt.Errorf("failed to read connection: %w", err)
+	}
+	def = mapping.rewrite(def)
+
+	targetID := id
+	if mapped, ok := mapping.Connections[id]; ok {
+		targetID = mapped
+	}
+
+	if _, err := dst.CreateConnection(json.RawMessage(def)); err != nil {
+		if StatusCode(err) != http.StatusConflict {
+			return fmt.Errorf("failed to create connection: %w", err)
+		}
+		if _, err := dst.UpdateConnection(targetID, json.RawMessage(def)); err != nil {
+			return fmt.Errorf("failed to update connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// promoteItem exports a catalog item from src and imports it at the same path in dst
+func promoteItem(src, dst *OacClient, itemType, catalogPath string, mapping *PromoteMapping) error {
+	archive, err := src.ExportItem(itemType, catalogPath)
+	if err != nil {
+		return err
+	}
+
+	archive, err = mapping.rewriteArchive(archive)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite references in %s: %w", catalogPath, err)
+	}
+
+	return dst.ImportItem(itemType, catalogPath, archive)
+}
+
+// rewrite replaces every mapped connection reference in data
+func (m *PromoteMapping) rewrite(data []byte) []byte {
+	for from, to := range m.Connections {
+		data = bytes.ReplaceAll(data, []byte(from), []byte(to))
+	}
+	return data
+}
+
+// rewriteArchive applies rewrite to each text entry of a zip archive (such as a .dva),
+// or to the whole payload when it is not a zip
+func (m *PromoteMapping) rewriteArchive(archive []byte) ([]byte, error) {
+	if len(m.Connections) == 0 {
+		return archive, nil
+	}
+	if !bytes.HasPrefix(archive, []byte("PK\x03\x04")) {
+		return m.rewrite(archive), nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if isTextEntry(f.Name) {
+			content = m.rewrite(content)
+		}
+
+		header := f.FileHeader
+		w, err := zw.CreateHeader(&header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// isTextEntry reports whether an archive entry holds JSON or XML metadata
+func isTextEntry(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".json", ".xml", ".txt", ".properties"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
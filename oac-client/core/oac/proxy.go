@@ -0,0 +1,151 @@
+package oac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBufferedProxyBody is the largest request body the proxy holds in memory so the
+// request can be replayed after a rejected token or a transient failure; larger and
+// chunked bodies are streamed and sent once
+const maxBufferedProxyBody = 8 << 20
+
+// proxiedRequestHeaders are the caller headers forwarded to the instance; credentials
+// and cookies of the caller never are
+var proxiedRequestHeaders = []string{
+	"Accept", "Accept-Language", "If-Match", "If-None-Match", "If-Modified-Since", "Range", "Opc-Request-Id",
+}
+
+// unproxiedResponseHeaders are the instance headers not passed back to the caller
+var unproxiedResponseHeaders = map[string]bool{
+	"Connection": true, "Keep-Alive": true, "Transfer-Encoding": true, "Content-Length": true,
+	"Content-Encoding": true, "Set-Cookie": true, "Strict-Transport-Security": true,
+}
+
+// ProxyHandler returns a handler forwarding requests to the API of the instance with
+// the client's token, so callers need no credentials of their own. Only paths under
+// /api/ are forwarded, with a few caller headers (Accept, If-Match, Range, ...); the
+// client's rate limit, retries, circuit breaker and history apply as to any request.
+// Requests the profile protects are refused with 403, there being no one to confirm
+// them, and failures of the client itself are answered as {"code", "message"} JSON.
+func (c *OacClient) ProxyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, err := proxyPath(r.URL)
+		if err != nil {
+			writeProxyError(w, http.StatusNotFound, "NotFound", err.Error())
+			return
+		}
+
+		req := &Request{
+			Method:      r.Method,
+			Path:        target,
+			Header:      http.Header{},
+			ContentType: r.Header.Get("Content-Type"),
+			Context:     r.Context(),
+			// If-Match comes from the caller, not from the ETags the proxy has seen
+			NoETag: true,
+		}
+		for _, name := range proxiedRequestHeaders {
+			if v := r.Header.Values(name); len(v) > 0 {
+				req.Header[name] = v
+			}
+		}
+		if r.ContentLength != 0 {
+			if r.ContentLength > 0 && r.ContentLength <= maxBufferedProxyBody {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					writeProxyError(w, http.StatusBadRequest, "InvalidBody", "failed to read request body: "+err.Error())
+					return
+				}
+				req.Body = bytes.NewReader(body)
+			} else {
+				req.Body, req.ContentLength = r.Body, max(r.ContentLength, 0)
+			}
+		}
+
+		resp, err := c.open(req)
+		if err != nil {
+			writeProxyFailure(w, r, err)
+			return
+		}
+		defer resp.Body.Close()
+		copyProxyHeaders(w.Header(), resp.Header)
+		w.WriteHeader(resp.StatusCode)
+		if _, err := io.Copy(w, resp.Body); err != nil && r.Context().Err() == nil {
+			slog.Warn("failed to forward response", "method", r.Method, "path", r.URL.Path, "error", err)
+		}
+	})
+}
+
+// proxyPath returns the path and query forwarded for a request URL: the decoded path,
+// cleaned and escaped again, so that neither encoded characters nor dot segments get a
+// request past the /api/ prefix or the paths the profile protects
+func proxyPath(u *url.URL) (string, error) {
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == ".." {
+			return "", errors.New("paths with .. segments are not forwarded")
+		}
+	}
+	cleaned := path.Clean(u.Path)
+	if !strings.HasPrefix(cleaned, "/api/") {
+		return "", errors.New("only /api/ paths are forwarded")
+	}
+	target := (&url.URL{Path: cleaned}).EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target, nil
+}
+
+// writeProxyFailure answers with the response of the instance for API errors, and with
+// a status describing the failure otherwise
+func writeProxyFailure(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *APIError
+	var authErr *AuthError
+	var timeout *TimeoutError
+	var open *CircuitOpenError
+	switch {
+	case errors.As(err, &apiErr):
+		copyProxyHeaders(w.Header(), apiErr.Header)
+		w.WriteHeader(apiErr.StatusCode)
+		w.Write(apiErr.Body)
+	case errors.As(err, &open):
+		w.Header().Set("Retry-After", strconv.Itoa(max(int(time.Until(open.RetryAt).Seconds()), 1)))
+		writeProxyError(w, http.StatusServiceUnavailable, "CircuitOpen", err.Error())
+	case errors.Is(err, ErrNotConfirmed):
+		writeProxyError(w, http.StatusForbidden, "Protected", "the profile protects "+r.Method+" "+r.URL.Path)
+	case errors.As(err, &authErr):
+		writeProxyError(w, http.StatusBadGateway, "AuthenticationFailed", err.Error())
+	case errors.As(err, &timeout):
+		writeProxyError(w, http.StatusGatewayTimeout, "Timeout", err.Error())
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// the caller went away
+	default:
+		writeProxyError(w, http.StatusBadGateway, "BadGateway", err.Error())
+	}
+}
+
+// writeProxyError answers with an error of the proxy itself, shaped like OAC errors
+func writeProxyError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"code": code, "message": message})
+}
+
+func copyProxyHeaders(dst, src http.Header) {
+	for name, values := range src {
+		if !unproxiedResponseHeaders[http.CanonicalHeaderKey(name)] {
+			dst[name] = values
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package oac
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestProxyPath(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"/api/20210901/snapshots?limit=5", "/api/20210901/snapshots?limit=5", false},
+		{"/api/20210901//catalog/./workbooks", "/api/20210901/catalog/workbooks", false},
+		{"/api/20210901/%73napshots/s1", "/api/20210901/snapshots/s1", false},
+		{"/api/20210901/catalog/my%20folder", "/api/20210901/catalog/my%20folder", false},
+		{"/api/../admin", "", true},
+		{"/api/%2e%2e/admin", "", true},
+		{"/ui/index.html", "", true},
+		{"/api", "", true},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := proxyPath(u)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("proxyPath(%s) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("proxyPath(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// queryPath is the endpoint executing logical SQL against the semantic model
+const queryPath = apiBase + "/query"
+
+// QueryColumn describes a column of a logical SQL result
+type QueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// QueryPage is one page of a logical SQL result
+type QueryPage struct {
+	Columns []QueryColumn `json:"columns"`
+	Rows    [][]any       `json:"rows"`
+}
+
+// QueryOptions controls paging of Query
+type QueryOptions struct {
+	// PageSize is the number of rows requested per page, 1000 when zero
+	PageSize int
+	// MaxRows stops after this many rows when positive
+	MaxRows int
+}
+
+// Query runs a logical SQL statement and calls fn with every page of the result until
+// the instance reports no next page (oa-next-page header) or MaxRows is reached
+func (c *OacClient) Query(sql string, opts QueryOptions, fn func(*QueryPage) error) error {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	body, err := marshalBody(map[string]any{"logicalSql": sql})
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	page := ""
+	for {
+		query := url.Values{"limit": {fmt.Sprint(pageSize)}}
+		if page != "" {
+			query.Set("page", page)
+		}
+		resp, err := c.exchangeRequest(&Request{Method: "POST", Path: queryPath, Query: query, Body: bytes.NewReader(body)})
+		if err != nil {
+			return err
+		}
+
+		var p QueryPage
+		if err := json.Unmarshal(resp.Bytes(), &p); err != nil {
+			return fmt.Errorf("invalid response from %s: %w", queryPath, err)
+		}
+		if opts.MaxRows > 0 && rows+len(p.Rows) > opts.MaxRows {
+			p.Rows = p.Rows[:opts.MaxRows-rows]
+		}
+		rows += len(p.Rows)
+		if err := fn(&p); err != nil {
+			return err
+		}
+
+		page = resp.Header.Get("oa-next-page")
+		if page == "" || len(p.Rows) == 0 || (opts.MaxRows > 0 && rows >= opts.MaxRows) {
+			return nil
+		}
+	}
+}
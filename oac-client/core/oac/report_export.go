@@ -0,0 +1,40 @@
+package oac
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ExportFormats are the output formats a report or workbook can be rendered to
+var ExportFormats = []string{"pdf", "xlsx", "csv"}
+
+// StartExport starts rendering a catalog item of itemType (e.g. reports or workbooks) to
+// format and returns the work request id of the render job
+func (c *OacClient) StartExport(itemType, catalogPath, format string) (string, error) {
+	format = strings.ToLower(format)
+	if !slices.Contains(ExportFormats, format) {
+		return "", fmt.Errorf("unsupported export format %q, expected one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+
+	b, err := marshalBody(map[string]string{"format": format})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.exchange("POST", itemEndpoint(itemType, catalogPath)+"/actions/export", "application/json", b)
+	if err != nil {
+		return "", err
+	}
+
+	id := resp.Header.Get("oa-work-request-id")
+	if id == "" {
+		return "", fmt.Errorf("export accepted but no work request id returned")
+	}
+	return id, nil
+}
+
+// StreamExport opens the file rendered by a finished export work request; the caller
+// must close it and may Verify it once read
+func (c *OacClient) StreamExport(workRequestID string) (*Download, error) {
+	return c.download("GET", apiBase+"/workRequests/"+workRequestID+"/actions/download")
+}
@@ -0,0 +1,300 @@
+package oac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Request describes a single REST call against the OAC instance
+type Request struct {
+	Method string
+	// Path is relative to OAC_INSTANCE and may include a query string; without a leading
+	// slash it is relative to the versioned API prefix or the profile's base path
+	Path string
+	// Query values are added to any query already present in Path
+	Query url.Values
+	// Header values are sent in addition to the authorization header
+	Header http.Header
+	// ContentType defaults to application/json
+	ContentType string
+	Body        io.Reader
+	// GetBody, when set, returns a fresh copy of the body for every attempt and replaces
+	// Body, so streamed bodies can be sent again after a rejected token. Bodies given as
+	// *bytes.Reader, *bytes.Buffer or *strings.Reader are rewound without it.
+	GetBody func() (io.ReadCloser, error)
+	// ContentLength is the size of a body that net/http cannot determine, such as one
+	// returned by GetBody; zero means unknown
+	ContentLength int64
+	// NoETag disables the cached If-Match header on PUT and PATCH
+	NoETag bool
+	// Context, when set, replaces the client's context (see WithContext) for this request
+	Context context.Context
+}
+
+// requestURL joins the instance URL, path and extra query parameters
+func requestURL(instance, path string, query url.Values) (string, error) {
+	u, err := url.Parse(strings.TrimRight(instance, "/") + "/" + strings.TrimLeft(path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	if len(query) > 0 {
+		q := u.Query()
+		for key, values := range query {
+			for _, v := range values {
+				q.Add(key, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String(), nil
+}
+
+// Call executes a request and returns the pretty-printed response body
+func (c *OacClient) Call(r *Request) (string, error) {
+	resp, err := c.exchangeRequest(r)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Pretty()
+}
+
+// Open executes a request and returns the response with its body unread, so large
+// bodies can be streamed; the caller must close resp.Body
+func (c *OacClient) Open(r *Request) (*http.Response, error) {
+	return c.open(r)
+}
+
+// Do executes a request and returns the status, headers and body
+func (c *OacClient) Do(r *Request) (*Response, error) {
+	return c.exchangeRequest(r)
+}
+
+// send executes a JSON request against the OAC instance and returns the raw response body
+func (c *OacClient) send(method, path string, bodyBytes []byte) ([]byte, error) {
+	return c.sendContent(method, path, "application/json", bodyBytes)
+}
+
+// sendContent is send with an explicit request content type
+func (c *OacClient) sendContent(method, path, contentType string, bodyBytes []byte) ([]byte, error) {
+	resp, err := c.exchange(method, path, contentType, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Bytes(), nil
+}
+
+// exchange performs a request, retrying on 401, and returns status, headers and body
+func (c *OacClient) exchange(method, path, contentType string, bodyBytes []byte) (*Response, error) {
+	return c.exchangeRequest(&Request{Method: method, Path: path, ContentType: contentType, Body: bytes.NewReader(bodyBytes)})
+}
+
+// exchangeRequest is exchange for a fully described Request
+func (c *OacClient) exchangeRequest(r *Request) (*Response, error) {
+	resp, err := c.open(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	resBody, err := c.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newResponse(resp, resBody), nil
+}
+
+// open performs a request, retrying on 401, and returns a successful response
+// whose body the caller must close
+func (c *OacClient) open(r *Request) (*http.Response, error) {
+	r = c.applyProfile(r)
+	if err := c.confirmRequest(r); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	body, redacted := historyBody(r)
+	r = c.applyETag(r)
+
+	cacheFile := c.responseCacheFile(r)
+	if cacheFile != "" {
+		if resp := c.cachedGet(cacheFile); resp != nil {
+			return resp, nil
+		}
+	}
+
+	resp, err := c.openCompressed(r)
+	if err == nil {
+		c.storeETag(r, resp)
+		if cacheFile != "" {
+			resp = c.cacheResponse(cacheFile, resp)
+		}
+	}
+	c.recordHistory(r, body, redacted, start, resp, err)
+	return resp, err
+}
+
+// openRequest is open without the audit log
+func (c *OacClient) openRequest(r *Request) (*http.Response, error) {
+	url, err := requestURL(c.setting("OAC_INSTANCE"), r.Path, r.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	body := r.Body
+	if r.GetBody != nil {
+		rc, err := r.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = rc
+	}
+	ctx := r.Context
+	if ctx == nil {
+		ctx = c.context()
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(r.Method), url, body)
+	if err != nil {
+		return nil, err
+	}
+	if r.GetBody != nil {
+		req.GetBody = r.GetBody
+	}
+	if r.ContentLength > 0 {
+		req.ContentLength = r.ContentLength
+	}
+
+	contentType := r.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, values := range r.Header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	resp, err := c.pipeline()(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a conditional GET answered with 304 is not an error
+	notModified := resp.StatusCode == http.StatusNotModified && req.Header.Get("If-None-Match") != ""
+	if (resp.StatusCode < 200 || resp.StatusCode >= 300) && !notModified {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body)
+	}
+
+	return resp, nil
+}
+
+// Stream performs a request and returns the response body unread, for large downloads
+func (c *OacClient) Stream(method, path string) (io.ReadCloser, error) {
+	resp, err := c.open(&Request{Method: method, Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Upload sends body without buffering it and returns the response body
+func (c *OacClient) Upload(method, path, contentType string, body io.Reader) ([]byte, error) {
+	resp, err := c.open(&Request{Method: method, Path: path, ContentType: contentType, Body: body})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// marshalBody encodes a request body, passing raw JSON and nil through unchanged
+func marshalBody(in any) ([]byte, error) {
+	switch v := in.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// callJSON sends in (if not nil) as JSON and decodes the response into out (if not nil)
+func (c *OacClient) callJSON(method, path string, in, out any) error {
+	bodyBytes, err := marshalBody(in)
+	if err != nil {
+		return err
+	}
+
+	resBody, err := c.send(method, path, bodyBytes)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(bytes.TrimSpace(resBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resBody, out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends a request, unless the circuit breaker is open, waiting for the rate
+// limiter first
+func (c *OacClient) do(req *http.Request) (*http.Response, error) {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			if c.breaker != nil {
+				c.breaker.release()
+			}
+			return nil, err
+		}
+	}
+	req, span := startHTTPSpan(req)
+	var resp *http.Response
+	var err error
+	if c.stats != nil {
+		resp, err = c.doWithStats(req, c.roundTrip)
+	} else {
+		resp, err = c.roundTrip(req)
+	}
+	endSpan(span, resp, err)
+	if c.breaker != nil {
+		c.breaker.record(req, resp, err)
+	}
+	return resp, err
+}
+
+// roundTrip sends a request with the HTTP client, logging it at debug level
+func (c *OacClient) roundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		slog.Debug("request failed", "method", req.Method, "url", req.URL.Redacted(), "error", err)
+		return nil, asTimeout(req.Method+" "+req.URL.Path, err)
+	}
+	slog.Debug("request", "method", req.Method, "url", req.URL.Redacted(), "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
@@ -0,0 +1,152 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+	"time"
+)
+
+// RequestHook changes a request right before it is sent, e.g. to add a signature
+// header. Hooks run once per attempt, after authentication and middleware, so they see
+// the final URL and headers. An error aborts the request.
+type RequestHook func(*http.Request) error
+
+// RequestHookSymbol is the symbol a Go plugin exports as its RequestHook:
+//
+//	var RequestHook = func(r *http.Request) error { ... }
+const RequestHookSymbol = "RequestHook"
+
+// execHookTimeout is how long a request hook command may run
+const execHookTimeout = 30 * time.Second
+
+// HookSpec is a request hook of a profile: a command or a Go plugin
+type HookSpec struct {
+	Exec   string `yaml:"exec,omitempty"`
+	Plugin string `yaml:"plugin,omitempty"`
+}
+
+// WithRequestHooks adds hooks run on every request, in order
+func WithRequestHooks(hooks ...RequestHook) Option {
+	return func(c *OacClient) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// hooksFromProfile loads the request hooks of the client's profile, after those given
+// with WithRequestHooks
+func (c *OacClient) hooksFromProfile() error {
+	if c.profile == nil {
+		return nil
+	}
+	for i, spec := range c.profile.RequestHooks {
+		hook, err := spec.Load()
+		if err != nil {
+			return fmt.Errorf("invalid request hook %d of profile %s: %w", i+1, c.profile.Name, err)
+		}
+		c.hooks = append(c.hooks, hook)
+	}
+	return nil
+}
+
+// applyHooks runs the client's hooks on each attempt before handing it to next
+func (c *OacClient) applyHooks(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		for _, hook := range c.hooks {
+			if err := hook(req); err != nil {
+				return nil, fmt.Errorf("request hook: %w", err)
+			}
+		}
+		return next(req)
+	}
+}
+
+// Load returns the hook of a spec
+func (s HookSpec) Load() (RequestHook, error) {
+	switch {
+	case s.Exec != "" && s.Plugin != "":
+		return nil, fmt.Errorf("request hook sets both exec and plugin")
+	case s.Exec != "":
+		return ExecRequestHook(s.Exec), nil
+	case s.Plugin != "":
+		return LoadRequestHookPlugin(s.Plugin)
+	}
+	return nil, fmt.Errorf("request hook needs exec or plugin")
+}
+
+// LoadRequestHookPlugin opens a Go plugin (go build -buildmode=plugin) and returns the
+// RequestHook it exports. Plugins must be built with the Go version and module versions
+// of this binary and are only supported on Linux and macOS.
+func LoadRequestHookPlugin(file string) (RequestHook, error) {
+	p, err := plugin.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin %s: %w", file, err)
+	}
+	sym, err := p.Lookup(RequestHookSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", file, err)
+	}
+	switch hook := sym.(type) {
+	case func(*http.Request) error:
+		return hook, nil
+	case *func(*http.Request) error:
+		return *hook, nil
+	case *RequestHook:
+		return *hook, nil
+	}
+	return nil, fmt.Errorf("plugin %s: %s is a %T, not a func(*http.Request) error", file, RequestHookSymbol, sym)
+}
+
+// ExecRequestHook returns a hook running command through the shell for every request.
+// The command gets the request body on stdin, when it can be read again, and the request
+// in OAC_REQUEST_METHOD, OAC_REQUEST_URL, OAC_REQUEST_PATH and OAC_REQUEST_QUERY. It
+// prints headers to set as "Name: value" lines; a failure aborts the request.
+func ExecRequestHook(command string) RequestHook {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), execHookTimeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		c := exec.CommandContext(ctx, "sh", "-c", command)
+		c.Stdout = &stdout
+		c.Stderr = &stderr
+		c.Env = append(os.Environ(),
+			"OAC_REQUEST_METHOD="+req.Method,
+			"OAC_REQUEST_URL="+req.URL.String(),
+			"OAC_REQUEST_PATH="+req.URL.EscapedPath(),
+			"OAC_REQUEST_QUERY="+req.URL.RawQuery,
+		)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return fmt.Errorf("failed to read request body: %w", err)
+			}
+			defer body.Close()
+			c.Stdin = body
+		}
+
+		if err := c.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%s: %w: %s", command, err, msg)
+			}
+			return fmt.Errorf("%s: %w", command, err)
+		}
+
+		header, err := textproto.NewReader(bufio.NewReader(io.MultiReader(&stdout, strings.NewReader("\r\n")))).ReadMIMEHeader()
+		if err != nil {
+			return fmt.Errorf("%s printed invalid headers: %w", command, err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		return nil
+	}
+}
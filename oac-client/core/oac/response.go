@@ -0,0 +1,62 @@
+package oac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Response is a completed, successful OAC REST call
+type Response struct {
+	StatusCode int
+	// Status is the status line text, e.g. "200 OK"
+	Status string
+	Proto  string
+	Header http.Header
+	// Body reads the response body, which has already been received in full; closing it
+	// is optional. Bytes and DecodeInto return the whole body whether or not it was read.
+	Body io.ReadCloser
+
+	data []byte
+}
+
+// newResponse returns the Response of resp with the received body data
+func newResponse(resp *http.Response, data []byte) *Response {
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Proto:      resp.Proto,
+		Header:     resp.Header,
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		data:       data,
+	}
+}
+
+// Bytes returns the response body
+func (r *Response) Bytes() []byte {
+	return r.data
+}
+
+// DecodeInto unmarshals the JSON response body into v
+func (r *Response) DecodeInto(v any) error {
+	if err := json.Unmarshal(r.data, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// StatusLine returns the HTTP status line, e.g. "HTTP/1.1 200 OK"
+func (r *Response) StatusLine() string {
+	status := r.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+	return r.Proto + " " + status
+}
+
+// Pretty returns the body formatted for display
+func (r *Response) Pretty() (string, error) {
+	return prettyPrintJSON(r.data)
+}
@@ -0,0 +1,197 @@
+package oac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseIndexName is the index file WithResponseArchive appends to in its directory
+const ResponseIndexName = "index.jsonl"
+
+// ArchivedResponse is a line of the response archive index
+type ArchivedResponse struct {
+	Time       time.Time   `json:"time"`
+	Profile    string      `json:"profile,omitempty"`
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	File       string      `json:"file"`
+	Size       int64       `json:"size"`
+	SHA256     string      `json:"sha256"`
+	DurationMs int64       `json:"durationMs"`
+}
+
+// responseArchive writes response bodies into a directory
+type responseArchive struct {
+	dir string
+	mu  sync.Mutex
+	seq int
+}
+
+// WithResponseArchive writes the body of every API response, failed ones included, to a
+// file in dir named after the time, method and path, and appends a line describing it to
+// the index.jsonl of dir. Bodies are written as they are read, so downloads are not held
+// in memory. Token requests are not archived.
+func WithResponseArchive(dir string) Option {
+	return func(c *OacClient) {
+		c.responses = &responseArchive{dir: dir}
+	}
+}
+
+// wrap archives the responses of next
+func (a *responseArchive) wrap(c *OacClient, next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req)
+		if err != nil {
+			return resp, err
+		}
+
+		entry := ArchivedResponse{
+			Time:    start.UTC(),
+			Profile: c.ProfileName(),
+			Method:  req.Method,
+			URI:     req.URL.RequestURI(),
+			Status:  resp.StatusCode,
+			Header:  resp.Header.Clone(),
+		}
+		f, err := a.create(entry.Time, req.Method, req.URL.Path, resp.Header.Get("Content-Type"))
+		if err != nil {
+			slog.Warn("failed to archive response", "dir", a.dir, "error", err)
+			return resp, nil
+		}
+		entry.File = filepath.Base(f.Name())
+		resp.Body = &archivedBody{body: resp.Body, file: f, sum: sha256.New(), archive: a, entry: entry, start: start}
+		return resp, nil
+	}
+}
+
+// create opens the file of a new response, e.g. 20261015T094512.083Z-0001-GET-api_20210901_catalog.json
+func (a *responseArchive) create(t time.Time, method, urlPath, contentType string) (*os.File, error) {
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return nil, err
+	}
+	for {
+		a.mu.Lock()
+		a.seq++
+		seq := a.seq
+		a.mu.Unlock()
+
+		name := fmt.Sprintf("%s-%04d-%s-%s%s", t.Format("20060102T150405.000Z"), seq, method, responseFileName(urlPath), responseFileExt(contentType))
+		f, err := os.OpenFile(filepath.Join(a.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		// another process archiving into dir may have taken the name
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+}
+
+// responseFileName turns a URL path into a file name part, e.g. api_20210901_catalog
+func responseFileName(urlPath string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.Trim(urlPath, "/"))
+	if len(name) > 120 {
+		name = name[:120]
+	}
+	if name == "" {
+		name = "root"
+	}
+	return name
+}
+
+// responseFileExt picks the file extension of a response from its content type
+func responseFileExt(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return ".json"
+	case mediaType == "text/plain":
+		return ".txt"
+	case mediaType == "text/csv":
+		return ".csv"
+	case mediaType == "application/xml" || mediaType == "text/xml":
+		return ".xml"
+	case mediaType == "application/pdf":
+		return ".pdf"
+	}
+	return ".bin"
+}
+
+// index appends entry to the index file
+func (a *responseArchive) index(entry ArchivedResponse) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(a.dir, ResponseIndexName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// archivedBody copies a response body to its archive file as it is read, and indexes
+// it at the end of the body or when it is closed
+type archivedBody struct {
+	body    io.ReadCloser
+	file    *os.File
+	sum     hash.Hash
+	archive *responseArchive
+	entry   ArchivedResponse
+	start   time.Time
+	once    sync.Once
+}
+
+func (b *archivedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.file.Write(p[:n])
+		b.sum.Write(p[:n])
+		b.entry.Size += int64(n)
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *archivedBody) Close() error {
+	b.finish()
+	return b.body.Close()
+}
+
+// finish closes the archive file and writes the index line
+func (b *archivedBody) finish() {
+	b.once.Do(func() {
+		b.file.Close()
+		b.entry.SHA256 = hex.EncodeToString(b.sum.Sum(nil))
+		b.entry.DurationMs = time.Since(b.start).Milliseconds()
+		if err := b.archive.index(b.entry); err != nil {
+			slog.Warn("failed to index archived response", "dir", b.archive.dir, "error", err)
+		}
+	})
+}
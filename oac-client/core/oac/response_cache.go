@@ -0,0 +1,123 @@
+package oac
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCachedBody is the largest response body stored in the response cache
+const maxCachedBody = 10 << 20
+
+// responseCacheDir holds one file per cached GET response
+var responseCacheDir = filepath.Join(cacheDir, "responses")
+
+// cachedResponse is the on-disk form of a cached GET response
+type cachedResponse struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Proto      string      `json:"proto"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+}
+
+// WithResponseCache serves successful GET responses from disk for ttl after they were
+// fetched. Cached entries are keyed by profile, URL and Accept header.
+func WithResponseCache(ttl time.Duration) Option {
+	return func(c *OacClient) {
+		c.cacheTTL = ttl
+	}
+}
+
+// ClearResponseCache removes every cached response
+func ClearResponseCache() error {
+	return os.RemoveAll(responseCacheDir)
+}
+
+// responseCacheFile returns the cache file for r, or "" when r is not cacheable
+func (c *OacClient) responseCacheFile(r *Request) string {
+	if c.cacheTTL <= 0 || !strings.EqualFold(r.Method, http.MethodGet) ||
+		r.Header.Get("If-None-Match") != "" || r.Header.Get("Cache-Control") == "no-cache" {
+		return ""
+	}
+
+	url, err := requestURL(c.setting("OAC_INSTANCE"), r.Path, r.Query)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(c.ProfileName() + "\x00" + url + "\x00" + r.Header.Get("Accept")))
+	return filepath.Join(responseCacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cachedGet returns a response from the cache when a fresh one exists
+func (c *OacClient) cachedGet(file string) *http.Response {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || time.Since(cached.Stored) > c.cacheTTL {
+		return nil
+	}
+
+	slog.Debug("serving response from cache", "url", cached.URL, "age", time.Since(cached.Stored).Round(time.Second))
+	header := cached.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(time.Since(cached.Stored).Seconds())))
+	return &http.Response{
+		StatusCode:    cached.StatusCode,
+		Status:        cached.Status,
+		Proto:         cached.Proto,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+	}
+}
+
+// cacheResponse stores a 200 response and returns an equivalent one for the caller.
+// Bodies larger than maxCachedBody are passed through without being cached.
+func (c *OacClient) cacheResponse(file string, resp *http.Response) *http.Response {
+	if resp.StatusCode != http.StatusOK {
+		return resp
+	}
+
+	br := bufio.NewReaderSize(resp.Body, 64<<10)
+	body, err := io.ReadAll(io.LimitReader(br, maxCachedBody+1))
+	if err != nil || len(body) > maxCachedBody {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), br), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.Marshal(cachedResponse{
+		URL:        resp.Request.URL.Redacted(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Proto:      resp.Proto,
+		Header:     resp.Header,
+		Body:       body,
+		Stored:     time.Now(),
+	})
+	if err == nil && os.MkdirAll(responseCacheDir, 0o700) == nil {
+		_ = os.WriteFile(file, data, 0o600)
+	}
+	return resp
+}
+
+// readCloser pairs a reader with the Close of the body it reads from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,113 @@
+package oac
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxResponseSize is the largest response body read into memory, unless set by
+// WithMaxResponseSize or OAC_MAX_RESPONSE_SIZE
+const DefaultMaxResponseSize = 64 << 20
+
+// ResponseTooLargeError is returned instead of a response whose body exceeds the
+// in-memory limit. The body was saved to File, which the caller should remove.
+type ResponseTooLargeError struct {
+	File  string
+	Size  int64
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response of %s exceeds the in-memory limit of %s, saved to %s",
+		FormatSize(e.Size), FormatSize(e.Limit), e.File)
+}
+
+// WithMaxResponseSize sets the largest response body read into memory; larger bodies
+// are spooled to a temporary file, see ResponseTooLargeError. Streaming calls such as
+// Open and Stream are not limited.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *OacClient) {
+		c.maxResponse = n
+	}
+}
+
+// maxResponseFromEnv applies OAC_MAX_RESPONSE_SIZE unless WithMaxResponseSize was given
+func (c *OacClient) maxResponseFromEnv() error {
+	if c.maxResponse > 0 {
+		return nil
+	}
+	c.maxResponse = DefaultMaxResponseSize
+	if v := c.setting("OAC_MAX_RESPONSE_SIZE"); v != "" {
+		n, err := ParseSize(v)
+		if err != nil {
+			return fmt.Errorf("invalid OAC_MAX_RESPONSE_SIZE: %w", err)
+		}
+		c.maxResponse = n
+	}
+	return nil
+}
+
+// readResponseBody reads a response body into memory, spooling it to a temporary file
+// when it is larger than the client's limit
+func (c *OacClient) readResponseBody(body io.Reader) ([]byte, error) {
+	limit := c.maxResponse
+	if limit <= 0 {
+		limit = DefaultMaxResponseSize
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil || int64(len(data)) <= limit {
+		return data, err
+	}
+
+	f, err := os.CreateTemp("", "oac-response-*")
+	if err != nil {
+		return nil, fmt.Errorf("response exceeds %s and cannot be spooled: %w", FormatSize(limit), err)
+	}
+	size, err := io.Copy(f, io.MultiReader(bytes.NewReader(data), body))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to spool response: %w", err)
+	}
+	return nil, &ResponseTooLargeError{File: f.Name(), Size: size, Limit: limit}
+}
+
+// ParseSize parses a byte count such as 1048576, 512KiB, 64MiB, 2GiB or 10MB
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRightFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	unit := strings.ToUpper(strings.TrimSpace(s[len(num):]))
+
+	multipliers := map[string]int64{
+		"": 1, "B": 1,
+		"K": 1 << 10, "KB": 1000, "KIB": 1 << 10,
+		"M": 1 << 20, "MB": 1000 * 1000, "MIB": 1 << 20,
+		"G": 1 << 30, "GB": 1000 * 1000 * 1000, "GIB": 1 << 30,
+	}
+	mult, ok := multipliers[unit]
+	n, err := strconv.ParseInt(num, 10, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
+// FormatSize formats a byte count with a binary unit
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
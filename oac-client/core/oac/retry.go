@@ -0,0 +1,91 @@
+package oac
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay caps the wait between two attempts, Retry-After included
+const maxRetryDelay = 30 * time.Second
+
+// WithRetries sends idempotent requests (GET, HEAD, OPTIONS, PUT, DELETE) up to n more
+// times when the instance answers 429, 502, 503 or 504 or cannot be reached, waiting
+// for its Retry-After or an exponential backoff from 500ms between attempts. Requests
+// whose body cannot be rebuilt are sent once.
+func WithRetries(n int) Option {
+	return func(c *OacClient) {
+		c.retries = max(n, 0)
+	}
+}
+
+// retry sends a request again on transient failures, see WithRetries
+func (c *OacClient) retry(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if !idempotent(req.Method) || !rewindable(req) {
+			return next(req)
+		}
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				var err error
+				if req, err = rewind(req); err != nil {
+					return nil, err
+				}
+			}
+			resp, err := next(req)
+			if attempt >= c.retries || !transient(resp, err) {
+				return resp, err
+			}
+
+			delay := retryDelay(attempt, resp)
+			reason := "error"
+			if resp != nil {
+				reason = resp.Status
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			slog.Debug("retrying request", "method", req.Method, "url", req.URL.Redacted(), "reason", reason, "attempt", attempt+1, "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// transient reports whether a failed attempt may succeed when sent again
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		var open *CircuitOpenError
+		return !errors.As(err, &open) && !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay is the Retry-After of resp in seconds, or 500ms doubled with every attempt
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := 500 * time.Millisecond << min(attempt, 6)
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			delay = time.Duration(s) * time.Second
+		}
+	}
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,90 @@
+package oac
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rolesPath is the endpoint for the application roles of the instance
+const rolesPath = apiBase + "/roles"
+
+// builtinRoles are the application roles every instance comes with
+var builtinRoles = map[string]bool{
+	"AuthenticatedUser": true, "BIServiceAdministrator": true, "BIDataModelAuthor": true,
+	"BIDatasetAuthor": true, "BIDataLoadAuthor": true, "BIContentAuthor": true, "BIConsumer": true,
+	"DVContentAuthor": true, "DVConsumer": true,
+}
+
+// Role is an application role and the principals granted it
+type Role struct {
+	Name        string       `json:"name" yaml:"-"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Members     []RoleMember `json:"members,omitempty" yaml:"members"`
+
+	// membersSet is true when a state file declares the members, even as []
+	membersSet bool
+}
+
+// UnmarshalYAML notes whether the members are declared, so roles without them keep theirs
+func (r *Role) UnmarshalYAML(node *yaml.Node) error {
+	type plain Role
+	if err := node.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "members" {
+			r.membersSet = true
+		}
+	}
+	return nil
+}
+
+// Builtin reports whether the role is one every instance comes with, such as BIConsumer
+func (r Role) Builtin() bool {
+	return builtinRoles[r.Name]
+}
+
+// RoleMember is a principal granted an application role
+type RoleMember struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is the kind of principal: user, group or role
+	Type string `json:"type" yaml:"type"`
+}
+
+func (m RoleMember) key() string {
+	return strings.ToLower(m.Type) + ":" + m.Name
+}
+
+func (m RoleMember) String() string {
+	return m.Type + ":" + m.Name
+}
+
+// ListRoles returns the application roles of the instance, sorted by name
+func (c *OacClient) ListRoles() ([]Role, error) {
+	var page struct {
+		Items []Role `json:"items"`
+	}
+	if err := c.callJSON("GET", rolesPath, nil, &page); err != nil {
+		return nil, err
+	}
+	sort.Slice(page.Items, func(i, j int) bool { return page.Items[i].Name < page.Items[j].Name })
+	return page.Items, nil
+}
+
+// CreateRole creates an application role
+func (c *OacClient) CreateRole(role Role) error {
+	return c.callJSON("POST", rolesPath, role, nil)
+}
+
+// UpdateRole replaces the description and members of an application role
+func (c *OacClient) UpdateRole(role Role) error {
+	return c.callJSON("PUT", rolesPath+"/"+url.PathEscape(role.Name), role, nil)
+}
+
+// DeleteRole removes an application role
+func (c *OacClient) DeleteRole(name string) error {
+	return c.callJSON("DELETE", rolesPath+"/"+url.PathEscape(name), nil, nil)
+}
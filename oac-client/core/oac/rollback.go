@@ -0,0 +1,157 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackupManifestName is the manifest WithBackup writes in its directory
+const BackupManifestName = "manifest.json"
+
+// backup holds the previous versions of the catalog items imported by a client
+type backup struct {
+	dir      string
+	mu       sync.Mutex
+	manifest *ExportManifest
+	saved    map[string]bool
+}
+
+// WithBackup saves the current version of every catalog item into dir before an import
+// replaces it, and lists it in the manifest.json of dir, so that Rollback can undo a
+// bulk import or promotion that failed halfway. Items that did not exist are listed too,
+// and rolling back deletes them.
+func WithBackup(dir string) Option {
+	return func(c *OacClient) {
+		c.backup = &backup{dir: dir, saved: map[string]bool{}}
+	}
+}
+
+// BackupManifest returns the manifest of the items backed up so far, "" when none were
+func (c *OacClient) BackupManifest() string {
+	if c.backup == nil {
+		return ""
+	}
+	c.backup.mu.Lock()
+	defer c.backup.mu.Unlock()
+	if c.backup.manifest == nil {
+		return ""
+	}
+	return filepath.Join(c.backup.dir, BackupManifestName)
+}
+
+// saveBackup backs up a catalog item before it is first imported over
+func (c *OacClient) saveBackup(itemType, catalogPath string) error {
+	b := c.backup
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.saved[catalogPath] {
+		// keep the version from before the first change
+		return nil
+	}
+	if b.manifest == nil {
+		if err := os.MkdirAll(b.dir, 0o755); err != nil {
+			return err
+		}
+		b.manifest = &ExportManifest{Instance: c.Instance(), Root: "/", ExportedAt: time.Now().UTC(), dir: b.dir}
+	}
+
+	item := CatalogItem{ID: EncodeCatalogID(catalogPath), Type: itemType, Name: path.Base(catalogPath), Path: catalogPath}
+	rel := strings.TrimPrefix(catalogPath, "/")
+	entry := ManifestItem{ID: item.ID, Type: itemType, Path: catalogPath}
+	sum, err := c.archiveItem(item, filepath.Join(b.dir, filepath.FromSlash(rel)))
+	entry.ExportedAt = time.Now().UTC()
+	switch {
+	case StatusCode(err) == http.StatusNotFound:
+		entry.Absent = true
+	case err != nil:
+		return fmt.Errorf("failed to back up %s: %w", catalogPath, err)
+	default:
+		entry.Archive, entry.SHA256 = rel+archiveExt, sum
+	}
+
+	b.manifest.Items = append(b.manifest.Items, entry)
+	b.saved[catalogPath] = true
+	return WriteExportManifest(filepath.Join(b.dir, BackupManifestName), b.manifest)
+}
+
+// RollbackResult is the outcome of restoring one item of a manifest
+type RollbackResult struct {
+	Item ManifestItem
+	// Deleted is set for items that did not exist before and were removed
+	Deleted bool
+	Err     error
+}
+
+// Rollback restores the items of a manifest written by WithBackup, last changed first:
+// previous versions are imported again with their permissions, and items that did not
+// exist are deleted. Every item is attempted; report is called with each result.
+func (c *OacClient) Rollback(m *ExportManifest, dryRun bool, report func(RollbackResult)) []RollbackResult {
+	var results []RollbackResult
+	for i := len(m.Items) - 1; i >= 0; i-- {
+		item := m.Items[i]
+		res := RollbackResult{Item: item, Deleted: item.Absent}
+		switch {
+		case item.Error != "":
+			res.Err = fmt.Errorf("not in the backup: %s", item.Error)
+		case dryRun:
+		case item.Absent:
+			res.Err = c.deleteItem(item.Type, item.Path)
+		default:
+			res.Err = c.restoreItem(m, item)
+		}
+		results = append(results, res)
+		if report != nil {
+			report(res)
+		}
+	}
+	return results
+}
+
+// restoreItem imports the archive of a manifest item, checked against its checksum, and
+// restores the ACL saved next to it
+func (c *OacClient) restoreItem(m *ExportManifest, item ManifestItem) error {
+	file := m.ArchiveFile(item)
+	if err := VerifyChecksum(file); err != nil {
+		return err
+	}
+	archive, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := c.ImportItem(item.Type, item.Path, archive); err != nil {
+		return err
+	}
+
+	metaBytes, err := os.ReadFile(strings.TrimSuffix(file, archiveExt) + itemMetaExt)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var meta archivedItem
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return fmt.Errorf("invalid item metadata of %s: %w", item.Path, err)
+	}
+	if len(meta.ACL) > 0 {
+		if _, err := c.send("POST", itemEndpoint(item.Type, item.Path)+"/actions/updateACL", meta.ACL); err != nil {
+			return fmt.Errorf("failed to restore ACL of %s: %w", item.Path, err)
+		}
+	}
+	return nil
+}
+
+// deleteItem removes a catalog item; one that is already gone is not an error
+func (c *OacClient) deleteItem(itemType, catalogPath string) error {
+	if _, err := c.send("DELETE", itemEndpoint(itemType, catalogPath), nil); err != nil && StatusCode(err) != http.StatusNotFound {
+		return fmt.Errorf("failed to delete %s: %w", catalogPath, err)
+	}
+	return nil
+}
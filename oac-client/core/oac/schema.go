@@ -0,0 +1,302 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SchemaIssue is a mismatch between a request body and the schema of its operation
+type SchemaIssue struct {
+	// Field is the location in the body, e.g. "connectionParams.port" or "items[2]"
+	Field   string
+	Message string
+}
+
+func (i SchemaIssue) String() string {
+	if i.Field == "" {
+		return i.Message
+	}
+	return i.Field + ": " + i.Message
+}
+
+// FindOperation returns the operation whose method and path template match a request
+// path (which may carry a query string), preferring the most literal template
+func (s *OpenAPISpec) FindOperation(method, path string) *Operation {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *Operation
+	bestLiteral := -1
+	for _, op := range s.Operations {
+		if op.Method != method {
+			continue
+		}
+		template := strings.Split(strings.Trim(op.Path, "/"), "/")
+		if len(template) != len(segments) {
+			continue
+		}
+		literal := 0
+		matched := true
+		for i, t := range template {
+			if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+				if segments[i] == "" {
+					matched = false
+					break
+				}
+				continue
+			}
+			if t != segments[i] {
+				matched = false
+				break
+			}
+			literal++
+		}
+		if matched && literal > bestLiteral {
+			best, bestLiteral = op, literal
+		}
+	}
+	return best
+}
+
+// ValidateBody checks a JSON request body against the operation's body schema, reporting
+// wrong types, missing required fields, values outside an enum and unknown fields
+func (s *OpenAPISpec) ValidateBody(op *Operation, body []byte) ([]SchemaIssue, error) {
+	if op.Body == nil {
+		return nil, nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, fmt.Errorf("request body is not valid JSON: %w", err)
+	}
+	var issues []SchemaIssue
+	s.validate(op.Body, value, "", &issues)
+	return issues, nil
+}
+
+// validate appends the issues of value against schema to issues
+func (s *OpenAPISpec) validate(schema map[string]any, value any, field string, issues *[]SchemaIssue) {
+	schema = s.flatten(schema)
+	if len(schema) == 0 {
+		return
+	}
+	report := func(format string, args ...any) {
+		*issues = append(*issues, SchemaIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); !nullable && schema["type"] != nil {
+			report("must not be null")
+		}
+		return
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		alternatives := asSlice(schema[key])
+		if len(alternatives) == 0 {
+			continue
+		}
+		matched := slices.ContainsFunc(alternatives, func(alt any) bool {
+			var altIssues []SchemaIssue
+			sub, _ := s.resolve(alt).(map[string]any)
+			s.validate(sub, value, field, &altIssues)
+			return len(altIssues) == 0
+		})
+		if !matched {
+			report("matches none of the allowed schemas")
+		}
+		return
+	}
+
+	if typ, _ := schema["type"].(string); typ != "" && !jsonTypeMatches(typ, value) {
+		report("expected %s, got %s", typ, jsonTypeName(value))
+		return
+	}
+	if enum := asSlice(schema["enum"]); len(enum) > 0 && !slices.ContainsFunc(enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		values := make([]string, len(enum))
+		for i, e := range enum {
+			values[i] = fmt.Sprint(e)
+		}
+		report("must be one of %s, got %v", strings.Join(values, ", "), value)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		s.validateObject(schema, v, field, issues)
+	case []any:
+		items, _ := s.resolve(schema["items"]).(map[string]any)
+		for i, item := range v {
+			s.validate(items, item, fmt.Sprintf("%s[%d]", field, i), issues)
+		}
+	}
+}
+
+// validateObject checks required, known and additional properties
+func (s *OpenAPISpec) validateObject(schema, obj map[string]any, field string, issues *[]SchemaIssue) {
+	props, _ := schema["properties"].(map[string]any)
+	child := func(name string) string {
+		if field == "" {
+			return name
+		}
+		return field + "." + name
+	}
+
+	for _, r := range asSlice(schema["required"]) {
+		if name, ok := r.(string); ok {
+			if _, present := obj[name]; !present {
+				*issues = append(*issues, SchemaIssue{Field: child(name), Message: "required field is missing"})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := s.resolve(props[name]).(map[string]any); ok {
+			s.validate(prop, obj[name], child(name), issues)
+			continue
+		}
+		switch extra := s.resolve(schema["additionalProperties"]).(type) {
+		case map[string]any:
+			s.validate(extra, obj[name], child(name), issues)
+		case bool:
+			if !extra {
+				*issues = append(*issues, SchemaIssue{Field: child(name), Message: unknownField(name, props)})
+			}
+		default:
+			// typos are the common case, so unknown fields of a described object are reported
+			if len(props) > 0 {
+				*issues = append(*issues, SchemaIssue{Field: child(name), Message: unknownField(name, props)})
+			}
+		}
+	}
+}
+
+// flatten resolves a schema and merges its allOf parts into one schema
+func (s *OpenAPISpec) flatten(schema map[string]any) map[string]any {
+	schema, _ = s.resolve(schema).(map[string]any)
+	parts := asSlice(schema["allOf"])
+	if len(parts) == 0 {
+		return schema
+	}
+
+	merged := map[string]any{}
+	props := map[string]any{}
+	var required []any
+	merge := func(p map[string]any) {
+		for k, v := range p {
+			switch k {
+			case "allOf":
+			case "properties":
+				m, _ := v.(map[string]any)
+				for name, prop := range m {
+					props[name] = prop
+				}
+			case "required":
+				required = append(required, asSlice(v)...)
+			default:
+				merged[k] = v
+			}
+		}
+	}
+
+	merge(schema)
+	for _, part := range parts {
+		p, _ := s.resolve(part).(map[string]any)
+		merge(s.flatten(p))
+	}
+	if len(props) > 0 {
+		merged["properties"] = props
+	}
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+	return merged
+}
+
+// unknownField describes an unknown field, suggesting a close property name
+func unknownField(name string, props map[string]any) string {
+	best, bestDist := "", 3
+	for p := range props {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(p)); d < bestDist || (d == bestDist && p < best) {
+			best, bestDist = p, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown field, did you mean %q?", best)
+	}
+	return "unknown field"
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// jsonTypeMatches reports whether a decoded JSON value has the schema type
+func jsonTypeMatches(typ string, v any) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	}
+	return true
+}
+
+// jsonTypeName names the type of a decoded JSON value
+func jsonTypeName(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
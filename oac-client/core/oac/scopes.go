@@ -0,0 +1,31 @@
+package oac
+
+import "strings"
+
+// WithScopes requests tokens with exactly these scopes instead of the configured ones.
+// Such tokens are obtained fresh and kept out of the token cache, so they never replace
+// or reuse the profile's regular token.
+func WithScopes(scopes []string) Option {
+	return func(c *OacClient) {
+		c.scopeOverride = scopes
+	}
+}
+
+// ParseScopes splits a comma or space separated scope list
+func ParseScopes(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// Scopes returns the scopes tokens are requested with: the WithScopes override, the
+// profile's scopes list, or IDCS_OAC_SCOPE
+func (c *OacClient) Scopes() []string {
+	if len(c.scopeOverride) > 0 {
+		return c.scopeOverride
+	}
+	if c.profile != nil && len(c.profile.Scopes) > 0 {
+		return c.profile.Scopes
+	}
+	return ParseScopes(c.setting("IDCS_OAC_SCOPE"))
+}
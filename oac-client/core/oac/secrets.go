@@ -0,0 +1,145 @@
+package oac
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/common/auth"
+	"github.com/oracle/oci-go-sdk/v65/secrets"
+)
+
+const (
+	ociSecretScheme   = "secret://"
+	vaultSecretScheme = "vault://"
+)
+
+// resolveSecret returns the plain value of a credential setting.
+//
+// Supported forms:
+//
+//	secret://ocid1.vaultsecret...    OCI Vault secret (latest version)
+//	vault://secret/data/oac#password HashiCorp Vault KV path and field
+//
+// Any other value is returned unchanged. HashiCorp Vault is read through the client's
+// proxy and TLS settings.
+func (c *OacClient) resolveSecret(ctx context.Context, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, ociSecretScheme):
+		return readOciSecret(ctx, strings.TrimPrefix(value, ociSecretScheme))
+	case strings.HasPrefix(value, vaultSecretScheme):
+		return readVaultSecret(ctx, c.sharedClient, strings.TrimPrefix(value, vaultSecretScheme))
+	default:
+		return value, nil
+	}
+}
+
+// ociConfigProvider picks the OCI authentication method from OCI_CLI_AUTH
+func ociConfigProvider() (common.ConfigurationProvider, error) {
+	switch os.Getenv("OCI_CLI_AUTH") {
+	case "instance_principal":
+		return auth.InstancePrincipalConfigurationProvider()
+	case "resource_principal":
+		return auth.ResourcePrincipalConfigurationProvider()
+	case "", "api_key":
+		configFile := os.Getenv("OCI_CONFIG_FILE")
+		profile := os.Getenv("OCI_CLI_PROFILE")
+		if configFile == "" && profile == "" {
+			return common.DefaultConfigProvider(), nil
+		}
+		if profile == "" {
+			profile = "DEFAULT"
+		}
+		return common.CustomProfileConfigProvider(configFile, profile), nil
+	default:
+		return nil, fmt.Errorf("unsupported OCI_CLI_AUTH: %s", os.Getenv("OCI_CLI_AUTH"))
+	}
+}
+
+// readOciSecret fetches the current version of an OCI Vault secret
+func readOciSecret(ctx context.Context, secretID string) (string, error) {
+	provider, err := ociConfigProvider()
+	if err != nil {
+		return "", err
+	}
+
+	client, err := secrets.NewSecretsClientWithConfigurationProvider(provider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCI secrets client: %w", err)
+	}
+
+	resp, err := client.GetSecretBundle(ctx, secrets.GetSecretBundleRequest{SecretId: &secretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to read OCI secret %s: %w", secretID, err)
+	}
+
+	content, ok := resp.SecretBundleContent.(secrets.Base64SecretBundleContentDetails)
+	if !ok || content.Content == nil {
+		return "", fmt.Errorf("OCI secret %s has no base64 content", secretID)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(*content.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode OCI secret %s: %w", secretID, err)
+	}
+
+	return string(value), nil
+}
+
+// readVaultSecret reads a field from a HashiCorp Vault KV secret (v1 or v2)
+func readVaultSecret(ctx context.Context, client *http.Client, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || field == "" {
+		return "", fmt.Errorf("vault reference must be vault://<path>#<field>")
+	}
+
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set for vault:// secrets")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read vault secret %s: status %d", path, resp.StatusCode)
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("invalid vault response for %s: %w", path, err)
+	}
+
+	// KV v2 nests the secret under data.data
+	data := payload.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		data = inner
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("field %q not found in vault secret %s", field, path)
+	}
+
+	return value, nil
+}
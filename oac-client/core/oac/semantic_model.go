@@ -0,0 +1,95 @@
+package oac
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// semanticModelPath is the endpoint for the deployed semantic model
+const semanticModelPath = apiBase + "/semanticModel"
+
+// ModelFormat returns "smml" for zip archives and "rpd" otherwise, based on the file name
+func ModelFormat(file string) string {
+	if strings.EqualFold(filepath.Ext(file), ".zip") {
+		return "smml"
+	}
+	return "rpd"
+}
+
+// ExportModel downloads the deployed semantic model in format (rpd or smml) into w
+func (c *OacClient) ExportModel(format, password string, w io.Writer) error {
+	body, err := marshalBody(map[string]string{"format": format, "password": password})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.open(&Request{
+		Method: "POST",
+		Path:   semanticModelPath + "/actions/download",
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// ImportModel uploads an .rpd file or SMML zip without deploying it and returns the
+// work request id when the upload is processed asynchronously
+func (c *OacClient) ImportModel(file, password string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("format", ModelFormat(file))
+	if password != "" {
+		_ = mw.WriteField("password", password)
+	}
+	part, err := mw.CreateFormFile("file", filepath.Base(file))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := c.exchange("POST", semanticModelPath+"/actions/upload", mw.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return resp.Header.Get("oa-work-request-id"), nil
+}
+
+// DeployModel activates the most recently uploaded model and returns the work request id
+func (c *OacClient) DeployModel(comment string) (string, error) {
+	body, err := marshalBody(map[string]string{"comment": comment})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.exchange("POST", semanticModelPath+"/actions/deploy", "application/json", body)
+	if err != nil {
+		return "", err
+	}
+
+	id := resp.Header.Get("oa-work-request-id")
+	if id == "" {
+		return "", fmt.Errorf("deployment accepted but no work request id returned")
+	}
+	return id, nil
+}
@@ -0,0 +1,44 @@
+package oac
+
+import (
+	"net/url"
+	"time"
+)
+
+// sessionsPath is the endpoint for the analytic sessions of the instance
+const sessionsPath = apiBase + "/system/sessions"
+
+// Session is a user session on the instance
+type Session struct {
+	ID             string `json:"id"`
+	User           string `json:"user"`
+	ClientAddress  string `json:"clientAddress,omitempty"`
+	Client         string `json:"client,omitempty"`
+	StartedAt      string `json:"startedAt,omitempty"`
+	LastActivityAt string `json:"lastActivityAt,omitempty"`
+	RunningQueries int    `json:"runningQueries"`
+	Status         string `json:"status,omitempty"`
+}
+
+// Started parses StartedAt, returning the zero time when it is missing or malformed
+func (s Session) Started() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.StartedAt)
+	return t
+}
+
+// ListSessions returns the sessions open on the instance
+func (c *OacClient) ListSessions() ([]Session, error) {
+	var page struct {
+		Items []Session `json:"items"`
+	}
+	if err := c.callJSON("GET", sessionsPath, nil, &page); err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// KillSession terminates a session and cancels its running queries
+func (c *OacClient) KillSession(id string) error {
+	_, err := c.send("DELETE", sessionsPath+"/"+url.PathEscape(id), nil)
+	return err
+}
@@ -0,0 +1,140 @@
+package oac
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// SettingKeys are the settings reported by Settings, in display order
+var SettingKeys = []string{
+	"OAC_INSTANCE", "IDCS_TOKEN_URL", "IDCS_GRANT_TYPE", "IDCS_OAC_CLIENT_ID", "IDCS_OAC_CLIENT_SECRET",
+	"IDCS_OAC_SCOPE", "OAC_USERNAME", "OAC_PASSWORD", "IDCS_AUTHORIZE_URL", "IDCS_DEVICE_URL",
+	"IDCS_REDIRECT_PORT", "IDCS_USERINFO_URL", "OAC_CA_BUNDLE", "OAC_CLIENT_CERT", "OAC_CLIENT_KEY",
+	"OAC_INSECURE_SKIP_VERIFY", "OAC_TLS_MIN_VERSION", "OAC_TLS_CIPHERS",
+	"OAC_RATE_LIMIT", "OAC_AUTH_RETRIES", "OAC_MAX_RESPONSE_SIZE", "OAC_API_VERSION", "OAC_COMPRESS_MIN_SIZE", "OAC_OPENAPI_SPEC", "OAC_HISTORY_FILE",
+	"OAC_TOKEN_STORE", "OAC_CIRCUIT_BREAKER",
+}
+
+// Setting is the effective value of a setting and the layer it comes from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// envSources records the environment variables filled in by LoadEnvLayers, by source
+var envSources = map[string]string{}
+
+// DotEnvFiles returns the .env files consulted, in precedence order: the one in the current
+// directory, then the one next to the config file
+func DotEnvFiles(configPath string) []string {
+	files := []string{".env"}
+	if beside := filepath.Join(filepath.Dir(configPath), ".env"); filepath.Clean(beside) != ".env" {
+		files = append(files, beside)
+	}
+	return files
+}
+
+// LoadEnvLayers fills environment variables that are not set from the .env files, in order,
+// and then from the config file's env defaults, so that the environment always wins over
+// .env files and .env files over the config file. It returns the .env files found.
+func LoadEnvLayers(files []string, cfg *Config) ([]string, error) {
+	var found []string
+	for _, file := range files {
+		values, err := godotenv.Read(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return found, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		found = append(found, file)
+		fillEnv(values, file)
+	}
+	if cfg != nil {
+		fillEnv(cfg.Env, "config file "+cfg.Path)
+	}
+	return found, nil
+}
+
+// fillEnv sets the unset variables of values, recording source
+func fillEnv(values map[string]string, source string) {
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		_ = os.Setenv(key, value)
+		envSources[key] = source
+	}
+}
+
+// EnvSource names the layer an environment variable comes from: a .env file, the config
+// file, "environment", or "" when it is not set
+func EnvSource(key string) string {
+	if _, set := os.LookupEnv(key); !set {
+		return ""
+	}
+	if source, ok := envSources[key]; ok {
+		return source
+	}
+	return "environment"
+}
+
+// Setting returns the effective value of a setting and where it comes from. Flags passed to
+// the client come first, then variables exported in the environment, then the selected
+// profile, then the .env files and the config file's env. Profiles rank above the .env
+// files so that a .env with one instance's settings does not redirect every profile.
+func (c *OacClient) Setting(key string) Setting {
+	s := Setting{Key: key}
+	switch {
+	case key == "OAC_INSTANCE" && c.instance != "":
+		s.Value, s.Source = c.instance, "--instance"
+	case key == "IDCS_OAC_SCOPE" && len(c.scopeOverride) > 0:
+		s.Value, s.Source = strings.Join(c.scopeOverride, " "), "--scope"
+	case os.Getenv(key) != "" && EnvSource(key) == "environment":
+		s.Value, s.Source = os.Getenv(key), "environment"
+	case key == "IDCS_OAC_SCOPE" && c.profile != nil && len(c.profile.Scopes) > 0:
+		s.Value, s.Source = strings.Join(c.profile.Scopes, " "), "profile "+c.profile.Name
+	default:
+		if c.profile != nil {
+			if v, ok := c.profile.Env[key]; ok {
+				s.Value, s.Source = v, "profile "+c.profile.Name
+				return s
+			}
+		}
+		s.Value, s.Source = os.Getenv(key), EnvSource(key)
+	}
+	return s
+}
+
+// Settings returns the effective values of SettingKeys
+func (c *OacClient) Settings() []Setting {
+	settings := make([]Setting, len(SettingKeys))
+	for i, key := range SettingKeys {
+		settings[i] = c.Setting(key)
+	}
+	return settings
+}
+
+// Masked returns the value for display, hiding plain passwords and client secrets while
+// keeping secret://, vault:// references readable
+func (s Setting) Masked() string {
+	if s.Value == "" || strings.HasPrefix(s.Value, ociSecretScheme) || strings.HasPrefix(s.Value, vaultSecretScheme) {
+		return s.Value
+	}
+	if strings.Contains(s.Key, "SECRET") || strings.Contains(s.Key, "PASSWORD") {
+		return "********"
+	}
+	// URLs such as OAC_TOKEN_STORE may carry credentials
+	if u, err := url.Parse(s.Value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return s.Value
+}
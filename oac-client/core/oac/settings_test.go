@@ -0,0 +1,35 @@
+package oac
+
+import "testing"
+
+func TestSettingPrecedence(t *testing.T) {
+	profile := &Profile{Name: "prod", Env: map[string]string{"OAC_INSTANCE": "https://profile.example.com"}}
+	tests := []struct {
+		name       string
+		instance   string
+		env        string
+		envSource  string
+		profile    *Profile
+		want       string
+		wantSource string
+	}{
+		{"flag", "https://flag.example.com", "https://env.example.com", "", profile, "https://flag.example.com", "--instance"},
+		{"exported over profile", "", "https://env.example.com", "", profile, "https://env.example.com", "environment"},
+		{"profile over .env", "", "https://dotenv.example.com", ".env", profile, "https://profile.example.com", "profile prod"},
+		{".env without profile", "", "https://dotenv.example.com", ".env", nil, "https://dotenv.example.com", ".env"},
+		{"profile", "", "", "", profile, "https://profile.example.com", "profile prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OAC_INSTANCE", tt.env)
+			if tt.envSource != "" {
+				envSources["OAC_INSTANCE"] = tt.envSource
+				t.Cleanup(func() { delete(envSources, "OAC_INSTANCE") })
+			}
+			c := &OacClient{instance: tt.instance, profile: tt.profile}
+			if got := c.Setting("OAC_INSTANCE"); got.Value != tt.want || got.Source != tt.wantSource {
+				t.Errorf("Setting() = %q from %q, want %q from %q", got.Value, got.Source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
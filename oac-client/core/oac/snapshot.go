@@ -0,0 +1,342 @@
+package oac
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot is an OAC snapshot as returned by the snapshots API
+type Snapshot struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	Status      string `json:"status,omitempty"`
+	// Size of the snapshot in bytes, when reported
+	Size int64 `json:"size,omitempty"`
+}
+
+// Created parses CreatedAt, returning the zero time when it is missing or malformed
+func (s Snapshot) Created() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.CreatedAt)
+	return t
+}
+
+// WorkRequest tracks an asynchronous OAC operation
+type WorkRequest struct {
+	ID              string  `json:"id"`
+	OperationType   string  `json:"operationType,omitempty"`
+	Status          string  `json:"status"`
+	PercentComplete float64 `json:"percentComplete,omitempty"`
+	Resources       []struct {
+		Identifier string `json:"identifier"`
+		EntityType string `json:"entityType,omitempty"`
+	} `json:"resources,omitempty"`
+}
+
+// Done reports whether the work request has finished, successfully or not
+func (w WorkRequest) Done() bool {
+	switch w.Status {
+	case "SUCCEEDED", "FAILED", "CANCELED":
+		return true
+	}
+	return false
+}
+
+// ListSnapshots returns all snapshots on the instance, reading every page of the list
+func (c *OacClient) ListSnapshots() ([]Snapshot, error) {
+	pages := Paginate[Snapshot](c.context(), c, apiBase+"/snapshots")
+	var snaps []Snapshot
+	for snap := range pages.All() {
+		snaps = append(snaps, snap)
+	}
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// GetSnapshot returns a single snapshot
+func (c *OacClient) GetSnapshot(id string) (*Snapshot, error) {
+	var snap Snapshot
+	if err := c.callJSON("GET", apiBase+"/snapshots/"+id, nil, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// CreateSnapshot starts a snapshot and returns the id of the work request tracking it
+func (c *OacClient) CreateSnapshot(name, description, password string) (string, error) {
+	body := map[string]any{
+		"type":        "CREATE",
+		"name":        name,
+		"description": description,
+		"password":    password,
+	}
+
+	b, err := marshalBody(body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.exchange("POST", apiBase+"/snapshots", "application/json", b)
+	if err != nil {
+		return "", err
+	}
+
+	id := resp.Header.Get("oa-work-request-id")
+	if id == "" {
+		return "", fmt.Errorf("snapshot accepted but no work request id returned")
+	}
+	return id, nil
+}
+
+// RestoreOptions select what a snapshot restore replaces. With neither Include nor
+// Exclude set, everything in the snapshot is restored.
+type RestoreOptions struct {
+	Password string
+	// Include lists the kinds of content to restore, see SnapshotKinds; empty means all
+	Include []string
+	// Exclude lists kinds of content to leave untouched
+	Exclude []string
+}
+
+// Kinds returns the kinds of content to restore, nil meaning everything
+func (o RestoreOptions) Kinds() ([]string, error) {
+	if len(o.Include) == 0 && len(o.Exclude) == 0 {
+		return nil, nil
+	}
+	include, err := restoreKinds(o.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := restoreKinds(o.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if len(include) == 0 {
+		include = restorableKinds()
+	}
+
+	skip := map[string]bool{}
+	for _, k := range exclude {
+		skip[k] = true
+	}
+	var kinds []string
+	for _, k := range include {
+		if !skip[k] {
+			kinds = append(kinds, k)
+		}
+	}
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("nothing to restore: every kind of content is excluded")
+	}
+	return kinds, nil
+}
+
+// restorableKinds returns the kinds of content a selective restore can pick
+func restorableKinds() []string {
+	var kinds []string
+	for _, k := range SnapshotKinds {
+		if k != "other" {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// restoreKinds normalizes and validates kind names, dropping duplicates
+func restoreKinds(names []string) ([]string, error) {
+	valid := restorableKinds()
+	seen := map[string]bool{}
+	var kinds []string
+	for _, n := range names {
+		k := strings.ToLower(strings.TrimSpace(n))
+		if k == "" || seen[k] {
+			continue
+		}
+		if !slices.Contains(valid, k) {
+			return nil, fmt.Errorf("unknown content kind %q, expected one of %s", n, strings.Join(valid, ", "))
+		}
+		seen[k] = true
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
+
+// LoadKindsFile reads content kinds from a file, one or more per line separated by
+// commas or spaces; # starts a comment
+func LoadKindsFile(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var kinds []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		kinds = append(kinds, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})...)
+	}
+	return kinds, nil
+}
+
+// RestoreSnapshot starts restoring a snapshot and returns the id of the work request
+// tracking it. A selection in opts restores only the chosen kinds of content.
+func (c *OacClient) RestoreSnapshot(id string, opts RestoreOptions) (string, error) {
+	kinds, err := opts.Kinds()
+	if err != nil {
+		return "", err
+	}
+	body := map[string]any{
+		"snapshot": map[string]string{
+			"id":       id,
+			"password": opts.Password,
+		},
+		"restoreOption": "ALL",
+	}
+	if kinds != nil {
+		content := make([]string, len(kinds))
+		for i, k := range kinds {
+			content[i] = strings.ToUpper(k)
+		}
+		body["restoreOption"] = "CUSTOM"
+		body["contentTypes"] = content
+	}
+
+	b, err := marshalBody(body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.exchange("POST", apiBase+"/system/actions/restoreSnapshot", "application/json", b)
+	if err != nil {
+		return "", err
+	}
+
+	wrID := resp.Header.Get("oa-work-request-id")
+	if wrID == "" {
+		return "", fmt.Errorf("restore accepted but no work request id returned")
+	}
+	return wrID, nil
+}
+
+// DeleteSnapshot removes a snapshot from the instance
+func (c *OacClient) DeleteSnapshot(id string) error {
+	return c.callJSON("DELETE", apiBase+"/snapshots/"+id, nil, nil)
+}
+
+// DownloadSnapshot returns the BAR file of a snapshot
+func (c *OacClient) DownloadSnapshot(id string) ([]byte, error) {
+	return c.send("GET", snapshotDownloadPath(id), nil)
+}
+
+// StreamSnapshot opens the BAR file of a snapshot for streaming; the caller must close it
+// and may Verify it once read
+func (c *OacClient) StreamSnapshot(id string) (*Download, error) {
+	return c.download("GET", snapshotDownloadPath(id))
+}
+
+func snapshotDownloadPath(id string) string {
+	return apiBase + "/snapshots/" + id + "/actions/download"
+}
+
+// GetWorkRequest returns the current state of a work request
+func (c *OacClient) GetWorkRequest(id string) (*WorkRequest, error) {
+	var wr WorkRequest
+	if err := c.callJSON("GET", apiBase+"/workRequests/"+id, nil, &wr); err != nil {
+		return nil, err
+	}
+	return &wr, nil
+}
+
+// ListWorkRequests returns the recent work requests of the instance
+func (c *OacClient) ListWorkRequests() ([]WorkRequest, error) {
+	var page struct {
+		Items []WorkRequest `json:"items"`
+	}
+	if err := c.callJSON("GET", apiBase+"/workRequests", nil, &page); err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// WaitWorkRequest polls a work request until it finishes, timeout elapses or the client's
+// context is cancelled
+func (c *OacClient) WaitWorkRequest(id string, interval, timeout time.Duration) (*WorkRequest, error) {
+	return c.WatchWorkRequest(id, interval, timeout, nil)
+}
+
+// WatchWorkRequest is WaitWorkRequest calling onPoll, when not nil, with every state polled
+func (c *OacClient) WatchWorkRequest(id string, interval, timeout time.Duration, onPoll func(*WorkRequest)) (*WorkRequest, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		wr, err := c.GetWorkRequest(id)
+		if err != nil {
+			return nil, err
+		}
+		if onPoll != nil {
+			onPoll(wr)
+		}
+		if wr.Done() {
+			if wr.Status != "SUCCEEDED" {
+				return wr, fmt.Errorf("work request %s finished with status %s", id, wr.Status)
+			}
+			return wr, nil
+		}
+		if time.Now().After(deadline) {
+			return wr, &TimeoutError{
+				Op:  "work request " + id,
+				Err: fmt.Errorf("still %s after %s", wr.Status, timeout),
+			}
+		}
+		select {
+		case <-time.After(interval):
+		case <-c.context().Done():
+			return wr, c.context().Err()
+		}
+	}
+}
+
+// PruneSnapshots deletes all but the newest keep snapshots whose name starts with prefix,
+// returning the deleted snapshots. It keeps at least one and needs a prefix, so it never
+// deletes every snapshot of the instance.
+func (c *OacClient) PruneSnapshots(prefix string, keep int) ([]Snapshot, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("snapshots to keep must be at least 1, got %d", keep)
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("a snapshot name prefix is required to prune snapshots")
+	}
+	snaps, err := c.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []Snapshot
+	for _, s := range snaps {
+		if strings.HasPrefix(s.Name, prefix) {
+			matching = append(matching, s)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].Created().After(matching[j].Created())
+	})
+
+	if len(matching) <= keep {
+		return nil, nil
+	}
+
+	var deleted []Snapshot
+	for _, s := range matching[keep:] {
+		if err := c.DeleteSnapshot(s.ID); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot %s: %w", s.Name, err)
+		}
+		deleted = append(deleted, s)
+	}
+	return deleted, nil
+}
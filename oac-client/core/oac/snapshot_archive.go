@@ -0,0 +1,101 @@
+package oac
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotKinds are the kinds of content SnapshotEntry.Kind reports, in display order
+var SnapshotKinds = []string{
+	"workbooks", "analyses", "dashboards", "reports", "datasets", "dataflows",
+	"connections", "models", "users", "roles", "settings", "other",
+}
+
+// SnapshotEntry is one file stored in a snapshot (BAR) archive
+type SnapshotEntry struct {
+	Kind     string    `json:"kind"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified,omitzero"`
+}
+
+// snapshotMarkers classify archive paths by the folder or extension they contain,
+// checked in order
+var snapshotMarkers = []struct {
+	kind    string
+	markers []string
+}{
+	{"connections", []string{"connections", "connection"}},
+	{"datasets", []string{"datasets", "dataset", "datasources"}},
+	{"dataflows", []string{"dataflows", "dataflow"}},
+	{"models", []string{"semanticmodel", "rpd", "repository", "smml"}},
+	{"users", []string{"users", "user"}},
+	{"roles", []string{"roles", "approles", "applicationroles", "groups"}},
+	{"settings", []string{"settings", "systemsettings", "config"}},
+	{"workbooks", []string{"workbooks", "projects", ".dva"}},
+	{"dashboards", []string{"dashboards", "dashboard"}},
+	{"reports", []string{"reports", ".xdo", ".xdm"}},
+	{"analyses", []string{"analyses", "analysis", "catalog", "webcat"}},
+}
+
+// snapshotKind classifies an archive path
+func snapshotKind(name string) string {
+	lower := strings.ToLower(name)
+	segments := strings.Split(strings.Trim(lower, "/"), "/")
+	ext := path.Ext(lower)
+	for _, m := range snapshotMarkers {
+		for _, marker := range m.markers {
+			if strings.HasPrefix(marker, ".") {
+				if ext == marker {
+					return m.kind
+				}
+				continue
+			}
+			for _, seg := range segments[:len(segments)-1] {
+				if seg == marker {
+					return m.kind
+				}
+			}
+		}
+	}
+	return "other"
+}
+
+// InspectSnapshot lists the contents of a downloaded snapshot archive without restoring
+// it, sorted by kind and path. Encrypted archives can be listed without their password.
+func InspectSnapshot(file string) ([]SnapshotEntry, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot %s: %w", file, err)
+	}
+	defer zr.Close()
+
+	order := map[string]int{}
+	for i, k := range SnapshotKinds {
+		order[k] = i
+	}
+
+	var entries []SnapshotEntry
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		entries = append(entries, SnapshotEntry{
+			Kind:     snapshotKind(f.Name),
+			Path:     f.Name,
+			Size:     int64(f.UncompressedSize64),
+			Modified: f.Modified,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return order[entries[i].Kind] < order[entries[j].Kind]
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
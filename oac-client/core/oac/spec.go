@@ -0,0 +1,31 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSpecFile reads a YAML or JSON document and returns it as JSON
+func LoadSpecFile(file string) (json.RawMessage, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return SpecToJSON(data)
+}
+
+// SpecToJSON converts a YAML or JSON document to JSON
+func SpecToJSON(data []byte) (json.RawMessage, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML/JSON document: %w", err)
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("document cannot be represented as JSON: %w", err)
+	}
+	return b, nil
+}
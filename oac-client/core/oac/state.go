@@ -0,0 +1,473 @@
+package oac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// State declares the configuration of an instance:
+//
+//	connections:
+//	  ADW Sales: {type: oracle-adw, host: adw.example.com, username: SALES, password: "secret://ocid1..."}
+//	roles:
+//	  FinanceAuthors: {members: [{name: finance-team, type: group}]}
+//	settings:
+//	  QueryLimitsMaxRows: 250000
+//	agents:
+//	  /shared/Finance/Agents/Daily Revenue: {enabled: true, schedule: "0 6 * * *"}
+//
+// Sections left out are not managed.
+type State struct {
+	// Connections maps connection names to their definitions
+	Connections map[string]map[string]any `yaml:"connections,omitempty"`
+	// Roles maps application role names to their description and members
+	Roles map[string]Role `yaml:"roles,omitempty"`
+	// Settings maps system setting keys to their values
+	Settings map[string]any `yaml:"settings,omitempty"`
+	// Agents maps the catalog paths of agents to their schedules
+	Agents map[string]AgentState `yaml:"agents,omitempty"`
+}
+
+// AgentState is the declared schedule of an agent; unset fields are left alone
+type AgentState struct {
+	Enabled  *bool  `yaml:"enabled,omitempty"`
+	Schedule string `yaml:"schedule,omitempty"`
+}
+
+// LoadState reads a state file
+func LoadState(file string) (*State, error) {
+	s := &State{}
+	if err := loadYAML(file, s); err != nil {
+		return nil, err
+	}
+	for name, role := range s.Roles {
+		for _, m := range role.Members {
+			if m.Name == "" || m.Type == "" {
+				return nil, fmt.Errorf("invalid %s: members of role %s need name and type", file, name)
+			}
+		}
+	}
+	if s.Agents != nil {
+		agents := make(map[string]AgentState, len(s.Agents))
+		for p, a := range s.Agents {
+			agents["/"+strings.Trim(p, "/")] = a
+		}
+		s.Agents = agents
+	}
+	return s, nil
+}
+
+// StateSections are the sections of a State, in the order they are applied
+var StateSections = []string{"connections", "roles", "settings", "agents"}
+
+// ExportState reads the configuration of the instance as a State, limited to the given
+// sections (all when empty). Applying it to another instance with --plan shows the drift.
+func (c *OacClient) ExportState(sections []string) (*State, error) {
+	if len(sections) == 0 {
+		sections = StateSections
+	}
+	s := &State{}
+	for _, section := range sections {
+		switch section {
+		case "connections":
+			items, err := c.ListConnections()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list connections: %w", err)
+			}
+			s.Connections = map[string]map[string]any{}
+			for _, item := range items {
+				raw, err := c.GetConnection(item.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read connection %s: %w", item.Name, err)
+				}
+				var def map[string]any
+				if err := json.Unmarshal(raw, &def); err != nil {
+					return nil, fmt.Errorf("invalid definition of connection %s: %w", item.Name, err)
+				}
+				delete(def, "name")
+				s.Connections[item.Name] = wholeNumbers(def).(map[string]any)
+			}
+		case "roles":
+			roles, err := c.ListRoles()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list roles: %w", err)
+			}
+			s.Roles = make(map[string]Role, len(roles))
+			for _, r := range roles {
+				s.Roles[r.Name] = r
+			}
+		case "settings":
+			settings, err := c.ListSystemSettings()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list system settings: %w", err)
+			}
+			s.Settings = make(map[string]any, len(settings))
+			for _, st := range settings {
+				s.Settings[st.Key] = wholeNumbers(st.Value)
+			}
+		case "agents":
+			agents, err := c.ListAgents()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list agents: %w", err)
+			}
+			s.Agents = make(map[string]AgentState, len(agents))
+			for _, a := range agents {
+				p := a.Path
+				if p == "" {
+					p, _ = DecodeCatalogID(a.ID)
+				}
+				s.Agents[p] = AgentState{Enabled: &a.Enabled, Schedule: a.Schedule}
+			}
+		default:
+			return nil, fmt.Errorf("unknown section %q, expected %s", section, strings.Join(StateSections, ", "))
+		}
+	}
+	return s, nil
+}
+
+// wholeNumbers turns whole JSON numbers into integers, so that YAML prints 8000
+// rather than 8e+03
+func wholeNumbers(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return int64(v)
+		}
+	case map[string]any:
+		for k, e := range v {
+			v[k] = wholeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = wholeNumbers(e)
+		}
+	}
+	return v
+}
+
+// StateChange is one change needed to converge an instance to a State
+type StateChange struct {
+	// Op is "+" for a create, "~" for an update and "-" for a delete
+	Op string
+	// Kind is connection, role, setting or agent
+	Kind string
+	Name string
+	// Details lists what changes, e.g. "host: old -> new"
+	Details []string
+
+	apply func(*OacClient) error
+}
+
+func (ch StateChange) String() string {
+	s := ch.Op + " " + ch.Kind + " " + ch.Name
+	for _, d := range ch.Details {
+		s += "\n    " + d
+	}
+	return s
+}
+
+// PlanState compares a State with the instance and returns the changes needed, by kind
+// and name. With prune, connections and roles missing from a listed section are deleted,
+// built-in roles excepted; roles declared without members keep theirs.
+// Connection fields the instance does not return, such as passwords, are only compared
+// on creation; fields may reference secrets like IDCS_OAC_CLIENT_SECRET.
+func (c *OacClient) PlanState(ctx context.Context, s *State, prune bool) ([]StateChange, error) {
+	var changes []StateChange
+	if s.Connections != nil {
+		planned, err := c.planConnections(ctx, s.Connections, prune)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, planned...)
+	}
+	if s.Roles != nil {
+		planned, err := c.planRoles(s.Roles, prune)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, planned...)
+	}
+	if s.Settings != nil {
+		planned, err := c.PlanSystemSettings(s.Settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, sc := range planned {
+			detail := settingString(sc.From) + " -> " + settingString(sc.To)
+			if sc.RestartRequired {
+				detail += " (restart required)"
+			}
+			key, value := sc.Key, sc.To
+			changes = append(changes, StateChange{Op: "~", Kind: "setting", Name: key, Details: []string{detail},
+				apply: func(c *OacClient) error { return c.SetSystemSetting(key, value) }})
+		}
+	}
+	if s.Agents != nil {
+		planned, err := c.planAgents(s.Agents)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, planned...)
+	}
+	return changes, nil
+}
+
+// ApplyState performs planned changes, stopping at the first failure
+func (c *OacClient) ApplyState(changes []StateChange) error {
+	verbs := map[string]string{"+": "create", "~": "update", "-": "delete"}
+	for _, ch := range changes {
+		if err := ch.apply(c); err != nil {
+			return fmt.Errorf("failed to %s %s %s: %w", verbs[ch.Op], ch.Kind, ch.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *OacClient) planConnections(ctx context.Context, desired map[string]map[string]any, prune bool) ([]StateChange, error) {
+	items, err := c.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	live := make(map[string]CatalogItem, len(items))
+	for _, item := range items {
+		live[item.Name] = item
+	}
+
+	var changes []StateChange
+	for _, name := range sortedKeys(desired) {
+		def := desired[name]
+		if def == nil {
+			def = map[string]any{}
+		}
+		if _, ok := def["name"]; !ok {
+			def["name"] = name
+		}
+		resolved, err := c.resolveSecretFields(ctx, def)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", name, err)
+		}
+		spec, err := json.Marshal(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s cannot be represented as JSON: %w", name, err)
+		}
+
+		item, ok := live[name]
+		if !ok {
+			changes = append(changes, StateChange{Op: "+", Kind: "connection", Name: name,
+				apply: func(c *OacClient) error { _, err := c.CreateConnection(spec); return err }})
+			continue
+		}
+		raw, err := c.GetConnection(item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read connection %s: %w", name, err)
+		}
+		var current map[string]any
+		if err := json.Unmarshal(raw, &current); err != nil {
+			return nil, fmt.Errorf("invalid definition of connection %s: %w", name, err)
+		}
+		if details := diffFields("", def, current); len(details) > 0 {
+			id := item.ID
+			changes = append(changes, StateChange{Op: "~", Kind: "connection", Name: name, Details: details,
+				apply: func(c *OacClient) error { _, err := c.UpdateConnection(id, spec); return err }})
+		}
+	}
+	if prune {
+		for _, name := range sortedKeys(live) {
+			if _, ok := desired[name]; !ok {
+				id := live[name].ID
+				changes = append(changes, StateChange{Op: "-", Kind: "connection", Name: name,
+					apply: func(c *OacClient) error { return c.DeleteConnection(id) }})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// resolveSecretFields returns a copy of def with secret references replaced by their values
+func (c *OacClient) resolveSecretFields(ctx context.Context, def map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(def))
+	for k, v := range def {
+		switch v := v.(type) {
+		case string:
+			plain, err := c.resolveSecret(ctx, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", k, err)
+			}
+			out[k] = plain
+		case map[string]any:
+			nested, err := c.resolveSecretFields(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = nested
+		default:
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// diffFields lists the fields of want whose value differs in have, as "path: old -> new".
+// Fields missing from have and secret references are write-only and skipped.
+func diffFields(prefix string, want, have map[string]any) []string {
+	var details []string
+	for _, k := range sortedKeys(want) {
+		hv, ok := have[k]
+		if !ok {
+			continue
+		}
+		switch wv := want[k].(type) {
+		case map[string]any:
+			if hm, ok := hv.(map[string]any); ok {
+				details = append(details, diffFields(prefix+k+".", wv, hm)...)
+				continue
+			}
+		case string:
+			if strings.HasPrefix(wv, ociSecretScheme) || strings.HasPrefix(wv, vaultSecretScheme) {
+				continue
+			}
+		}
+		if settingString(want[k]) != settingString(hv) {
+			details = append(details, fmt.Sprintf("%s%s: %s -> %s", prefix, k, settingString(hv), settingString(want[k])))
+		}
+	}
+	return details
+}
+
+func (c *OacClient) planRoles(desired map[string]Role, prune bool) ([]StateChange, error) {
+	roles, err := c.ListRoles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	live := make(map[string]Role, len(roles))
+	for _, r := range roles {
+		live[r.Name] = r
+	}
+
+	var changes []StateChange
+	for _, name := range sortedKeys(desired) {
+		want := desired[name]
+		want.Name = name
+		have, ok := live[name]
+		if want.Description == "" {
+			want.Description = have.Description
+		}
+		if !want.membersSet {
+			want.Members = have.Members
+		}
+		details := diffMembers(have.Members, want.Members)
+		switch {
+		case !ok:
+			changes = append(changes, StateChange{Op: "+", Kind: "role", Name: name, Details: details,
+				apply: func(c *OacClient) error { return c.CreateRole(want) }})
+			continue
+		case want.Description != have.Description:
+			details = append([]string{fmt.Sprintf("description: %s -> %s", have.Description, want.Description)}, details...)
+		}
+		if len(details) > 0 {
+			changes = append(changes, StateChange{Op: "~", Kind: "role", Name: name, Details: details,
+				apply: func(c *OacClient) error { return c.UpdateRole(want) }})
+		}
+	}
+	if prune {
+		for _, name := range sortedKeys(live) {
+			if _, ok := desired[name]; !ok && !live[name].Builtin() {
+				changes = append(changes, StateChange{Op: "-", Kind: "role", Name: name,
+					apply: func(c *OacClient) error { return c.DeleteRole(name) }})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// diffMembers lists the members added to and removed from a role
+func diffMembers(current, desired []RoleMember) []string {
+	have := map[string]bool{}
+	for _, m := range current {
+		have[m.key()] = true
+	}
+	want := map[string]bool{}
+	var details []string
+	for _, m := range desired {
+		want[m.key()] = true
+		if !have[m.key()] {
+			details = append(details, "+ member "+m.String())
+		}
+	}
+	for _, m := range current {
+		if !want[m.key()] {
+			details = append(details, "- member "+m.String())
+		}
+	}
+	sort.Slice(details, func(i, j int) bool { return details[i][2:] < details[j][2:] })
+	return details
+}
+
+func (c *OacClient) planAgents(desired map[string]AgentState) ([]StateChange, error) {
+	agents, err := c.ListAgents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	live := make(map[string]Agent, len(agents))
+	for _, a := range agents {
+		p := a.Path
+		if p == "" {
+			p, _ = DecodeCatalogID(a.ID)
+		}
+		live[p] = a
+	}
+
+	var unknown []string
+	var changes []StateChange
+	for _, p := range sortedKeys(desired) {
+		want := desired[p]
+		a, ok := live[p]
+		if !ok {
+			unknown = append(unknown, p)
+			continue
+		}
+		var details []string
+		enable := want.Enabled != nil && *want.Enabled != a.Enabled
+		if enable {
+			details = append(details, fmt.Sprintf("enabled: %t -> %t", a.Enabled, *want.Enabled))
+		}
+		schedule := want.Schedule != "" && want.Schedule != a.Schedule
+		if schedule {
+			details = append(details, fmt.Sprintf("schedule: %s -> %s", a.Schedule, want.Schedule))
+		}
+		if len(details) == 0 {
+			continue
+		}
+		path := p
+		changes = append(changes, StateChange{Op: "~", Kind: "agent", Name: p, Details: details,
+			apply: func(c *OacClient) error {
+				if schedule {
+					if err := c.SetAgentSchedule(path, want.Schedule); err != nil {
+						return err
+					}
+				}
+				if enable {
+					return c.SetAgentEnabled(path, *want.Enabled)
+				}
+				return nil
+			}})
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("agents not found: %s", strings.Join(unknown, ", "))
+	}
+	return changes, nil
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
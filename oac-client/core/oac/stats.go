@@ -0,0 +1,245 @@
+package oac
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestStats describes one HTTP exchange with OAC. Phases that did not happen,
+// such as DNS and TLS on a reused connection, are zero.
+type RequestStats struct {
+	Method string
+	Path   string
+	Status int
+	// Err is set when the request failed before a response was received
+	Err error
+
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from sending the request to the first response byte
+	TTFB time.Duration
+	// Total includes reading the response body
+	Total time.Duration
+
+	RequestBytes  int64
+	ResponseBytes int64
+	ReusedConn    bool
+	// Retry is set when the exchange repeats an earlier one, e.g. after a 401
+	Retry bool
+}
+
+// StatsFunc receives the stats of each exchange once its response body is closed
+type StatsFunc func(RequestStats)
+
+// WithStats calls fn with the timing and size of every API request. fn may be called
+// from several goroutines at once.
+func WithStats(fn StatsFunc) Option {
+	return func(c *OacClient) {
+		c.stats = fn
+	}
+}
+
+type retryKey struct{}
+
+// markRetry flags req as a repetition of an earlier exchange for the stats
+func markRetry(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), retryKey{}, true))
+}
+
+// tracedRequest attaches an httptrace to req filling st
+func tracedRequest(req *http.Request, st *RequestStats) *http.Request {
+	var dnsStart, connectStart, tlsStart, wrote time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { st.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { st.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { st.TLS = time.Since(tlsStart) },
+		GotConn:           func(info httptrace.GotConnInfo) { st.ReusedConn = info.Reused },
+		WroteRequest:      func(httptrace.WroteRequestInfo) { wrote = time.Now() },
+		GotFirstResponseByte: func() {
+			if !wrote.IsZero() {
+				st.TTFB = time.Since(wrote)
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	*cr.n += int64(n)
+	return n, err
+}
+
+// statsBody reports the stats when the response body is closed
+type statsBody struct {
+	countingReader
+	body io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *statsBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// doWithStats sends req through send, reporting its stats to c.stats
+func (c *OacClient) doWithStats(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	st := &RequestStats{Method: req.Method, Path: req.URL.Path}
+	st.Retry, _ = req.Context().Value(retryKey{}).(bool)
+	req = tracedRequest(req, st)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = readCloser{countingReader{req.Body, &st.RequestBytes}, req.Body}
+	}
+
+	start := time.Now()
+	resp, err := send(req)
+	if err != nil {
+		st.Err = err
+		st.Total = time.Since(start)
+		c.stats(*st)
+		return nil, err
+	}
+
+	st.Status = resp.StatusCode
+	resp.Body = &statsBody{
+		countingReader: countingReader{resp.Body, &st.ResponseBytes},
+		body:           resp.Body,
+		done: func() {
+			st.Total = time.Since(start)
+			c.stats(*st)
+		},
+	}
+	return resp, nil
+}
+
+// Metrics aggregates RequestStats and serves them in the Prometheus text format, for
+// services embedding the client:
+//
+//	m := oac.NewMetrics()
+//	client, err := oac.NewOacClient(oac.WithStats(m.Observe))
+//	http.Handle("/metrics", m)
+type Metrics struct {
+	mu       sync.Mutex
+	requests map[metricKey]*metricValue
+}
+
+type metricKey struct {
+	method string
+	status string
+}
+
+type metricValue struct {
+	count         int64
+	retries       int64
+	seconds       float64
+	requestBytes  int64
+	responseBytes int64
+	buckets       []int64
+}
+
+// metricBuckets are the upper bounds, in seconds, of the request duration histogram
+var metricBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// NewMetrics returns an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{requests: map[metricKey]*metricValue{}}
+}
+
+// Observe adds the stats of one exchange; it is a StatsFunc
+func (m *Metrics) Observe(st RequestStats) {
+	status := "error"
+	if st.Err == nil {
+		status = fmt.Sprint(st.Status)
+	}
+	key := metricKey{method: st.Method, status: status}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v := m.requests[key]
+	if v == nil {
+		v = &metricValue{buckets: make([]int64, len(metricBuckets))}
+		m.requests[key] = v
+	}
+	v.count++
+	if st.Retry {
+		v.retries++
+	}
+	v.seconds += st.Total.Seconds()
+	v.requestBytes += st.RequestBytes
+	v.responseBytes += st.ResponseBytes
+	for i, le := range metricBuckets {
+		if st.Total.Seconds() <= le {
+			v.buckets[i]++
+		}
+	}
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]metricKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	var b strings.Builder
+	counter := func(name, help string, value func(*metricValue) int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s{method=%q,status=%q} %d\n", name, k.method, k.status, value(m.requests[k]))
+		}
+	}
+	counter("oac_client_requests_total", "OAC API requests.", func(v *metricValue) int64 { return v.count })
+	counter("oac_client_retries_total", "OAC API requests repeated after a failure.", func(v *metricValue) int64 { return v.retries })
+	counter("oac_client_request_bytes_total", "Request body bytes sent to OAC.", func(v *metricValue) int64 { return v.requestBytes })
+	counter("oac_client_response_bytes_total", "Response body bytes read from OAC.", func(v *metricValue) int64 { return v.responseBytes })
+
+	const hist = "oac_client_request_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Duration of OAC API requests including the response body.\n# TYPE %s histogram\n", hist, hist)
+	for _, k := range keys {
+		v := m.requests[k]
+		for i, le := range metricBuckets {
+			fmt.Fprintf(&b, "%s_bucket{method=%q,status=%q,le=\"%g\"} %d\n", hist, k.method, k.status, le, v.buckets[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{method=%q,status=%q,le=\"+Inf\"} %d\n", hist, k.method, k.status, v.count)
+		fmt.Fprintf(&b, "%s_sum{method=%q,status=%q} %g\n", hist, k.method, k.status, v.seconds)
+		fmt.Fprintf(&b, "%s_count{method=%q,status=%q} %d\n", hist, k.method, k.status, v.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics for a Prometheus scrape
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
@@ -0,0 +1,150 @@
+package oac
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// systemSettingsPath is the endpoint for the system settings of the instance
+const systemSettingsPath = apiBase + "/system/settings"
+
+// SystemSetting is a system setting of the instance, as shown on the System Settings page
+type SystemSetting struct {
+	Key             string `json:"key"`
+	Value           any    `json:"value"`
+	Description     string `json:"description,omitempty"`
+	RestartRequired bool   `json:"restartRequired,omitempty"`
+}
+
+// ValueString formats Value for display and comparison
+func (s SystemSetting) ValueString() string {
+	return settingString(s.Value)
+}
+
+// ListSystemSettings returns the system settings of the instance, sorted by key
+func (c *OacClient) ListSystemSettings() ([]SystemSetting, error) {
+	var page struct {
+		Items []SystemSetting `json:"items"`
+	}
+	if err := c.callJSON("GET", systemSettingsPath, nil, &page); err != nil {
+		return nil, err
+	}
+	sort.Slice(page.Items, func(i, j int) bool { return page.Items[i].Key < page.Items[j].Key })
+	return page.Items, nil
+}
+
+// GetSystemSetting returns a single system setting
+func (c *OacClient) GetSystemSetting(key string) (*SystemSetting, error) {
+	var s SystemSetting
+	if err := c.callJSON("GET", systemSettingsPath+"/"+url.PathEscape(key), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetSystemSetting changes the value of a system setting
+func (c *OacClient) SetSystemSetting(key string, value any) error {
+	return c.callJSON("PUT", systemSettingsPath+"/"+url.PathEscape(key), map[string]any{"value": value}, nil)
+}
+
+// ParseSettingValue converts a value given on the command line to the type of the
+// current value of a setting: a bool, a number or a string
+func ParseSettingValue(current any, raw string) (any, error) {
+	switch current.(type) {
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", raw)
+		}
+		return b, nil
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", raw)
+		}
+		return f, nil
+	}
+	return raw, nil
+}
+
+// settingString formats a setting value, so that values read from YAML and JSON compare
+// equal: 10 and 10.0, true and "true"
+func settingString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any, map[string]any:
+		b, _ := marshalBody(v)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// LoadSystemSettingsFile reads a YAML or JSON file mapping setting keys to the values
+// they should have
+func LoadSystemSettingsFile(file string) (map[string]any, error) {
+	desired := map[string]any{}
+	if err := loadYAML(file, &desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// SettingChange is a system setting whose value differs from the desired one
+type SettingChange struct {
+	Key             string
+	From            any
+	To              any
+	RestartRequired bool
+}
+
+func (ch SettingChange) String() string {
+	return fmt.Sprintf("~ %s: %s -> %s", ch.Key, settingString(ch.From), settingString(ch.To))
+}
+
+// PlanSystemSettings compares desired values with the settings of the instance and
+// returns the changes needed, sorted by key. Keys unknown to the instance are an error.
+func (c *OacClient) PlanSystemSettings(desired map[string]any) ([]SettingChange, error) {
+	current, err := c.ListSystemSettings()
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]SystemSetting, len(current))
+	for _, s := range current {
+		have[s.Key] = s
+	}
+
+	var unknown []string
+	var changes []SettingChange
+	for key, value := range desired {
+		s, ok := have[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if s.ValueString() != settingString(value) {
+			changes = append(changes, SettingChange{Key: key, From: s.Value, To: value, RestartRequired: s.RestartRequired})
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown system settings: %s", strings.Join(unknown, ", "))
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+// ApplySystemSettings performs planned setting changes, stopping at the first failure
+func (c *OacClient) ApplySystemSettings(changes []SettingChange) error {
+	for _, ch := range changes {
+		if err := c.SetSystemSetting(ch.Key, ch.To); err != nil {
+			return fmt.Errorf("failed to set %s: %w", ch.Key, err)
+		}
+	}
+	return nil
+}
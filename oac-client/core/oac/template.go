@@ -0,0 +1,161 @@
+package oac
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateVars holds values available to request body templates
+type TemplateVars map[string]any
+
+// LoadVarFile reads template variables from a YAML or JSON file
+func LoadVarFile(file string) (TemplateVars, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars file: %w", err)
+	}
+
+	vars := TemplateVars{}
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("invalid vars file %s: %w", file, err)
+	}
+	return vars, nil
+}
+
+// ParseVars parses key=value pairs as given to --var
+func ParseVars(pairs []string) (TemplateVars, error) {
+	vars := TemplateVars{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// Merge returns a copy of vars overlaid with each of others in order
+func (vars TemplateVars) Merge(others ...TemplateVars) TemplateVars {
+	merged := TemplateVars{}
+	for k, v := range vars {
+		merged[k] = v
+	}
+	for _, o := range others {
+		for k, v := range o {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// RenderTemplate executes body as a Go template. Variables are referenced as
+// {{ .name }}; environment variables through {{ env "NAME" }}. Referencing an
+// undefined variable is an error, unless it is passed to default as in
+// {{ .name | default "nightly" }}.
+func RenderTemplate(body []byte, vars TemplateVars) ([]byte, error) {
+	return renderTemplate("body", body, vars)
+}
+
+// ExpandPath renders the variables of a request path such as
+// /api/{{.version}}/catalog/{{.folder}} like RenderTemplate; paths without {{ are
+// returned unchanged
+func ExpandPath(path string, vars TemplateVars) (string, error) {
+	if !strings.Contains(path, "{{") {
+		return path, nil
+	}
+	out, err := renderTemplate("path", []byte(path), vars)
+	return string(out), err
+}
+
+func renderTemplate(kind string, text []byte, vars TemplateVars) ([]byte, error) {
+	tmpl, err := template.New(kind).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"env": os.Getenv,
+			"default": func(def, value any) any {
+				if value == nil || value == "" {
+					return def
+				}
+				return value
+			},
+		}).
+		Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", kind, err)
+	}
+
+	// variables given to default may be missing, which missingkey=error would refuse
+	data := map[string]any(vars.Merge())
+	optional := map[string]bool{}
+	defaultedVars(tmpl.Tree.Root, optional)
+	for name := range optional {
+		if _, ok := data[name]; !ok {
+			data[name] = nil
+		}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s template: %w", kind, err)
+	}
+	return out.Bytes(), nil
+}
+
+// defaultedVars adds the variables of node that are passed to default to names
+func defaultedVars(node parse.Node, names map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			defaultedVars(child, names)
+		}
+	case *parse.ActionNode:
+		defaultedPipeVars(n.Pipe, names)
+	case *parse.TemplateNode:
+		defaultedPipeVars(n.Pipe, names)
+	case *parse.IfNode:
+		defaultedBranchVars(&n.BranchNode, names)
+	case *parse.RangeNode:
+		defaultedBranchVars(&n.BranchNode, names)
+	case *parse.WithNode:
+		defaultedBranchVars(&n.BranchNode, names)
+	}
+}
+
+func defaultedBranchVars(n *parse.BranchNode, names map[string]bool) {
+	defaultedPipeVars(n.Pipe, names)
+	defaultedVars(n.List, names)
+	defaultedVars(n.ElseList, names)
+}
+
+// defaultedPipeVars adds the fields of pipe to names when its last command is default,
+// as in {{ .name | default "x" }} and {{ default "x" .name }}
+func defaultedPipeVars(pipe *parse.PipeNode, names map[string]bool) {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return
+	}
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	ident, ok := last.Args[0].(*parse.IdentifierNode)
+	isDefault := ok && ident.Ident == "default"
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				if isDefault {
+					names[a.Ident[0]] = true
+				}
+			case *parse.PipeNode:
+				defaultedPipeVars(a, names)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package oac
+
+import "testing"
+
+func TestRenderTemplate(t *testing.T) {
+	t.Setenv("OACTEST_PASSWORD", "hunter2")
+	tests := []struct {
+		name    string
+		body    string
+		vars    TemplateVars
+		want    string
+		wantErr bool
+	}{
+		{"variable", `{"name":"{{ .name }}"}`, TemplateVars{"name": "nightly"}, `{"name":"nightly"}`, false},
+		{"env only", `{"password":"{{ env "OACTEST_PASSWORD" }}"}`, nil, `{"password":"hunter2"}`, false},
+		{"undefined", `{"name":"{{ .name }}"}`, nil, "", true},
+		{"default piped", `{"name":"{{ .name | default "adhoc" }}"}`, nil, `{"name":"adhoc"}`, false},
+		{"default called", `{"name":"{{ default "adhoc" .name }}"}`, nil, `{"name":"adhoc"}`, false},
+		{"default unused", `{"name":"{{ .name | default "adhoc" }}"}`, TemplateVars{"name": "nightly"}, `{"name":"nightly"}`, false},
+		{"default in if", `{{ if true }}{{ .n | default 3 }}{{ end }}`, nil, `3`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate([]byte(tt.body), tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RenderTemplate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
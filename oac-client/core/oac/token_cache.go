@@ -0,0 +1,223 @@
+package oac
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+var cacheDir = CacheDir()
+
+// tokenLockTimeout is how long a token refresh waits for another process refreshing the
+// same cache before going ahead on its own
+const tokenLockTimeout = 30 * time.Second
+
+// errCacheNotEncrypted reports a cache file that is not a sealed envelope
+var errCacheNotEncrypted = errors.New("token cache is not encrypted")
+
+// tokenKey names the cache entry of the client, one per profile and impersonated user
+func (c *OacClient) tokenKey() string {
+	if name := c.ProfileName(); name != "" {
+		return "oac_token_" + name + c.actAsSuffix()
+	}
+	return "oac_token" + c.actAsSuffix()
+}
+
+// encryptedCache is the on-disk envelope of the token cache
+type encryptedCache struct {
+	Version int    `json:"v"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// cacheKey derives the AES-256 key protecting the token cache. OAC_CACHE_KEY wins when set,
+// otherwise the key is bound to this machine and user so a copied file is useless elsewhere.
+func cacheKey() []byte {
+	if key := os.Getenv("OAC_CACHE_KEY"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return sum[:]
+	}
+
+	machineID, err := os.ReadFile("/etc/machine-id")
+	if err != nil {
+		machineID, _ = os.ReadFile("/var/lib/dbus/machine-id")
+	}
+	host, _ := os.Hostname()
+
+	h := sha256.New()
+	h.Write([]byte("oac-client token cache v1\x00"))
+	h.Write(machineID)
+	h.Write([]byte(host))
+	h.Write([]byte(strconv.Itoa(os.Getuid())))
+	h.Write([]byte(homeDir()))
+	return h.Sum(nil)
+}
+
+// sealCache encrypts plaintext with AES-GCM
+func sealCache(plaintext []byte) ([]byte, error) {
+	gcm, err := cacheCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedCache{Version: 1, Nonce: nonce, Data: gcm.Seal(nil, nonce, plaintext, nil)})
+}
+
+// openCache decrypts a sealed cache file
+func openCache(file []byte) ([]byte, error) {
+	var env encryptedCache
+	if err := json.Unmarshal(file, &env); err != nil || env.Version != 1 {
+		return nil, errCacheNotEncrypted
+	}
+	gcm, err := cacheCipher()
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, env.Nonce, env.Data, nil)
+}
+
+func cacheCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cacheKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// saveTokenToCache caches the token in the client's store, encrypted
+func (oacClient *OacClient) saveTokenToCache() {
+	if len(oacClient.scopeOverride) > 0 {
+		return
+	}
+	data := map[string]any{
+		"access_token": oacClient.AccessToken,
+		"expires_at":   oacClient.TokenExpiry.Unix(),
+	}
+	if oacClient.RefreshToken != "" {
+		data["refresh_token"] = oacClient.RefreshToken
+	}
+	if oacClient.actor != "" {
+		data["actor"] = oacClient.actor
+	}
+	b, _ := json.Marshal(data)
+
+	sealed, err := sealCache(b)
+	if err != nil {
+		return
+	}
+	// refresh tokens outlive access tokens, so entries holding one are kept
+	var ttl time.Duration
+	if oacClient.RefreshToken == "" {
+		ttl = time.Until(oacClient.TokenExpiry) + time.Minute
+	}
+	if err := oacClient.tokens.Save(oacClient.context(), oacClient.tokenKey(), sealed, ttl); err != nil {
+		slog.Warn("failed to write token cache", "error", err)
+	}
+}
+
+// lockTokenCache takes the lock guarding refreshes of the token cache when the store
+// has one, so processes sharing a cache, such as cron jobs starting together, obtain one
+// token between them. When the lock cannot be taken in time the refresh goes ahead
+// unlocked; the returned function releases the lock.
+func (oacClient *OacClient) lockTokenCache(ctx context.Context) func() {
+	locker, ok := oacClient.tokens.(TokenLocker)
+	if !ok || len(oacClient.scopeOverride) > 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, tokenLockTimeout)
+	defer cancel()
+	unlock, err := locker.Lock(ctx, oacClient.tokenKey())
+	if err != nil {
+		slog.Warn("refreshing the token without the cache lock", "key", oacClient.tokenKey(), "error", err)
+		return func() {}
+	}
+	return unlock
+}
+
+// loadTokenFromCache loads the cached token if present. Entries that fail to decrypt
+// are ignored; corrupt ones are removed so the next token replaces them.
+func (oacClient *OacClient) loadTokenFromCache() {
+	if len(oacClient.scopeOverride) > 0 {
+		return
+	}
+	key := oacClient.tokenKey()
+	sealed, err := oacClient.tokens.Load(oacClient.context(), key)
+	if err != nil {
+		slog.Warn("failed to read token cache", "key", key, "error", err)
+		return
+	}
+	if sealed == nil {
+		return
+	}
+
+	file, err := openCache(sealed)
+	if errors.Is(err, errCacheNotEncrypted) {
+		oacClient.discardTokenCache(key)
+		return
+	}
+	if err != nil {
+		// sealed with another key, e.g. a changed OAC_CACHE_KEY
+		slog.Debug("ignoring token cache that fails to decrypt", "key", key)
+		return
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(file, &data); err != nil {
+		oacClient.discardTokenCache(key)
+		return
+	}
+
+	// refresh tokens outlive access tokens, keep them even when the access token expired
+	if refresh, ok := data["refresh_token"].(string); ok {
+		oacClient.RefreshToken = refresh
+	}
+	if actor, ok := data["actor"].(string); ok {
+		oacClient.actor = actor
+	}
+
+	token, tokenError := data["access_token"].(string)
+	exp, expError := data["expires_at"].(float64)
+	if !tokenError || !expError || token == oacClient.rejectedToken {
+		return
+	}
+
+	oacClient.AccessToken = token
+	oacClient.TokenExpiry = time.Unix(int64(exp), 0)
+	if time.Now().After(oacClient.TokenExpiry) {
+		oacClient.AccessToken = ""
+	}
+}
+
+// discardTokenCache removes an unreadable token cache entry
+func (oacClient *OacClient) discardTokenCache(key string) {
+	slog.Warn("removing corrupt token cache", "key", key)
+	_ = oacClient.tokens.Delete(oacClient.context(), key)
+}
+
+// tokenStoreFromEnv selects the token store of OAC_TOKEN_STORE unless WithTokenStore set one
+func (c *OacClient) tokenStoreFromEnv() error {
+	if c.tokens != nil {
+		return nil
+	}
+	spec := c.setting("OAC_TOKEN_STORE")
+	store, err := NewTokenStore(spec)
+	if err != nil {
+		return err
+	}
+	if _, ok := store.(*RedisTokenStore); ok && os.Getenv("OAC_CACHE_KEY") == "" {
+		slog.Warn("tokens in Redis are sealed with a key bound to this host; set OAC_CACHE_KEY to share them")
+	}
+	c.tokens = store
+	return nil
+}
@@ -0,0 +1,130 @@
+package oac
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenClaims are the interesting claims of an IDCS access token
+type TokenClaims struct {
+	Subject     string `json:"subject"`
+	DisplayName string `json:"displayName,omitempty"`
+	ClientID    string `json:"clientId,omitempty"`
+	Issuer      string `json:"issuer,omitempty"`
+	Tenant      string `json:"tenant,omitempty"`
+	// Actor is the identity acting on behalf of Subject in an exchanged token
+	Actor     string    `json:"actor,omitempty"`
+	Audience  []string  `json:"audience,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// DecodeTokenClaims reads the claims of a JWT access token without verifying its signature
+func DecodeTokenClaims(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	var raw struct {
+		Sub         string `json:"sub"`
+		DisplayName string `json:"user_displayname"`
+		ClientID    string `json:"client_id"`
+		Iss         string `json:"iss"`
+		Tenant      string `json:"tenant"`
+		Act         struct {
+			Sub string `json:"sub"`
+		} `json:"act"`
+		Aud   json.RawMessage `json:"aud"`
+		Scope json.RawMessage `json:"scope"`
+		Iat   int64           `json:"iat"`
+		Exp   int64           `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	return &TokenClaims{
+		Subject:     raw.Sub,
+		DisplayName: raw.DisplayName,
+		ClientID:    raw.ClientID,
+		Issuer:      raw.Iss,
+		Tenant:      raw.Tenant,
+		Actor:       raw.Act.Sub,
+		Audience:    stringOrList(raw.Aud),
+		Scopes:      stringOrList(raw.Scope),
+		IssuedAt:    time.Unix(raw.Iat, 0),
+		ExpiresAt:   time.Unix(raw.Exp, 0),
+	}, nil
+}
+
+// stringOrList decodes a claim that may be a space separated string or a list of strings
+func stringOrList(raw json.RawMessage) []string {
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.Fields(s)
+	}
+	return nil
+}
+
+// Login discards any cached tokens and authenticates again
+func (c *OacClient) Login() error {
+	c.tokenMu.Lock()
+	c.AccessToken = ""
+	c.RefreshToken = ""
+	// the cached tokens would be loaded again instead of logging in
+	err := c.tokens.Delete(c.context(), c.tokenKey())
+	c.tokenMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to remove token cache: %w", err)
+	}
+	if _, err := c.GetToken(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Refresh replaces the access token, using the refresh token when one is cached
+func (c *OacClient) Refresh() error {
+	c.tokenMu.Lock()
+	c.rejectedToken = c.AccessToken
+	c.AccessToken = ""
+	c.tokenMu.Unlock()
+	if _, err := c.GetToken(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Logout clears the tokens held by the client and removes the token cache
+func (c *OacClient) Logout() error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.AccessToken = ""
+	c.RefreshToken = ""
+	c.TokenExpiry = time.Time{}
+	if err := c.tokens.Delete(c.context(), c.tokenKey()); err != nil {
+		return fmt.Errorf("failed to remove token cache: %w", err)
+	}
+	return nil
+}
+
+// CachedToken returns the access token currently held without contacting IDCS
+func (c *OacClient) CachedToken() (string, time.Time) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.AccessToken, c.TokenExpiry
+}
@@ -0,0 +1,127 @@
+package oac
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenExchangeGrant is the RFC 8693 token exchange grant type
+const tokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// WithActAs makes the client act on behalf of user: the token obtained with the
+// configured grant is exchanged for a token of user (RFC 8693 token exchange), and the
+// acting identity is logged and recorded in the history
+func WithActAs(user string) Option {
+	return func(c *OacClient) {
+		c.actAs = user
+	}
+}
+
+// ActAs returns the user the client acts on behalf of, or ""
+func (c *OacClient) ActAs() string {
+	return c.actAs
+}
+
+// Actor returns the identity acting on behalf of ActAs, once a token was exchanged
+func (c *OacClient) Actor() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.actor
+}
+
+// actAsSuffix keeps exchanged tokens apart from the acting identity's own in the cache
+func (c *OacClient) actAsSuffix() string {
+	if c.actAs == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(c.actAs))
+	return "_as_" + hex.EncodeToString(sum[:6])
+}
+
+// acceptToken stores a newly obtained token, first exchanging it for the ActAs user's
+func (c *OacClient) acceptToken(ctx context.Context, cfg *oauth2.Config, token *oauth2.Token) error {
+	if c.actAs == "" {
+		return c.setToken(token)
+	}
+
+	actor := cfg.ClientID
+	if claims, err := DecodeTokenClaims(token.AccessToken); err == nil && claims.Subject != "" {
+		actor = claims.Subject
+	}
+	exchanged, err := c.exchangeToken(ctx, cfg, token.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to obtain a token for %s: %w", c.actAs, err)
+	}
+	slog.Info("acting on behalf of user", "user", c.actAs, "actor", actor)
+
+	// the acting identity's refresh token obtains the next token to exchange
+	exchanged.RefreshToken = token.RefreshToken
+	c.actor = actor
+	return c.setToken(exchanged)
+}
+
+// exchangeToken trades subjectToken for a token of the ActAs user at the token endpoint
+func (c *OacClient) exchangeToken(ctx context.Context, cfg *oauth2.Config, subjectToken string) (*oauth2.Token, error) {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrant},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"requested_subject":  {c.actAs},
+	}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	if cfg.ClientSecret == "" {
+		form.Set("client_id", cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.Endpoint.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &out)
+	if resp.StatusCode != http.StatusOK || out.AccessToken == "" {
+		if out.Error != "" {
+			return nil, fmt.Errorf("token exchange refused: %s %s", out.Error, out.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token exchange failed with status %d", resp.StatusCode)
+	}
+
+	token := &oauth2.Token{AccessToken: out.AccessToken, TokenType: "Bearer"}
+	if out.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
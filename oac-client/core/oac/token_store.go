@@ -0,0 +1,281 @@
+package oac
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenStore keeps the token cache of clients. Entries are sealed with the cache key
+// before they reach the store; keys name a profile and impersonated user. Clients of
+// several processes or hosts share tokens by sharing a store, which then needs the same
+// OAC_CACHE_KEY everywhere.
+type TokenStore interface {
+	// Load returns the entry of key, nil when there is none
+	Load(ctx context.Context, key string) ([]byte, error)
+	// Save replaces the entry of key; ttl is how long it is useful, 0 for no limit
+	Save(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// Delete removes the entry of key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// TokenLocker is implemented by stores that can serialize token refreshes, so clients
+// sharing the store obtain one token between them
+type TokenLocker interface {
+	// Lock waits until it holds the lock of key or ctx is done
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// WithTokenStore keeps the client's tokens in store instead of the store selected by
+// OAC_TOKEN_STORE
+func WithTokenStore(store TokenStore) Option {
+	return func(c *OacClient) {
+		c.tokens = store
+	}
+}
+
+// NewTokenStore returns the store described by spec: "file" (the default) for files in
+// the cache directory, "memory" for this process only, or a redis:// or rediss:// URL
+func NewTokenStore(spec string) (TokenStore, error) {
+	switch {
+	case spec == "" || spec == "file":
+		return NewFileTokenStore(cacheDir), nil
+	case spec == "memory":
+		return NewMemoryTokenStore(), nil
+	case strings.HasPrefix(spec, "redis://"), strings.HasPrefix(spec, "rediss://"):
+		return NewRedisTokenStore(spec)
+	}
+	return nil, fmt.Errorf("invalid token store %q: expected file, memory or a redis:// URL", spec)
+}
+
+// MemoryTokenStore keeps tokens in memory, shared by the clients of a process it is
+// given to
+type MemoryTokenStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryToken
+	locks   map[string]chan struct{}
+}
+
+type memoryToken struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{entries: map[string]memoryToken{}, locks: map[string]chan struct{}{}}
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || (!e.expires.IsZero() && time.Now().After(e.expires)) {
+		return nil, nil
+	}
+	return e.data, nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := memoryToken{data: data}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	s.mu.Lock()
+	lock, ok := s.locks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		s.locks[key] = lock
+	}
+	s.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// FileTokenStore keeps each token in a file of a directory, readable only by the owner.
+// Files are replaced atomically and refreshes are serialized with lock files, so
+// processes of the same user can share them.
+type FileTokenStore struct {
+	Dir string
+}
+
+// NewFileTokenStore returns a FileTokenStore for dir
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{Dir: dir}
+}
+
+func (s *FileTokenStore) file(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
+
+// Load ignores files readable by group or others. Windows has no such permission bits,
+// Go reports every file there as 0666 or 0444, so the user profile's ACLs are relied on.
+func (s *FileTokenStore) Load(ctx context.Context, key string) ([]byte, error) {
+	file := s.file(key)
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, nil
+	}
+	if goos != "windows" && info.Mode().Perm()&0o077 != 0 {
+		slog.Warn("ignoring token cache with insecure permissions", "file", file, "mode", info.Mode().Perm().String())
+		return nil, nil
+	}
+	return os.ReadFile(file)
+}
+
+func (s *FileTokenStore) Save(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.file(key), data)
+}
+
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.file(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	lock := flock.New(s.file(key) + ".lock")
+	locked, err := lock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("%s is locked", lock.Path())
+	}
+	return func() { _ = lock.Unlock() }, nil
+}
+
+// writeFileAtomic replaces file with data through a temporary file and a rename, so
+// readers in other processes see either the old or the new contents, never a mix
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// CreateTemp creates the file readable only by the owner
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// redisKeyPrefix namespaces the keys of RedisTokenStore
+const redisKeyPrefix = "oac-client:"
+
+// RedisTokenStore keeps tokens in Redis, shared by every client connected to it, e.g.
+// the pods of a service
+type RedisTokenStore struct {
+	client *redis.Client
+}
+
+// NewRedisTokenStore connects to the Redis server of a redis:// or rediss:// URL, such
+// as redis://:password@redis:6379/0
+func NewRedisTokenStore(rawURL string) (*RedisTokenStore, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	return &RedisTokenStore{client: redis.NewClient(opts)}, nil
+}
+
+// NewRedisTokenStoreWithClient returns a RedisTokenStore using an existing client
+func NewRedisTokenStoreWithClient(client *redis.Client) *RedisTokenStore {
+	return &RedisTokenStore{client: client}
+}
+
+func (s *RedisTokenStore) Load(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *RedisTokenStore) Save(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, redisKeyPrefix+key, data, ttl).Err()
+}
+
+func (s *RedisTokenStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, redisKeyPrefix+key).Err()
+}
+
+// redisUnlock deletes a lock only while it still holds the value of its owner
+var redisUnlock = redis.NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) end return 0`)
+
+// Lock takes a lock key that expires after tokenLockTimeout, in case its holder dies
+func (s *RedisTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	lockKey := redisKeyPrefix + key + ":lock"
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	owner := hex.EncodeToString(b)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		ok, err := s.client.SetNX(ctx, lockKey, owner, tokenLockTimeout).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() {
+				_ = redisUnlock.Run(context.Background(), s.client, []string{lockKey}, owner).Err()
+			}, nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close closes the connections to Redis
+func (s *RedisTokenStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,43 @@
+package oac
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileTokenStoreLoadPermissions(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		perm os.FileMode
+		want string
+	}{
+		{"owner only", "linux", 0o600, "token"},
+		{"readable by others", "linux", 0o644, ""},
+		{"readable by group", "darwin", 0o640, ""},
+		{"windows reports 0666", "windows", 0o666, "token"},
+		{"windows read-only", "windows", 0o444, "token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGOOS(t, tt.goos)
+			store := NewFileTokenStore(t.TempDir())
+			file := filepath.Join(store.Dir, "key.json")
+			if err := os.WriteFile(file, []byte("token"), tt.perm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(file, tt.perm); err != nil {
+				t.Fatal(err)
+			}
+			got, err := store.Load(context.Background(), "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Load() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package oac_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"oac-client/core/oac"
+	"oac-client/core/oac/oactest"
+)
+
+func newTestClient(t *testing.T, srv *oactest.Server) *oac.OacClient {
+	t.Helper()
+	// exported settings would override those of the fake's profile
+	for key := range srv.Profile().Env {
+		t.Setenv(key, "")
+	}
+	client, err := srv.Client(oac.WithTokenStore(oac.NewMemoryTokenStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRejectedTokenIsReplaced(t *testing.T) {
+	srv := oactest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	if _, err := client.ListSnapshots(); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpireTokens()
+	if _, err := client.ListSnapshots(); err != nil {
+		t.Fatalf("request after the token was rejected: %v", err)
+	}
+	if got := srv.TokensIssued(); got != 2 {
+		t.Errorf("tokens issued = %d, want 2", got)
+	}
+}
+
+func TestLoginAndRefreshObtainNewTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(*oac.OacClient) error
+	}{
+		{"Login", (*oac.OacClient).Login},
+		{"Refresh", (*oac.OacClient).Refresh},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := oactest.NewServer()
+			defer srv.Close()
+			client := newTestClient(t, srv)
+
+			before, err := client.GetToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.call(client); err != nil {
+				t.Fatal(err)
+			}
+			after, _ := client.CachedToken()
+			if after == before {
+				t.Error("token unchanged")
+			}
+			if got := srv.TokensIssued(); got != 2 {
+				t.Errorf("tokens issued = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestRetryAfterUnauthorized(t *testing.T) {
+	srv := oactest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	// the body of the rejected request is sent again with the fresh token
+	srv.Fail(http.StatusUnauthorized, 1, "/snapshots")
+	if _, err := client.CreateSnapshot("nightly", "", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if snaps := srv.Snapshots(); len(snaps) != 1 || snaps[0].Name != "nightly" {
+		t.Errorf("snapshots = %+v, want nightly", snaps)
+	}
+	if got := srv.TokensIssued(); got != 2 {
+		t.Errorf("tokens issued = %d, want 2", got)
+	}
+
+	// retries are bounded
+	srv.Fail(http.StatusUnauthorized, oac.DefaultAuthRetries+1, "/snapshots")
+	_, err := client.ListSnapshots()
+	var apiErr *oac.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("error = %v, want a 401 API error", err)
+	}
+}
@@ -0,0 +1,26 @@
+package oac
+
+import (
+	"errors"
+	"time"
+)
+
+var errNoCachedToken = errors.New("no valid cached token and token refresh is disabled; run auth warm or auth login first")
+
+// WithCachedTokenOnly makes the client use the cached token only and fail rather than
+// request a new one from IDCS, e.g. in CI steps running while auth warm keeps it fresh
+func WithCachedTokenOnly() Option {
+	return func(c *OacClient) {
+		c.cachedTokenOnly = true
+	}
+}
+
+// EnsureToken makes sure the cached token stays valid for at least minValidity,
+// obtaining a new one ahead of its expiry otherwise, and returns its expiry
+func (c *OacClient) EnsureToken(minValidity time.Duration) (time.Time, error) {
+	if _, err := c.validToken(minValidity); err != nil {
+		return time.Time{}, err
+	}
+	_, expiry := c.CachedToken()
+	return expiry, nil
+}
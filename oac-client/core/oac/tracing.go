@@ -0,0 +1,78 @@
+package oac
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "oac-client/core/oac"
+
+// Spans go to the global OpenTelemetry tracer provider and propagator, which are
+// no-ops until the program installs an SDK.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// WithContext sets the context requests are made in, e.g. to parent their spans
+// under the caller's trace or to cancel them together
+func WithContext(ctx context.Context) Option {
+	return func(c *OacClient) {
+		c.ctx = ctx
+	}
+}
+
+// context returns the client's base context
+func (c *OacClient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// startTokenSpan starts the span around a token request
+func startTokenSpan(ctx context.Context, grantType string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "oac.token", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("oac.grant_type", grantType)))
+}
+
+// startHTTPSpan starts a client span for req and injects its traceparent header
+func startHTTPSpan(req *http.Request) (*http.Request, trace.Span) {
+	retry, _ := req.Context().Value(retryKey{}).(bool)
+	ctx, span := tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.Bool("oac.retry", retry),
+		))
+
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req, span
+}
+
+// endSpan records the outcome of a request on its span
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp != nil:
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+		if id := resp.Header.Get("opc-request-id"); id != "" {
+			span.SetAttributes(attribute.String("oac.opc_request_id", id))
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,249 @@
+package oac
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransportConfig controls how the client reaches OAC and IDCS.
+// Proxies are taken from HTTPS_PROXY/HTTP_PROXY/NO_PROXY.
+type TransportConfig struct {
+	// CABundle is a PEM file with extra trusted root certificates
+	CABundle string
+	// ClientCert and ClientKey are PEM files used for mutual TLS
+	ClientCert string
+	ClientKey  string
+
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept per host
+	// (default 32), the limit that matters to batch and bulk jobs
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps open connections per host, 0 for no limit
+	MaxConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections unused for this long (default 90s)
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 forces HTTP/1.1, e.g. behind proxies that break HTTP/2
+	DisableHTTP2 bool
+
+	// InsecureSkipVerify accepts any server certificate, for test instances with
+	// self-signed certificates only
+	InsecureSkipVerify bool
+	// TLSMinVersion is the lowest TLS version accepted: 1.0, 1.1, 1.2 (default) or 1.3
+	TLSMinVersion string
+	// CipherSuites is a comma-separated list of TLS 1.0-1.2 cipher suite names in order
+	// of preference, e.g. TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384; TLS 1.3 suites are not
+	// configurable
+	CipherSuites string
+}
+
+// Connection pool defaults, sized for batch jobs running tens of workers
+const (
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// sharedClients reuses one HTTP client, and so one connection pool, per transport config
+var sharedClients = struct {
+	sync.Mutex
+	m map[TransportConfig]*http.Client
+}{m: map[TransportConfig]*http.Client{}}
+
+// transportConfigFromEnv fills unset fields from OAC_CA_BUNDLE, OAC_CLIENT_CERT, OAC_CLIENT_KEY,
+// OAC_MAX_IDLE_CONNS_PER_HOST, OAC_MAX_CONNS_PER_HOST, OAC_IDLE_CONN_TIMEOUT, OAC_DISABLE_HTTP2,
+// OAC_INSECURE_SKIP_VERIFY, OAC_TLS_MIN_VERSION and OAC_TLS_CIPHERS
+func transportConfigFromEnv(cfg TransportConfig, getenv func(string) string) (TransportConfig, error) {
+	if cfg.CABundle == "" {
+		cfg.CABundle = getenv("OAC_CA_BUNDLE")
+	}
+	if cfg.ClientCert == "" {
+		cfg.ClientCert = getenv("OAC_CLIENT_CERT")
+	}
+	if cfg.ClientKey == "" {
+		cfg.ClientKey = getenv("OAC_CLIENT_KEY")
+	}
+
+	var err error
+	if v := getenv("OAC_MAX_IDLE_CONNS_PER_HOST"); v != "" && cfg.MaxIdleConnsPerHost == 0 {
+		if cfg.MaxIdleConnsPerHost, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid OAC_MAX_IDLE_CONNS_PER_HOST: %w", err)
+		}
+	}
+	if v := getenv("OAC_MAX_CONNS_PER_HOST"); v != "" && cfg.MaxConnsPerHost == 0 {
+		if cfg.MaxConnsPerHost, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid OAC_MAX_CONNS_PER_HOST: %w", err)
+		}
+	}
+	if v := getenv("OAC_IDLE_CONN_TIMEOUT"); v != "" && cfg.IdleConnTimeout == 0 {
+		if cfg.IdleConnTimeout, err = time.ParseDuration(v); err != nil {
+			return cfg, fmt.Errorf("invalid OAC_IDLE_CONN_TIMEOUT: %w", err)
+		}
+	}
+	if v := getenv("OAC_DISABLE_HTTP2"); v != "" && !cfg.DisableHTTP2 {
+		if cfg.DisableHTTP2, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("invalid OAC_DISABLE_HTTP2: %w", err)
+		}
+	}
+
+	if v := getenv("OAC_INSECURE_SKIP_VERIFY"); v != "" && !cfg.InsecureSkipVerify {
+		if cfg.InsecureSkipVerify, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("invalid OAC_INSECURE_SKIP_VERIFY: %w", err)
+		}
+	}
+	if cfg.TLSMinVersion == "" {
+		cfg.TLSMinVersion = getenv("OAC_TLS_MIN_VERSION")
+	}
+	if cfg.CipherSuites == "" {
+		cfg.CipherSuites = getenv("OAC_TLS_CIPHERS")
+	}
+
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	return cfg, nil
+}
+
+// sharedHTTPClient returns the HTTP client for cfg, creating it on first use, so clients
+// for several profiles or goroutines reuse the same keep-alive connections
+func sharedHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	sharedClients.Lock()
+	defer sharedClients.Unlock()
+
+	if client, ok := sharedClients.m[cfg]; ok {
+		return client, nil
+	}
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sharedClients.m[cfg] = client
+	return client, nil
+}
+
+// HTTPClient returns the shared HTTP client for cfg, completed from the environment, for
+// requests outside the API such as fetching specs or confirming subscriptions, so they
+// go through the same proxy, CA bundle, client certificate and TLS settings
+func HTTPClient(cfg TransportConfig) (*http.Client, error) {
+	cfg, err := transportConfigFromEnv(cfg, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return sharedHTTPClient(cfg)
+}
+
+// newHTTPClient builds an HTTP client honouring proxy, CA bundle and client certificate settings
+func newHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSMinVersion != "" {
+		version, err := parseTLSVersion(cfg.TLSMinVersion)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.MinVersion = version
+	}
+	if cfg.CipherSuites != "" {
+		suites, err := parseCipherSuites(cfg.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = suites
+	}
+	if cfg.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is DISABLED: connections can be intercepted; use this only with test instances")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if cfg.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("both client certificate and client key must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = tlsConfig
+	// compression is handled by decompressingTransport, which also understands deflate
+	transport.DisableCompression = true
+
+	transport.MaxIdleConns = max(transport.MaxIdleConns, cfg.MaxIdleConnsPerHost)
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	if cfg.DisableHTTP2 {
+		// a non-nil, empty TLSNextProto map turns off HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		// a custom TLS config disables HTTP/2 unless it is asked for explicitly
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	return &http.Client{Transport: &decompressingTransport{base: transport}}, nil
+}
+
+// tlsVersions maps the accepted TLSMinVersion values to crypto/tls versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion parses a TLS version such as 1.3 or TLS1.3
+func parseTLSVersion(s string) (uint16, error) {
+	v := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TLS")
+	if version, ok := tlsVersions[strings.TrimSpace(v)]; ok {
+		return version, nil
+	}
+	return 0, fmt.Errorf("invalid TLS version %q, expected 1.0, 1.1, 1.2 or 1.3", s)
+}
+
+// parseCipherSuites resolves comma-separated cipher suite names to their ids
+func parseCipherSuites(s string) ([]uint16, error) {
+	known := map[string]uint16{}
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[suite.Name] = suite.ID
+	}
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
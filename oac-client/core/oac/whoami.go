@@ -0,0 +1,132 @@
+package oac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Identity describes who the client is authenticated as and against which instance
+type Identity struct {
+	Profile     string    `json:"profile,omitempty"`
+	Instance    string    `json:"instance"`
+	User        string    `json:"user"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	ClientID    string    `json:"clientId,omitempty"`
+	Tenant      string    `json:"tenant,omitempty"`
+	Actor       string    `json:"actor,omitempty"`
+	Scopes      []string  `json:"scopes,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	// FromUserInfo is false when the IDCS user-info endpoint was unavailable and only
+	// the token claims were used, e.g. for client_credentials tokens
+	FromUserInfo bool `json:"fromUserInfo"`
+}
+
+// userInfo is the subset of the IDCS user-info response used by WhoAmI
+type userInfo struct {
+	Sub               string `json:"sub"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email"`
+	Groups            []struct {
+		Name string `json:"name"`
+	} `json:"groups"`
+	AppRoles []struct {
+		Name string `json:"name"`
+	} `json:"appRoles"`
+}
+
+// WhoAmI obtains a token and describes the identity behind it, asking the IDCS
+// user-info endpoint (IDCS_USERINFO_URL, derived from the token URL by default)
+// for the user's name and roles and falling back to the token claims
+func (c *OacClient) WhoAmI() (*Identity, error) {
+	token, err := c.GetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, expiry := c.CachedToken()
+	id := &Identity{
+		Profile:   c.ProfileName(),
+		Instance:  c.Instance(),
+		ExpiresAt: expiry,
+	}
+	if claims, err := DecodeTokenClaims(token); err == nil {
+		id.User = claims.Subject
+		id.DisplayName = claims.DisplayName
+		id.ClientID = claims.ClientID
+		id.Tenant = claims.Tenant
+		id.Actor = claims.Actor
+		id.Scopes = claims.Scopes
+		id.ExpiresAt = claims.ExpiresAt
+	}
+
+	info, err := c.userInfo(token)
+	if err != nil {
+		slog.Debug("user-info unavailable, using token claims", "error", err)
+		return id, nil
+	}
+
+	id.FromUserInfo = true
+	if info.PreferredUsername != "" {
+		id.User = info.PreferredUsername
+	} else if info.Sub != "" {
+		id.User = info.Sub
+	}
+	if info.Name != "" {
+		id.DisplayName = info.Name
+	}
+	id.Email = info.Email
+	for _, r := range info.AppRoles {
+		id.Roles = append(id.Roles, r.Name)
+	}
+	for _, g := range info.Groups {
+		id.Roles = append(id.Roles, g.Name)
+	}
+	return id, nil
+}
+
+// userInfo calls the IDCS user-info endpoint with token
+func (c *OacClient) userInfo(token string) (*userInfo, error) {
+	endpoint := c.setting("IDCS_USERINFO_URL")
+	if endpoint == "" {
+		tokenURL := strings.TrimRight(c.setting("IDCS_TOKEN_URL"), "/")
+		if tokenURL == "" {
+			return nil, fmt.Errorf("IDCS_TOKEN_URL is not set")
+		}
+		endpoint = strings.TrimSuffix(tokenURL, "/token") + "/userinfo"
+	}
+
+	req, err := http.NewRequestWithContext(c.context(), "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, body)
+	}
+
+	var info userInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("invalid user-info response: %w", err)
+	}
+	return &info, nil
+}
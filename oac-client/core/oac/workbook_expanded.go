@@ -0,0 +1,180 @@
+package oac
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// expandedManifest is the file ExpandArchive writes at the top of the directory
+const expandedManifest = ".oac-workbook.json"
+
+// expandedInfo records the item and the entry order of an expanded archive
+type expandedInfo struct {
+	Type    string   `json:"type"`
+	Path    string   `json:"path"`
+	Entries []string `json:"entries"`
+}
+
+// ExpandArchive unpacks a workbook archive (.dva) into dir so that it can be reviewed and
+// versioned: JSON entries are pretty-printed with sorted keys, other entries are written
+// as they are. Files of a previous expansion that are no longer in the archive are removed.
+func ExpandArchive(itemType, catalogPath string, archive []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("%s is not a workbook archive: %w", catalogPath, err)
+	}
+
+	if old, err := readExpandedInfo(dir); err == nil {
+		for _, name := range old.Entries {
+			os.Remove(filepath.Join(dir, filepath.FromSlash(name)))
+		}
+	}
+
+	info := expandedInfo{Type: itemType, Path: catalogPath}
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		if !validEntryName(f.Name) {
+			return fmt.Errorf("invalid entry %q in archive of %s", f.Name, catalogPath)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s from archive: %w", f.Name, err)
+		}
+		if strings.EqualFold(path.Ext(f.Name), ".json") {
+			content = canonicalJSON(content)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return err
+		}
+		info.Entries = append(info.Entries, f.Name)
+	}
+
+	manifest, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, expandedManifest), append(manifest, '\n'), 0o644)
+}
+
+// PackExpanded rebuilds the archive of a directory written by ExpandArchive and returns
+// it with the catalog type and path it was exported from. Entries keep the order of the
+// original archive; files added since are appended in name order.
+func PackExpanded(dir string) (archive []byte, itemType, catalogPath string, err error) {
+	info, err := readExpandedInfo(dir)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var added []string
+	known := make(map[string]bool, len(info.Entries))
+	for _, name := range info.Entries {
+		known[name] = true
+	}
+	err = filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name != expandedManifest && !known[name] {
+			added = append(added, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+	sort.Strings(added)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, name := range append(info.Entries, added...) {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			// removed from the directory since it was expanded
+			continue
+		}
+		if err != nil {
+			return nil, "", "", err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			return nil, "", "", err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", "", err
+	}
+	return out.Bytes(), info.Type, info.Path, nil
+}
+
+func readExpandedInfo(dir string) (*expandedInfo, error) {
+	file := filepath.Join(dir, expandedManifest)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an expanded workbook: %w", dir, err)
+	}
+	info := &expandedInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	return info, nil
+}
+
+// validEntryName rejects archive entries that would be written outside the directory
+func validEntryName(name string) bool {
+	if name == "" || name == expandedManifest || path.IsAbs(name) || strings.Contains(name, `\`) {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalJSON pretty-prints a JSON document with sorted keys, keeping numbers exactly
+// as written; anything that is not valid JSON is returned unchanged
+func canonicalJSON(data []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return data
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}